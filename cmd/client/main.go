@@ -36,6 +36,15 @@ func main() {
 	}
 	log.Printf("Created event: %s", createResp.Event.Name)
 
+	// Get the created event
+	getResp, err := client.GetEvent(ctx, &usagev1.GetEventRequest{
+		Name: createResp.Event.Name,
+	})
+	if err != nil {
+		log.Fatalf("Failed to get event: %v", err)
+	}
+	log.Printf("Got event: %s by %s (took %v)", getResp.Event.Name, getResp.Event.Subject, getResp.Event.ExecutionDuration.AsDuration())
+
 	// List events
 	listResp, err := client.ListEvents(ctx, &usagev1.ListEventsRequest{
 		PageSize: 10,
@@ -8,6 +8,7 @@ package usagev1
 
 import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	money "google.golang.org/genproto/googleapis/type/money"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
@@ -31,16 +32,47 @@ type Event struct {
 	// Format: `events/{event}`
 	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	// The subject who performed the action.
-	// Format: `users/{user}`
+	// Format: `users/{user}` or `serviceAccounts/{service_account}`, where the
+	// ID is 1-63 characters of lowercase letters, digits and hyphens, starts
+	// with a letter and does not end with a hyphen.
 	Subject string `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
-	// The source where the action originated (e.g., "animal-classifier").
+	// The source where the action originated (e.g., "animal-classifier"): the
+	// ID of a registered `Source`.
 	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
-	// The action that was performed (e.g., "classify").
+	// The action that was performed (e.g., "classify"): one of the `actions`
+	// of the source.
 	Action string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
 	// How long the operation took to complete.
 	ExecutionDuration *durationpb.Duration `protobuf:"bytes,5,opt,name=execution_duration,json=executionDuration,proto3" json:"execution_duration,omitempty"`
+	// Key/value metadata of the operation, e.g. the model version or the
+	// region it ran in. Keys are 1-63 characters of lowercase letters, digits,
+	// underscores and hyphens, and start with a letter. Values are up to 63
+	// characters of letters of either case, digits, underscores, hyphens and
+	// dots, e.g. "v1.2". An event has at most 64 labels.
+	Labels map[string]string `protobuf:"bytes,11,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	// The time when the event was recorded.
-	CreateTime    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// The time when the event was deleted. Only set for soft-deleted events.
+	DeleteTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=delete_time,json=deleteTime,proto3" json:"delete_time,omitempty"`
+	// The time after which a soft-deleted event is permanently purged.
+	PurgeTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=purge_time,json=purgeTime,proto3" json:"purge_time,omitempty"`
+	// The time when the event was last updated.
+	UpdateTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// A checksum of the event's current state, following AIP-154. Send it
+	// back with an update or delete to make the request fail with `ABORTED`
+	// if the event was modified in the meantime.
+	Etag string `protobuf:"bytes,10,opt,name=etag,proto3" json:"etag,omitempty"`
+	// The cost of the operation, computed when the event is recorded from the
+	// catalog price of its action in effect at `create_time`, or else from the
+	// rate card the server is configured with. Unset if neither applies.
+	//
+	// Price changes only affect events recorded afterwards. An update of
+	// `source`, `action` or `execution_duration` reprices the event with the
+	// price in effect at `create_time`, which `price_version` then reflects.
+	Cost *money.Money `protobuf:"bytes,12,opt,name=cost,proto3" json:"cost,omitempty"`
+	// The price that `cost` was computed with: the resource name of a catalog
+	// price, or the version of a configured rate card.
+	PriceVersion  string `protobuf:"bytes,13,opt,name=price_version,json=priceVersion,proto3" json:"price_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -110,6 +142,13 @@ func (x *Event) GetExecutionDuration() *durationpb.Duration {
 	return nil
 }
 
+func (x *Event) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 func (x *Event) GetCreateTime() *timestamppb.Timestamp {
 	if x != nil {
 		return x.CreateTime
@@ -117,20 +156,79 @@ func (x *Event) GetCreateTime() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Event) GetDeleteTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeleteTime
+	}
+	return nil
+}
+
+func (x *Event) GetPurgeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PurgeTime
+	}
+	return nil
+}
+
+func (x *Event) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Event) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Event) GetCost() *money.Money {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *Event) GetPriceVersion() string {
+	if x != nil {
+		return x.PriceVersion
+	}
+	return ""
+}
+
 var File_ai_h2o_usage_v1_event_proto protoreflect.FileDescriptor
 
 const file_ai_h2o_usage_v1_event_proto_rawDesc = "" +
 	"\n" +
-	"\x1bai/h2o/usage/v1/event.proto\x12\x0fai.h2o.usage.v1\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc2\x02\n" +
+	"\x1bai/h2o/usage/v1/event.proto\x12\x0fai.h2o.usage.v1\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x17google/type/money.proto\"\xf8\x05\n" +
 	"\x05Event\x12\x17\n" +
-	"\x04name\x18\x01 \x01(\tB\x03\xe0A\bR\x04name\x12\x1d\n" +
-	"\asubject\x18\x02 \x01(\tB\x03\xe0A\x02R\asubject\x12\x1b\n" +
+	"\x04name\x18\x01 \x01(\tB\x03\xe0A\bR\x04name\x12#\n" +
+	"\asubject\x18\x02 \x01(\tB\t\xe0A\x02\xfaA\x03\n" +
+	"\x01*R\asubject\x12\x1b\n" +
 	"\x06source\x18\x03 \x01(\tB\x03\xe0A\x02R\x06source\x12\x1b\n" +
 	"\x06action\x18\x04 \x01(\tB\x03\xe0A\x02R\x06action\x12M\n" +
-	"\x12execution_duration\x18\x05 \x01(\v2\x19.google.protobuf.DurationB\x03\xe0A\x02R\x11executionDuration\x12@\n" +
+	"\x12execution_duration\x18\x05 \x01(\v2\x19.google.protobuf.DurationB\x03\xe0A\x02R\x11executionDuration\x12?\n" +
+	"\x06labels\x18\v \x03(\v2\".ai.h2o.usage.v1.Event.LabelsEntryB\x03\xe0A\x01R\x06labels\x12@\n" +
 	"\vcreate_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
-	"createTime:6\xeaA3\n" +
-	"\x12usage.h2o.ai/Event\x12\x0eevents/{event}*\x06events2\x05eventB\xbf\x01\n" +
+	"createTime\x12@\n" +
+	"\vdelete_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"deleteTime\x12>\n" +
+	"\n" +
+	"purge_time\x18\b \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\tpurgeTime\x12@\n" +
+	"\vupdate_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"updateTime\x12\x17\n" +
+	"\x04etag\x18\n" +
+	" \x01(\tB\x03\xe0A\x01R\x04etag\x12+\n" +
+	"\x04cost\x18\f \x01(\v2\x12.google.type.MoneyB\x03\xe0A\x03R\x04cost\x12(\n" +
+	"\rprice_version\x18\r \x01(\tB\x03\xe0A\x03R\fpriceVersion\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01:6\xeaA3\n" +
+	"\x12usage.h2o.ai/Event\x12\x0eevents/{event}*\x06events2\x05eventB\xa6\x02\xeaA!\n" +
+	"\x11usage.h2o.ai/User\x12\fusers/{user}\xeaA@\n" +
+	"\x1busage.h2o.ai/ServiceAccount\x12!serviceAccounts/{service_account}\n" +
 	"\x13com.ai.h2o.usage.v1B\n" +
 	"EventProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
 
@@ -146,20 +244,27 @@ func file_ai_h2o_usage_v1_event_proto_rawDescGZIP() []byte {
 	return file_ai_h2o_usage_v1_event_proto_rawDescData
 }
 
-var file_ai_h2o_usage_v1_event_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ai_h2o_usage_v1_event_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
 var file_ai_h2o_usage_v1_event_proto_goTypes = []any{
 	(*Event)(nil),                 // 0: ai.h2o.usage.v1.Event
-	(*durationpb.Duration)(nil),   // 1: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+	nil,                           // 1: ai.h2o.usage.v1.Event.LabelsEntry
+	(*durationpb.Duration)(nil),   // 2: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*money.Money)(nil),           // 4: google.type.Money
 }
 var file_ai_h2o_usage_v1_event_proto_depIdxs = []int32{
-	1, // 0: ai.h2o.usage.v1.Event.execution_duration:type_name -> google.protobuf.Duration
-	2, // 1: ai.h2o.usage.v1.Event.create_time:type_name -> google.protobuf.Timestamp
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2, // 0: ai.h2o.usage.v1.Event.execution_duration:type_name -> google.protobuf.Duration
+	1, // 1: ai.h2o.usage.v1.Event.labels:type_name -> ai.h2o.usage.v1.Event.LabelsEntry
+	3, // 2: ai.h2o.usage.v1.Event.create_time:type_name -> google.protobuf.Timestamp
+	3, // 3: ai.h2o.usage.v1.Event.delete_time:type_name -> google.protobuf.Timestamp
+	3, // 4: ai.h2o.usage.v1.Event.purge_time:type_name -> google.protobuf.Timestamp
+	3, // 5: ai.h2o.usage.v1.Event.update_time:type_name -> google.protobuf.Timestamp
+	4, // 6: ai.h2o.usage.v1.Event.cost:type_name -> google.type.Money
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_ai_h2o_usage_v1_event_proto_init() }
@@ -173,7 +278,7 @@ func file_ai_h2o_usage_v1_event_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_event_proto_rawDesc), len(file_ai_h2o_usage_v1_event_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   2,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
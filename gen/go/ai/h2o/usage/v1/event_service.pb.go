@@ -8,8 +8,13 @@ package usagev1
 
 import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	interval "google.golang.org/genproto/googleapis/type/interval"
+	money "google.golang.org/genproto/googleapis/type/money"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,11 +27,84 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// The time buckets of AggregateUsage.
+type TimeBucket int32
+
+const (
+	// Events are not grouped by time.
+	TimeBucket_TIME_BUCKET_UNSPECIFIED TimeBucket = 0
+	// Hours.
+	TimeBucket_TIME_BUCKET_HOUR TimeBucket = 1
+	// Calendar days.
+	TimeBucket_TIME_BUCKET_DAY TimeBucket = 2
+	// Calendar months.
+	TimeBucket_TIME_BUCKET_MONTH TimeBucket = 3
+)
+
+// Enum value maps for TimeBucket.
+var (
+	TimeBucket_name = map[int32]string{
+		0: "TIME_BUCKET_UNSPECIFIED",
+		1: "TIME_BUCKET_HOUR",
+		2: "TIME_BUCKET_DAY",
+		3: "TIME_BUCKET_MONTH",
+	}
+	TimeBucket_value = map[string]int32{
+		"TIME_BUCKET_UNSPECIFIED": 0,
+		"TIME_BUCKET_HOUR":        1,
+		"TIME_BUCKET_DAY":         2,
+		"TIME_BUCKET_MONTH":       3,
+	}
+)
+
+func (x TimeBucket) Enum() *TimeBucket {
+	p := new(TimeBucket)
+	*p = x
+	return p
+}
+
+func (x TimeBucket) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimeBucket) Descriptor() protoreflect.EnumDescriptor {
+	return file_ai_h2o_usage_v1_event_service_proto_enumTypes[0].Descriptor()
+}
+
+func (TimeBucket) Type() protoreflect.EnumType {
+	return &file_ai_h2o_usage_v1_event_service_proto_enumTypes[0]
+}
+
+func (x TimeBucket) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimeBucket.Descriptor instead.
+func (TimeBucket) EnumDescriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{0}
+}
+
 // Request message for CreateEvent.
 type CreateEventRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The event to create.
-	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// A unique identifier for this request, following AIP-155. If a request
+	// with the same `request_id` was already processed recently, the
+	// originally created event is returned instead of creating a new one, so
+	// that clients can safely retry. Reusing a `request_id` with a different
+	// payload fails with `ALREADY_EXISTS`. Request IDs are remembered by each
+	// server process, not persisted, so only retries reaching the same
+	// process are deduplicated; set `event_id` to deduplicate across servers.
+	RequestId string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The ID to use for the event, which will become the final component of
+	// the event's resource name. If not set, the server generates a UUID.
+	//
+	// Following AIP-122, the ID must be 1 to 63 characters long, consist of
+	// lowercase letters, digits and hyphens, start with a letter and not end
+	// with a hyphen. Creating an event with an ID that is already in use fails
+	// with `ALREADY_EXISTS`.
+	EventId       string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -68,6 +146,20 @@ func (x *CreateEventRequest) GetEvent() *Event {
 	return nil
 }
 
+func (x *CreateEventRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
 // Response message for CreateEvent.
 type CreateEventResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -114,31 +206,40 @@ func (x *CreateEventResponse) GetEvent() *Event {
 	return nil
 }
 
-// Request message for ListEvents.
-type ListEventsRequest struct {
+// Request message for BatchCreateEvents.
+type BatchCreateEventsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The maximum number of events to return.
-	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	// A page token, received from a previous `ListEvents` call.
-	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// The requests specifying the events to create, validated with the same
+	// rules as `CreateEvent`. At most 1000 events can be created in a batch.
+	// The `request_id` of the individual requests must be empty; set it on
+	// the batch request instead.
+	Requests []*CreateEventRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	// If false (the default), the batch is atomic: if any request fails, no
+	// event is created and the error of the first failed request is returned.
+	// If true, the valid requests are applied and the failed ones are reported
+	// in `BatchCreateEventsResponse.failures`.
+	AllowPartialSuccess bool `protobuf:"varint,2,opt,name=allow_partial_success,json=allowPartialSuccess,proto3" json:"allow_partial_success,omitempty"`
+	// A unique identifier for the whole batch, following AIP-155. A retried
+	// batch with the same `request_id` returns the original response.
+	RequestId     string `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEventsRequest) Reset() {
-	*x = ListEventsRequest{}
+func (x *BatchCreateEventsRequest) Reset() {
+	*x = BatchCreateEventsRequest{}
 	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEventsRequest) String() string {
+func (x *BatchCreateEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEventsRequest) ProtoMessage() {}
+func (*BatchCreateEventsRequest) ProtoMessage() {}
 
-func (x *ListEventsRequest) ProtoReflect() protoreflect.Message {
+func (x *BatchCreateEventsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -150,50 +251,58 @@ func (x *ListEventsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEventsRequest.ProtoReflect.Descriptor instead.
-func (*ListEventsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use BatchCreateEventsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateEventsRequest) Descriptor() ([]byte, []int) {
 	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ListEventsRequest) GetPageSize() int32 {
+func (x *BatchCreateEventsRequest) GetRequests() []*CreateEventRequest {
 	if x != nil {
-		return x.PageSize
+		return x.Requests
 	}
-	return 0
+	return nil
 }
 
-func (x *ListEventsRequest) GetPageToken() string {
+func (x *BatchCreateEventsRequest) GetAllowPartialSuccess() bool {
 	if x != nil {
-		return x.PageToken
+		return x.AllowPartialSuccess
+	}
+	return false
+}
+
+func (x *BatchCreateEventsRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
 	}
 	return ""
 }
 
-// Response message for ListEvents.
-type ListEventsResponse struct {
+// Response message for BatchCreateEvents.
+type BatchCreateEventsResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// The list of events.
+	// The created events, in the order of the requests they were created by.
 	Events []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
-	// A token to retrieve the next page of results.
-	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// The requests that failed, in request order. Only set when
+	// `allow_partial_success` is true.
+	Failures      []*BatchCreateEventsResponse_Failure `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListEventsResponse) Reset() {
-	*x = ListEventsResponse{}
+func (x *BatchCreateEventsResponse) Reset() {
+	*x = BatchCreateEventsResponse{}
 	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListEventsResponse) String() string {
+func (x *BatchCreateEventsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListEventsResponse) ProtoMessage() {}
+func (*BatchCreateEventsResponse) ProtoMessage() {}
 
-func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
+func (x *BatchCreateEventsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -205,47 +314,1516 @@ func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListEventsResponse.ProtoReflect.Descriptor instead.
-func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use BatchCreateEventsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateEventsResponse) Descriptor() ([]byte, []int) {
 	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ListEventsResponse) GetEvents() []*Event {
+func (x *BatchCreateEventsResponse) GetEvents() []*Event {
 	if x != nil {
 		return x.Events
 	}
 	return nil
 }
 
-func (x *ListEventsResponse) GetNextPageToken() string {
+func (x *BatchCreateEventsResponse) GetFailures() []*BatchCreateEventsResponse_Failure {
 	if x != nil {
-		return x.NextPageToken
+		return x.Failures
+	}
+	return nil
+}
+
+// Request message for IngestEvents.
+type IngestEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A client-chosen number identifying the request within the stream,
+	// echoed in its response.
+	Sequence int64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// The event to create, with the same semantics as a `CreateEvent` call.
+	// Setting `request_id` makes it safe to resend unacknowledged events on
+	// a new stream after a disconnect.
+	Request       *CreateEventRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestEventsRequest) Reset() {
+	*x = IngestEventsRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestEventsRequest) ProtoMessage() {}
+
+func (x *IngestEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestEventsRequest.ProtoReflect.Descriptor instead.
+func (*IngestEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IngestEventsRequest) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *IngestEventsRequest) GetRequest() *CreateEventRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+// Response message for IngestEvents.
+type IngestEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The `sequence` of the request this response belongs to.
+	Sequence int64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// The created event, set if the request succeeded.
+	Event *Event `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	// The error of the request, with the same code and details that
+	// `CreateEvent` would have returned for it. Unset if the request
+	// succeeded.
+	Status        *status.Status `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestEventsResponse) Reset() {
+	*x = IngestEventsResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestEventsResponse) ProtoMessage() {}
+
+func (x *IngestEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestEventsResponse.ProtoReflect.Descriptor instead.
+func (*IngestEventsResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *IngestEventsResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *IngestEventsResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *IngestEventsResponse) GetStatus() *status.Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// Request message for WatchEvents.
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A filter expression following AIP-160, with the same syntax as
+	// `ListEventsRequest.filter`. Only matching events are streamed.
+	Filter string `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// A `resume_token` received from a previous `WatchEvents` stream with the
+	// same filter. Events created after the event it was issued for are
+	// replayed, in `create_time` order, before new events are streamed. If
+	// empty, only events created after the call are streamed.
+	ResumeToken   string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchEventsRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
 	}
 	return ""
 }
 
-var File_ai_h2o_usage_v1_event_service_proto protoreflect.FileDescriptor
+func (x *WatchEventsRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
 
-const file_ai_h2o_usage_v1_event_service_proto_rawDesc = "" +
-	"\n" +
-	"#ai/h2o/usage/v1/event_service.proto\x12\x0fai.h2o.usage.v1\x1a\x1bai/h2o/usage/v1/event.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\"G\n" +
-	"\x12CreateEventRequest\x121\n" +
-	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventB\x03\xe0A\x02R\x05event\"C\n" +
-	"\x13CreateEventResponse\x12,\n" +
-	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"O\n" +
-	"\x11ListEventsRequest\x12\x1b\n" +
-	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
-	"\n" +
-	"page_token\x18\x02 \x01(\tR\tpageToken\"l\n" +
-	"\x12ListEventsResponse\x12.\n" +
-	"\x06events\x18\x01 \x03(\v2\x16.ai.h2o.usage.v1.EventR\x06events\x12&\n" +
-	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken2\xea\x01\n" +
-	"\fEventService\x12o\n" +
-	"\vCreateEvent\x12#.ai.h2o.usage.v1.CreateEventRequest\x1a$.ai.h2o.usage.v1.CreateEventResponse\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
-	"/v1/events\x12i\n" +
-	"\n" +
-	"ListEvents\x12\".ai.h2o.usage.v1.ListEventsRequest\x1a#.ai.h2o.usage.v1.ListEventsResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
-	"/v1/eventsB\xc6\x01\n" +
+// Response message for WatchEvents.
+type WatchEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The created event.
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// A token to resume the stream after this event, e.g. when the stream is
+	// closed with `RESOURCE_EXHAUSTED` because the client did not keep up.
+	ResumeToken   string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsResponse) Reset() {
+	*x = WatchEventsResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsResponse) ProtoMessage() {}
+
+func (x *WatchEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsResponse.ProtoReflect.Descriptor instead.
+func (*WatchEventsResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchEventsResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *WatchEventsResponse) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+// Request message for GetEvent.
+type GetEventRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the event to retrieve.
+	// Format: `events/{event}`
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventRequest) Reset() {
+	*x = GetEventRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventRequest) ProtoMessage() {}
+
+func (x *GetEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventRequest.ProtoReflect.Descriptor instead.
+func (*GetEventRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetEventRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response message for GetEvent.
+type GetEventResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requested event.
+	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventResponse) Reset() {
+	*x = GetEventResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventResponse) ProtoMessage() {}
+
+func (x *GetEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventResponse.ProtoReflect.Descriptor instead.
+func (*GetEventResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Request message for UpdateEvent.
+type UpdateEventRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The event to update. Its `name` identifies the event; if its `etag` is
+	// set, it must match the current etag of the event.
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// The fields to update, following AIP-134. If omitted, all populated
+	// fields of `event` are updated; `*` replaces all mutable fields. Output
+	// only fields and `name` cannot be updated and are ignored.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEventRequest) Reset() {
+	*x = UpdateEventRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEventRequest) ProtoMessage() {}
+
+func (x *UpdateEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEventRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEventRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateEventRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *UpdateEventRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+// Response message for UpdateEvent.
+type UpdateEventResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The updated event.
+	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEventResponse) Reset() {
+	*x = UpdateEventResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEventResponse) ProtoMessage() {}
+
+func (x *UpdateEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEventResponse.ProtoReflect.Descriptor instead.
+func (*UpdateEventResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Request message for DeleteEvent.
+type DeleteEventRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the event to delete.
+	// Format: `events/{event}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The current etag of the event. If set and the event has been modified
+	// since, the request fails with `ABORTED`.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEventRequest) Reset() {
+	*x = DeleteEventRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventRequest) ProtoMessage() {}
+
+func (x *DeleteEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventRequest.ProtoReflect.Descriptor instead.
+func (*DeleteEventRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteEventRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeleteEventRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Response message for DeleteEvent.
+type DeleteEventResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The deleted event, with `delete_time` and `purge_time` set.
+	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEventResponse) Reset() {
+	*x = DeleteEventResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventResponse) ProtoMessage() {}
+
+func (x *DeleteEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventResponse.ProtoReflect.Descriptor instead.
+func (*DeleteEventResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Request message for UndeleteEvent.
+type UndeleteEventRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the event to restore.
+	// Format: `events/{event}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The current etag of the event. If set and the event has been modified
+	// since, the request fails with `ABORTED`.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteEventRequest) Reset() {
+	*x = UndeleteEventRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteEventRequest) ProtoMessage() {}
+
+func (x *UndeleteEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteEventRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteEventRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UndeleteEventRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UndeleteEventRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Response message for UndeleteEvent.
+type UndeleteEventResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The restored event.
+	Event         *Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteEventResponse) Reset() {
+	*x = UndeleteEventResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteEventResponse) ProtoMessage() {}
+
+func (x *UndeleteEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteEventResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteEventResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UndeleteEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Request message for ListEvents.
+type ListEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The maximum number of events to return.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// A page token, received from a previous `ListEvents` call.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// A filter expression following AIP-160, e.g.
+	// `action = "classify" AND subject = "users/alice"`.
+	//
+	// Supported fields are `name`, `subject`, `source`, `action` (compared as
+	// strings; `=` and `!=` accept `*` wildcards), `create_time` (a quoted
+	// RFC 3339 timestamp), `execution_duration` (e.g. `1.5s` or `200ms`) and
+	// labels: `labels.region = "us-east1"` compares the value of a label like
+	// a string field and only matches events that have the label, while
+	// `labels:region` or `labels.region:*` tests for its presence.
+	// Restrictions can be combined with `AND`, `OR`, `NOT` and parentheses.
+	// Note that, as in AIP-160, `OR` binds tighter than `AND`.
+	Filter string `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	// A comma-separated list of fields to order by, following AIP-132, e.g.
+	// `execution_duration desc, create_time`. Fields are sorted ascending
+	// unless suffixed with ` desc`.
+	//
+	// Supported fields are `create_time`, `execution_duration`, `subject`,
+	// `source`, `action` and `name`. Ties are broken by `name`. The default
+	// order is `create_time desc`.
+	OrderBy string `protobuf:"bytes,4,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// If true, soft-deleted events are included in the results.
+	ShowDeleted   bool `protobuf:"varint,5,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventsRequest) Reset() {
+	*x = ListEventsRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventsRequest) ProtoMessage() {}
+
+func (x *ListEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListEventsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListEventsRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *ListEventsRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListEventsRequest) GetShowDeleted() bool {
+	if x != nil {
+		return x.ShowDeleted
+	}
+	return false
+}
+
+// Response message for ListEvents.
+type ListEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The list of events.
+	Events []*Event `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// A token to retrieve the next page of results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventsResponse) Reset() {
+	*x = ListEventsResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventsResponse) ProtoMessage() {}
+
+func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListEventsResponse) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListEventsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Request message for AggregateUsage.
+type AggregateUsageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A filter expression selecting the events to aggregate, with the same
+	// syntax and fields as the `filter` of `ListEvents`, e.g.
+	// `create_time >= "2025-01-01T00:00:00Z" AND source = "animal-classifier"`.
+	Filter string `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// The fields to group events by: `subject`, `source`, `action` or
+	// `labels.{key}` for the value of a label. Events without the label are
+	// grouped together. If empty, all events are aggregated into one group per
+	// time bucket.
+	GroupBy []string `protobuf:"bytes,2,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	// The time buckets to group events by their `create_time` into. If
+	// unspecified, events are not grouped by time.
+	TimeBucket TimeBucket `protobuf:"varint,3,opt,name=time_bucket,json=timeBucket,proto3,enum=ai.h2o.usage.v1.TimeBucket" json:"time_bucket,omitempty"`
+	// The IANA time zone in which buckets start, e.g. `Europe/Prague`. Defaults
+	// to `UTC`.
+	TimeZone      string `protobuf:"bytes,4,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateUsageRequest) Reset() {
+	*x = AggregateUsageRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateUsageRequest) ProtoMessage() {}
+
+func (x *AggregateUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateUsageRequest.ProtoReflect.Descriptor instead.
+func (*AggregateUsageRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AggregateUsageRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *AggregateUsageRequest) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *AggregateUsageRequest) GetTimeBucket() TimeBucket {
+	if x != nil {
+		return x.TimeBucket
+	}
+	return TimeBucket_TIME_BUCKET_UNSPECIFIED
+}
+
+func (x *AggregateUsageRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+// Response message for AggregateUsage.
+type AggregateUsageResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The totals of each group of events, ordered by the start of their time
+	// bucket, then by the values of the `group_by` fields in order.
+	Aggregates    []*UsageAggregate `protobuf:"bytes,1,rep,name=aggregates,proto3" json:"aggregates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateUsageResponse) Reset() {
+	*x = AggregateUsageResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateUsageResponse) ProtoMessage() {}
+
+func (x *AggregateUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateUsageResponse.ProtoReflect.Descriptor instead.
+func (*AggregateUsageResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AggregateUsageResponse) GetAggregates() []*UsageAggregate {
+	if x != nil {
+		return x.Aggregates
+	}
+	return nil
+}
+
+// The totals of a group of events.
+type UsageAggregate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The subject of the events, if grouped by `subject`.
+	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	// The source of the events, if grouped by `source`.
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// The action of the events, if grouped by `action`.
+	Action string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	// The values of the labels grouped by, for the events that have them.
+	Labels map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The time bucket of the events, if grouped by time.
+	Interval *interval.Interval `protobuf:"bytes,5,opt,name=interval,proto3" json:"interval,omitempty"`
+	// The number of events.
+	EventCount int64 `protobuf:"varint,6,opt,name=event_count,json=eventCount,proto3" json:"event_count,omitempty"`
+	// The sum of the `execution_duration` of the events.
+	TotalExecutionDuration *durationpb.Duration `protobuf:"bytes,7,opt,name=total_execution_duration,json=totalExecutionDuration,proto3" json:"total_execution_duration,omitempty"`
+	// The mean `execution_duration` of the events.
+	AverageExecutionDuration *durationpb.Duration `protobuf:"bytes,8,opt,name=average_execution_duration,json=averageExecutionDuration,proto3" json:"average_execution_duration,omitempty"`
+	// The median `execution_duration` of the events. Like the 95th percentile
+	// and the maximum, it is estimated when read from rollups, see
+	// AggregateUsage.
+	P50ExecutionDuration *durationpb.Duration `protobuf:"bytes,9,opt,name=p50_execution_duration,json=p50ExecutionDuration,proto3" json:"p50_execution_duration,omitempty"`
+	// The 95th percentile of the `execution_duration` of the events.
+	P95ExecutionDuration *durationpb.Duration `protobuf:"bytes,10,opt,name=p95_execution_duration,json=p95ExecutionDuration,proto3" json:"p95_execution_duration,omitempty"`
+	// The longest `execution_duration` of the events.
+	MaxExecutionDuration *durationpb.Duration `protobuf:"bytes,11,opt,name=max_execution_duration,json=maxExecutionDuration,proto3" json:"max_execution_duration,omitempty"`
+	// The sum of the `cost` of the events, one amount per currency. Events
+	// without a cost are not included.
+	TotalCost     []*money.Money `protobuf:"bytes,12,rep,name=total_cost,json=totalCost,proto3" json:"total_cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageAggregate) Reset() {
+	*x = UsageAggregate{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageAggregate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageAggregate) ProtoMessage() {}
+
+func (x *UsageAggregate) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageAggregate.ProtoReflect.Descriptor instead.
+func (*UsageAggregate) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UsageAggregate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *UsageAggregate) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *UsageAggregate) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *UsageAggregate) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetInterval() *interval.Interval {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetEventCount() int64 {
+	if x != nil {
+		return x.EventCount
+	}
+	return 0
+}
+
+func (x *UsageAggregate) GetTotalExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TotalExecutionDuration
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetAverageExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.AverageExecutionDuration
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetP50ExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.P50ExecutionDuration
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetP95ExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.P95ExecutionDuration
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetMaxExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.MaxExecutionDuration
+	}
+	return nil
+}
+
+func (x *UsageAggregate) GetTotalCost() []*money.Money {
+	if x != nil {
+		return x.TotalCost
+	}
+	return nil
+}
+
+// Request message for CheckQuota.
+type CheckQuotaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The subject of the event, e.g. `users/alice`.
+	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	// The source of the event, e.g. `animal-classifier`.
+	Source string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// The action of the event, e.g. `classify`.
+	Action        string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckQuotaRequest) Reset() {
+	*x = CheckQuotaRequest{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckQuotaRequest) ProtoMessage() {}
+
+func (x *CheckQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckQuotaRequest.ProtoReflect.Descriptor instead.
+func (*CheckQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CheckQuotaRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *CheckQuotaRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CheckQuotaRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+// Response message for CheckQuota.
+type CheckQuotaResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether an event of the subject, source and action would be accepted:
+	// none of the quotas is exhausted.
+	Allowed bool `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	// The usage of the quotas that apply to the event, in their current
+	// windows.
+	Quotas        []*QuotaUsage `protobuf:"bytes,2,rep,name=quotas,proto3" json:"quotas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckQuotaResponse) Reset() {
+	*x = CheckQuotaResponse{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckQuotaResponse) ProtoMessage() {}
+
+func (x *CheckQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckQuotaResponse.ProtoReflect.Descriptor instead.
+func (*CheckQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CheckQuotaResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *CheckQuotaResponse) GetQuotas() []*QuotaUsage {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
+// The usage of a quota by a subject in a window of time.
+type QuotaUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the quota in the server configuration, e.g.
+	// `free-daily-classifications`.
+	QuotaId string `protobuf:"bytes,1,opt,name=quota_id,json=quotaId,proto3" json:"quota_id,omitempty"`
+	// The window of time in which usage counts against the quota. Windows are
+	// hours, days or months of UTC.
+	Window *interval.Interval `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	// The number of events allowed in the window, or zero if not limited.
+	EventLimit int64 `protobuf:"varint,3,opt,name=event_limit,json=eventLimit,proto3" json:"event_limit,omitempty"`
+	// The number of events in the window.
+	EventCount int64 `protobuf:"varint,4,opt,name=event_count,json=eventCount,proto3" json:"event_count,omitempty"`
+	// The total `execution_duration` allowed in the window, or unset if not
+	// limited.
+	ExecutionDurationLimit *durationpb.Duration `protobuf:"bytes,5,opt,name=execution_duration_limit,json=executionDurationLimit,proto3" json:"execution_duration_limit,omitempty"`
+	// The total `execution_duration` of the events in the window.
+	TotalExecutionDuration *durationpb.Duration `protobuf:"bytes,6,opt,name=total_execution_duration,json=totalExecutionDuration,proto3" json:"total_execution_duration,omitempty"`
+	// Whether the usage has reached a limit, so that further events are
+	// rejected until the window ends. The event reaching a limit on the
+	// execution duration is accepted, even if it exceeds it.
+	Exhausted     bool `protobuf:"varint,7,opt,name=exhausted,proto3" json:"exhausted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *QuotaUsage) GetQuotaId() string {
+	if x != nil {
+		return x.QuotaId
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetWindow() *interval.Interval {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *QuotaUsage) GetEventLimit() int64 {
+	if x != nil {
+		return x.EventLimit
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetEventCount() int64 {
+	if x != nil {
+		return x.EventCount
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetExecutionDurationLimit() *durationpb.Duration {
+	if x != nil {
+		return x.ExecutionDurationLimit
+	}
+	return nil
+}
+
+func (x *QuotaUsage) GetTotalExecutionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TotalExecutionDuration
+	}
+	return nil
+}
+
+func (x *QuotaUsage) GetExhausted() bool {
+	if x != nil {
+		return x.Exhausted
+	}
+	return false
+}
+
+// A request of the batch that could not be applied.
+type BatchCreateEventsResponse_Failure struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The index of the failed request in `BatchCreateEventsRequest.requests`.
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// The error of the request, with the same code and details that
+	// `CreateEvent` would have returned for it.
+	Status        *status.Status `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateEventsResponse_Failure) Reset() {
+	*x = BatchCreateEventsResponse_Failure{}
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateEventsResponse_Failure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateEventsResponse_Failure) ProtoMessage() {}
+
+func (x *BatchCreateEventsResponse_Failure) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_event_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateEventsResponse_Failure.ProtoReflect.Descriptor instead.
+func (*BatchCreateEventsResponse_Failure) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *BatchCreateEventsResponse_Failure) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchCreateEventsResponse_Failure) GetStatus() *status.Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+var File_ai_h2o_usage_v1_event_service_proto protoreflect.FileDescriptor
+
+const file_ai_h2o_usage_v1_event_service_proto_rawDesc = "" +
+	"\n" +
+	"#ai/h2o/usage/v1/event_service.proto\x12\x0fai.h2o.usage.v1\x1a\x1bai/h2o/usage/v1/event.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\x1a\x1bgoogle/api/field_info.proto\x1a\x19google/api/resource.proto\x1a\x1egoogle/protobuf/duration.proto\x1a google/protobuf/field_mask.proto\x1a\x17google/rpc/status.proto\x1a\x1agoogle/type/interval.proto\x1a\x17google/type/money.proto\"\x93\x01\n" +
+	"\x12CreateEventRequest\x121\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventB\x03\xe0A\x02R\x05event\x12*\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tB\v\xe0A\x01\xe2\x8c\xcf\xd7\b\x02\b\x01R\trequestId\x12\x1e\n" +
+	"\bevent_id\x18\x03 \x01(\tB\x03\xe0A\x01R\aeventId\"C\n" +
+	"\x13CreateEventResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"\xc0\x01\n" +
+	"\x18BatchCreateEventsRequest\x12D\n" +
+	"\brequests\x18\x01 \x03(\v2#.ai.h2o.usage.v1.CreateEventRequestB\x03\xe0A\x02R\brequests\x122\n" +
+	"\x15allow_partial_success\x18\x02 \x01(\bR\x13allowPartialSuccess\x12*\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tB\v\xe0A\x01\xe2\x8c\xcf\xd7\b\x02\b\x01R\trequestId\"\xe8\x01\n" +
+	"\x19BatchCreateEventsResponse\x12.\n" +
+	"\x06events\x18\x01 \x03(\v2\x16.ai.h2o.usage.v1.EventR\x06events\x12N\n" +
+	"\bfailures\x18\x02 \x03(\v22.ai.h2o.usage.v1.BatchCreateEventsResponse.FailureR\bfailures\x1aK\n" +
+	"\aFailure\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12*\n" +
+	"\x06status\x18\x02 \x01(\v2\x12.google.rpc.StatusR\x06status\"u\n" +
+	"\x13IngestEventsRequest\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x12B\n" +
+	"\arequest\x18\x02 \x01(\v2#.ai.h2o.usage.v1.CreateEventRequestB\x03\xe0A\x02R\arequest\"\x8c\x01\n" +
+	"\x14IngestEventsResponse\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x12,\n" +
+	"\x05event\x18\x02 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\x12*\n" +
+	"\x06status\x18\x03 \x01(\v2\x12.google.rpc.StatusR\x06status\"O\n" +
+	"\x12WatchEventsRequest\x12\x16\n" +
+	"\x06filter\x18\x01 \x01(\tR\x06filter\x12!\n" +
+	"\fresume_token\x18\x02 \x01(\tR\vresumeToken\"f\n" +
+	"\x13WatchEventsResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\x12!\n" +
+	"\fresume_token\x18\x02 \x01(\tR\vresumeToken\"A\n" +
+	"\x0fGetEventRequest\x12.\n" +
+	"\x04name\x18\x01 \x01(\tB\x1a\xe0A\x02\xfaA\x14\n" +
+	"\x12usage.h2o.ai/EventR\x04name\"@\n" +
+	"\x10GetEventResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"\x89\x01\n" +
+	"\x12UpdateEventRequest\x121\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventB\x03\xe0A\x02R\x05event\x12@\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskB\x03\xe0A\x01R\n" +
+	"updateMask\"C\n" +
+	"\x13UpdateEventResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"]\n" +
+	"\x12DeleteEventRequest\x12.\n" +
+	"\x04name\x18\x01 \x01(\tB\x1a\xe0A\x02\xfaA\x14\n" +
+	"\x12usage.h2o.ai/EventR\x04name\x12\x17\n" +
+	"\x04etag\x18\x02 \x01(\tB\x03\xe0A\x01R\x04etag\"C\n" +
+	"\x13DeleteEventResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"_\n" +
+	"\x14UndeleteEventRequest\x12.\n" +
+	"\x04name\x18\x01 \x01(\tB\x1a\xe0A\x02\xfaA\x14\n" +
+	"\x12usage.h2o.ai/EventR\x04name\x12\x17\n" +
+	"\x04etag\x18\x02 \x01(\tB\x03\xe0A\x01R\x04etag\"E\n" +
+	"\x15UndeleteEventResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.EventR\x05event\"\xa5\x01\n" +
+	"\x11ListEventsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06filter\x18\x03 \x01(\tR\x06filter\x12\x19\n" +
+	"\border_by\x18\x04 \x01(\tR\aorderBy\x12!\n" +
+	"\fshow_deleted\x18\x05 \x01(\bR\vshowDeleted\"l\n" +
+	"\x12ListEventsResponse\x12.\n" +
+	"\x06events\x18\x01 \x03(\v2\x16.ai.h2o.usage.v1.EventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xb9\x01\n" +
+	"\x15AggregateUsageRequest\x12\x1b\n" +
+	"\x06filter\x18\x01 \x01(\tB\x03\xe0A\x01R\x06filter\x12\x1e\n" +
+	"\bgroup_by\x18\x02 \x03(\tB\x03\xe0A\x01R\agroupBy\x12A\n" +
+	"\vtime_bucket\x18\x03 \x01(\x0e2\x1b.ai.h2o.usage.v1.TimeBucketB\x03\xe0A\x01R\n" +
+	"timeBucket\x12 \n" +
+	"\ttime_zone\x18\x04 \x01(\tB\x03\xe0A\x01R\btimeZone\"Y\n" +
+	"\x16AggregateUsageResponse\x12?\n" +
+	"\n" +
+	"aggregates\x18\x01 \x03(\v2\x1f.ai.h2o.usage.v1.UsageAggregateR\n" +
+	"aggregates\"\x82\x06\n" +
+	"\x0eUsageAggregate\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12C\n" +
+	"\x06labels\x18\x04 \x03(\v2+.ai.h2o.usage.v1.UsageAggregate.LabelsEntryR\x06labels\x121\n" +
+	"\binterval\x18\x05 \x01(\v2\x15.google.type.IntervalR\binterval\x12\x1f\n" +
+	"\vevent_count\x18\x06 \x01(\x03R\n" +
+	"eventCount\x12S\n" +
+	"\x18total_execution_duration\x18\a \x01(\v2\x19.google.protobuf.DurationR\x16totalExecutionDuration\x12W\n" +
+	"\x1aaverage_execution_duration\x18\b \x01(\v2\x19.google.protobuf.DurationR\x18averageExecutionDuration\x12O\n" +
+	"\x16p50_execution_duration\x18\t \x01(\v2\x19.google.protobuf.DurationR\x14p50ExecutionDuration\x12O\n" +
+	"\x16p95_execution_duration\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\x14p95ExecutionDuration\x12O\n" +
+	"\x16max_execution_duration\x18\v \x01(\v2\x19.google.protobuf.DurationR\x14maxExecutionDuration\x121\n" +
+	"\n" +
+	"total_cost\x18\f \x03(\v2\x12.google.type.MoneyR\ttotalCost\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"l\n" +
+	"\x11CheckQuotaRequest\x12\x1d\n" +
+	"\asubject\x18\x01 \x01(\tB\x03\xe0A\x02R\asubject\x12\x1b\n" +
+	"\x06source\x18\x02 \x01(\tB\x03\xe0A\x02R\x06source\x12\x1b\n" +
+	"\x06action\x18\x03 \x01(\tB\x03\xe0A\x02R\x06action\"c\n" +
+	"\x12CheckQuotaResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x123\n" +
+	"\x06quotas\x18\x02 \x03(\v2\x1b.ai.h2o.usage.v1.QuotaUsageR\x06quotas\"\xe0\x02\n" +
+	"\n" +
+	"QuotaUsage\x12\x19\n" +
+	"\bquota_id\x18\x01 \x01(\tR\aquotaId\x12-\n" +
+	"\x06window\x18\x02 \x01(\v2\x15.google.type.IntervalR\x06window\x12\x1f\n" +
+	"\vevent_limit\x18\x03 \x01(\x03R\n" +
+	"eventLimit\x12\x1f\n" +
+	"\vevent_count\x18\x04 \x01(\x03R\n" +
+	"eventCount\x12S\n" +
+	"\x18execution_duration_limit\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x16executionDurationLimit\x12S\n" +
+	"\x18total_execution_duration\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x16totalExecutionDuration\x12\x1c\n" +
+	"\texhausted\x18\a \x01(\bR\texhausted*k\n" +
+	"\n" +
+	"TimeBucket\x12\x1b\n" +
+	"\x17TIME_BUCKET_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10TIME_BUCKET_HOUR\x10\x01\x12\x13\n" +
+	"\x0fTIME_BUCKET_DAY\x10\x02\x12\x15\n" +
+	"\x11TIME_BUCKET_MONTH\x10\x032\xa2\n" +
+	"\n" +
+	"\fEventService\x12o\n" +
+	"\vCreateEvent\x12#.ai.h2o.usage.v1.CreateEventRequest\x1a$.ai.h2o.usage.v1.CreateEventResponse\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/events\x12\x8d\x01\n" +
+	"\x11BatchCreateEvents\x12).ai.h2o.usage.v1.BatchCreateEventsRequest\x1a*.ai.h2o.usage.v1.BatchCreateEventsResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/events:batchCreate\x12_\n" +
+	"\fIngestEvents\x12$.ai.h2o.usage.v1.IngestEventsRequest\x1a%.ai.h2o.usage.v1.IngestEventsResponse(\x010\x01\x12Z\n" +
+	"\vWatchEvents\x12#.ai.h2o.usage.v1.WatchEventsRequest\x1a$.ai.h2o.usage.v1.WatchEventsResponse0\x01\x12l\n" +
+	"\bGetEvent\x12 .ai.h2o.usage.v1.GetEventRequest\x1a!.ai.h2o.usage.v1.GetEventResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/{name=events/*}\x12\x82\x01\n" +
+	"\vUpdateEvent\x12#.ai.h2o.usage.v1.UpdateEventRequest\x1a$.ai.h2o.usage.v1.UpdateEventResponse\"(\x82\xd3\xe4\x93\x02\":\x05event2\x19/v1/{event.name=events/*}\x12u\n" +
+	"\vDeleteEvent\x12#.ai.h2o.usage.v1.DeleteEventRequest\x1a$.ai.h2o.usage.v1.DeleteEventResponse\"\x1b\x82\xd3\xe4\x93\x02\x15*\x13/v1/{name=events/*}\x12\x87\x01\n" +
+	"\rUndeleteEvent\x12%.ai.h2o.usage.v1.UndeleteEventRequest\x1a&.ai.h2o.usage.v1.UndeleteEventResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/{name=events/*}:undelete\x12i\n" +
+	"\n" +
+	"ListEvents\x12\".ai.h2o.usage.v1.ListEventsRequest\x1a#.ai.h2o.usage.v1.ListEventsResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/events\x12\x7f\n" +
+	"\x0eAggregateUsage\x12&.ai.h2o.usage.v1.AggregateUsageRequest\x1a'.ai.h2o.usage.v1.AggregateUsageResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/events:aggregate\x12t\n" +
+	"\n" +
+	"CheckQuota\x12\".ai.h2o.usage.v1.CheckQuotaRequest\x1a#.ai.h2o.usage.v1.CheckQuotaResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/v1/events:checkQuotaB\xc6\x01\n" +
 	"\x13com.ai.h2o.usage.v1B\x11EventServiceProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
 
 var (
@@ -260,27 +1838,102 @@ func file_ai_h2o_usage_v1_event_service_proto_rawDescGZIP() []byte {
 	return file_ai_h2o_usage_v1_event_service_proto_rawDescData
 }
 
-var file_ai_h2o_usage_v1_event_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_ai_h2o_usage_v1_event_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ai_h2o_usage_v1_event_service_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_ai_h2o_usage_v1_event_service_proto_goTypes = []any{
-	(*CreateEventRequest)(nil),  // 0: ai.h2o.usage.v1.CreateEventRequest
-	(*CreateEventResponse)(nil), // 1: ai.h2o.usage.v1.CreateEventResponse
-	(*ListEventsRequest)(nil),   // 2: ai.h2o.usage.v1.ListEventsRequest
-	(*ListEventsResponse)(nil),  // 3: ai.h2o.usage.v1.ListEventsResponse
-	(*Event)(nil),               // 4: ai.h2o.usage.v1.Event
+	(TimeBucket)(0),                           // 0: ai.h2o.usage.v1.TimeBucket
+	(*CreateEventRequest)(nil),                // 1: ai.h2o.usage.v1.CreateEventRequest
+	(*CreateEventResponse)(nil),               // 2: ai.h2o.usage.v1.CreateEventResponse
+	(*BatchCreateEventsRequest)(nil),          // 3: ai.h2o.usage.v1.BatchCreateEventsRequest
+	(*BatchCreateEventsResponse)(nil),         // 4: ai.h2o.usage.v1.BatchCreateEventsResponse
+	(*IngestEventsRequest)(nil),               // 5: ai.h2o.usage.v1.IngestEventsRequest
+	(*IngestEventsResponse)(nil),              // 6: ai.h2o.usage.v1.IngestEventsResponse
+	(*WatchEventsRequest)(nil),                // 7: ai.h2o.usage.v1.WatchEventsRequest
+	(*WatchEventsResponse)(nil),               // 8: ai.h2o.usage.v1.WatchEventsResponse
+	(*GetEventRequest)(nil),                   // 9: ai.h2o.usage.v1.GetEventRequest
+	(*GetEventResponse)(nil),                  // 10: ai.h2o.usage.v1.GetEventResponse
+	(*UpdateEventRequest)(nil),                // 11: ai.h2o.usage.v1.UpdateEventRequest
+	(*UpdateEventResponse)(nil),               // 12: ai.h2o.usage.v1.UpdateEventResponse
+	(*DeleteEventRequest)(nil),                // 13: ai.h2o.usage.v1.DeleteEventRequest
+	(*DeleteEventResponse)(nil),               // 14: ai.h2o.usage.v1.DeleteEventResponse
+	(*UndeleteEventRequest)(nil),              // 15: ai.h2o.usage.v1.UndeleteEventRequest
+	(*UndeleteEventResponse)(nil),             // 16: ai.h2o.usage.v1.UndeleteEventResponse
+	(*ListEventsRequest)(nil),                 // 17: ai.h2o.usage.v1.ListEventsRequest
+	(*ListEventsResponse)(nil),                // 18: ai.h2o.usage.v1.ListEventsResponse
+	(*AggregateUsageRequest)(nil),             // 19: ai.h2o.usage.v1.AggregateUsageRequest
+	(*AggregateUsageResponse)(nil),            // 20: ai.h2o.usage.v1.AggregateUsageResponse
+	(*UsageAggregate)(nil),                    // 21: ai.h2o.usage.v1.UsageAggregate
+	(*CheckQuotaRequest)(nil),                 // 22: ai.h2o.usage.v1.CheckQuotaRequest
+	(*CheckQuotaResponse)(nil),                // 23: ai.h2o.usage.v1.CheckQuotaResponse
+	(*QuotaUsage)(nil),                        // 24: ai.h2o.usage.v1.QuotaUsage
+	(*BatchCreateEventsResponse_Failure)(nil), // 25: ai.h2o.usage.v1.BatchCreateEventsResponse.Failure
+	nil,                           // 26: ai.h2o.usage.v1.UsageAggregate.LabelsEntry
+	(*Event)(nil),                 // 27: ai.h2o.usage.v1.Event
+	(*status.Status)(nil),         // 28: google.rpc.Status
+	(*fieldmaskpb.FieldMask)(nil), // 29: google.protobuf.FieldMask
+	(*interval.Interval)(nil),     // 30: google.type.Interval
+	(*durationpb.Duration)(nil),   // 31: google.protobuf.Duration
+	(*money.Money)(nil),           // 32: google.type.Money
 }
 var file_ai_h2o_usage_v1_event_service_proto_depIdxs = []int32{
-	4, // 0: ai.h2o.usage.v1.CreateEventRequest.event:type_name -> ai.h2o.usage.v1.Event
-	4, // 1: ai.h2o.usage.v1.CreateEventResponse.event:type_name -> ai.h2o.usage.v1.Event
-	4, // 2: ai.h2o.usage.v1.ListEventsResponse.events:type_name -> ai.h2o.usage.v1.Event
-	0, // 3: ai.h2o.usage.v1.EventService.CreateEvent:input_type -> ai.h2o.usage.v1.CreateEventRequest
-	2, // 4: ai.h2o.usage.v1.EventService.ListEvents:input_type -> ai.h2o.usage.v1.ListEventsRequest
-	1, // 5: ai.h2o.usage.v1.EventService.CreateEvent:output_type -> ai.h2o.usage.v1.CreateEventResponse
-	3, // 6: ai.h2o.usage.v1.EventService.ListEvents:output_type -> ai.h2o.usage.v1.ListEventsResponse
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	27, // 0: ai.h2o.usage.v1.CreateEventRequest.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 1: ai.h2o.usage.v1.CreateEventResponse.event:type_name -> ai.h2o.usage.v1.Event
+	1,  // 2: ai.h2o.usage.v1.BatchCreateEventsRequest.requests:type_name -> ai.h2o.usage.v1.CreateEventRequest
+	27, // 3: ai.h2o.usage.v1.BatchCreateEventsResponse.events:type_name -> ai.h2o.usage.v1.Event
+	25, // 4: ai.h2o.usage.v1.BatchCreateEventsResponse.failures:type_name -> ai.h2o.usage.v1.BatchCreateEventsResponse.Failure
+	1,  // 5: ai.h2o.usage.v1.IngestEventsRequest.request:type_name -> ai.h2o.usage.v1.CreateEventRequest
+	27, // 6: ai.h2o.usage.v1.IngestEventsResponse.event:type_name -> ai.h2o.usage.v1.Event
+	28, // 7: ai.h2o.usage.v1.IngestEventsResponse.status:type_name -> google.rpc.Status
+	27, // 8: ai.h2o.usage.v1.WatchEventsResponse.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 9: ai.h2o.usage.v1.GetEventResponse.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 10: ai.h2o.usage.v1.UpdateEventRequest.event:type_name -> ai.h2o.usage.v1.Event
+	29, // 11: ai.h2o.usage.v1.UpdateEventRequest.update_mask:type_name -> google.protobuf.FieldMask
+	27, // 12: ai.h2o.usage.v1.UpdateEventResponse.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 13: ai.h2o.usage.v1.DeleteEventResponse.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 14: ai.h2o.usage.v1.UndeleteEventResponse.event:type_name -> ai.h2o.usage.v1.Event
+	27, // 15: ai.h2o.usage.v1.ListEventsResponse.events:type_name -> ai.h2o.usage.v1.Event
+	0,  // 16: ai.h2o.usage.v1.AggregateUsageRequest.time_bucket:type_name -> ai.h2o.usage.v1.TimeBucket
+	21, // 17: ai.h2o.usage.v1.AggregateUsageResponse.aggregates:type_name -> ai.h2o.usage.v1.UsageAggregate
+	26, // 18: ai.h2o.usage.v1.UsageAggregate.labels:type_name -> ai.h2o.usage.v1.UsageAggregate.LabelsEntry
+	30, // 19: ai.h2o.usage.v1.UsageAggregate.interval:type_name -> google.type.Interval
+	31, // 20: ai.h2o.usage.v1.UsageAggregate.total_execution_duration:type_name -> google.protobuf.Duration
+	31, // 21: ai.h2o.usage.v1.UsageAggregate.average_execution_duration:type_name -> google.protobuf.Duration
+	31, // 22: ai.h2o.usage.v1.UsageAggregate.p50_execution_duration:type_name -> google.protobuf.Duration
+	31, // 23: ai.h2o.usage.v1.UsageAggregate.p95_execution_duration:type_name -> google.protobuf.Duration
+	31, // 24: ai.h2o.usage.v1.UsageAggregate.max_execution_duration:type_name -> google.protobuf.Duration
+	32, // 25: ai.h2o.usage.v1.UsageAggregate.total_cost:type_name -> google.type.Money
+	24, // 26: ai.h2o.usage.v1.CheckQuotaResponse.quotas:type_name -> ai.h2o.usage.v1.QuotaUsage
+	30, // 27: ai.h2o.usage.v1.QuotaUsage.window:type_name -> google.type.Interval
+	31, // 28: ai.h2o.usage.v1.QuotaUsage.execution_duration_limit:type_name -> google.protobuf.Duration
+	31, // 29: ai.h2o.usage.v1.QuotaUsage.total_execution_duration:type_name -> google.protobuf.Duration
+	28, // 30: ai.h2o.usage.v1.BatchCreateEventsResponse.Failure.status:type_name -> google.rpc.Status
+	1,  // 31: ai.h2o.usage.v1.EventService.CreateEvent:input_type -> ai.h2o.usage.v1.CreateEventRequest
+	3,  // 32: ai.h2o.usage.v1.EventService.BatchCreateEvents:input_type -> ai.h2o.usage.v1.BatchCreateEventsRequest
+	5,  // 33: ai.h2o.usage.v1.EventService.IngestEvents:input_type -> ai.h2o.usage.v1.IngestEventsRequest
+	7,  // 34: ai.h2o.usage.v1.EventService.WatchEvents:input_type -> ai.h2o.usage.v1.WatchEventsRequest
+	9,  // 35: ai.h2o.usage.v1.EventService.GetEvent:input_type -> ai.h2o.usage.v1.GetEventRequest
+	11, // 36: ai.h2o.usage.v1.EventService.UpdateEvent:input_type -> ai.h2o.usage.v1.UpdateEventRequest
+	13, // 37: ai.h2o.usage.v1.EventService.DeleteEvent:input_type -> ai.h2o.usage.v1.DeleteEventRequest
+	15, // 38: ai.h2o.usage.v1.EventService.UndeleteEvent:input_type -> ai.h2o.usage.v1.UndeleteEventRequest
+	17, // 39: ai.h2o.usage.v1.EventService.ListEvents:input_type -> ai.h2o.usage.v1.ListEventsRequest
+	19, // 40: ai.h2o.usage.v1.EventService.AggregateUsage:input_type -> ai.h2o.usage.v1.AggregateUsageRequest
+	22, // 41: ai.h2o.usage.v1.EventService.CheckQuota:input_type -> ai.h2o.usage.v1.CheckQuotaRequest
+	2,  // 42: ai.h2o.usage.v1.EventService.CreateEvent:output_type -> ai.h2o.usage.v1.CreateEventResponse
+	4,  // 43: ai.h2o.usage.v1.EventService.BatchCreateEvents:output_type -> ai.h2o.usage.v1.BatchCreateEventsResponse
+	6,  // 44: ai.h2o.usage.v1.EventService.IngestEvents:output_type -> ai.h2o.usage.v1.IngestEventsResponse
+	8,  // 45: ai.h2o.usage.v1.EventService.WatchEvents:output_type -> ai.h2o.usage.v1.WatchEventsResponse
+	10, // 46: ai.h2o.usage.v1.EventService.GetEvent:output_type -> ai.h2o.usage.v1.GetEventResponse
+	12, // 47: ai.h2o.usage.v1.EventService.UpdateEvent:output_type -> ai.h2o.usage.v1.UpdateEventResponse
+	14, // 48: ai.h2o.usage.v1.EventService.DeleteEvent:output_type -> ai.h2o.usage.v1.DeleteEventResponse
+	16, // 49: ai.h2o.usage.v1.EventService.UndeleteEvent:output_type -> ai.h2o.usage.v1.UndeleteEventResponse
+	18, // 50: ai.h2o.usage.v1.EventService.ListEvents:output_type -> ai.h2o.usage.v1.ListEventsResponse
+	20, // 51: ai.h2o.usage.v1.EventService.AggregateUsage:output_type -> ai.h2o.usage.v1.AggregateUsageResponse
+	23, // 52: ai.h2o.usage.v1.EventService.CheckQuota:output_type -> ai.h2o.usage.v1.CheckQuotaResponse
+	42, // [42:53] is the sub-list for method output_type
+	31, // [31:42] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_ai_h2o_usage_v1_event_service_proto_init() }
@@ -294,13 +1947,14 @@ func file_ai_h2o_usage_v1_event_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_event_service_proto_rawDesc), len(file_ai_h2o_usage_v1_event_service_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_ai_h2o_usage_v1_event_service_proto_goTypes,
 		DependencyIndexes: file_ai_h2o_usage_v1_event_service_proto_depIdxs,
+		EnumInfos:         file_ai_h2o_usage_v1_event_service_proto_enumTypes,
 		MessageInfos:      file_ai_h2o_usage_v1_event_service_proto_msgTypes,
 	}.Build()
 	File_ai_h2o_usage_v1_event_service_proto = out.File
@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EventService_CreateEvent_FullMethodName = "/ai.h2o.usage.v1.EventService/CreateEvent"
-	EventService_ListEvents_FullMethodName  = "/ai.h2o.usage.v1.EventService/ListEvents"
+	EventService_CreateEvent_FullMethodName       = "/ai.h2o.usage.v1.EventService/CreateEvent"
+	EventService_BatchCreateEvents_FullMethodName = "/ai.h2o.usage.v1.EventService/BatchCreateEvents"
+	EventService_IngestEvents_FullMethodName      = "/ai.h2o.usage.v1.EventService/IngestEvents"
+	EventService_WatchEvents_FullMethodName       = "/ai.h2o.usage.v1.EventService/WatchEvents"
+	EventService_GetEvent_FullMethodName          = "/ai.h2o.usage.v1.EventService/GetEvent"
+	EventService_UpdateEvent_FullMethodName       = "/ai.h2o.usage.v1.EventService/UpdateEvent"
+	EventService_DeleteEvent_FullMethodName       = "/ai.h2o.usage.v1.EventService/DeleteEvent"
+	EventService_UndeleteEvent_FullMethodName     = "/ai.h2o.usage.v1.EventService/UndeleteEvent"
+	EventService_ListEvents_FullMethodName        = "/ai.h2o.usage.v1.EventService/ListEvents"
+	EventService_AggregateUsage_FullMethodName    = "/ai.h2o.usage.v1.EventService/AggregateUsage"
+	EventService_CheckQuota_FullMethodName        = "/ai.h2o.usage.v1.EventService/CheckQuota"
 )
 
 // EventServiceClient is the client API for EventService service.
@@ -31,8 +40,49 @@ const (
 type EventServiceClient interface {
 	// Creates a new usage event.
 	CreateEvent(ctx context.Context, in *CreateEventRequest, opts ...grpc.CallOption) (*CreateEventResponse, error)
+	// Creates a batch of usage events.
+	BatchCreateEvents(ctx context.Context, in *BatchCreateEventsRequest, opts ...grpc.CallOption) (*BatchCreateEventsResponse, error)
+	// Ingests a continuous stream of usage events. Each request is answered
+	// with a response carrying the same `sequence`, which either acknowledges
+	// the created event or rejects the request. Requests are processed in
+	// order; when the server falls behind it stops reading, so flow control
+	// slows the client down.
+	IngestEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[IngestEventsRequest, IngestEventsResponse], error)
+	// Streams usage events as they are created. If `resume_token` is set, the
+	// events created after the one it was issued for are replayed first.
+	// Events are streamed in `create_time` order, so a resumed stream neither
+	// skips nor repeats events.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error)
+	// Gets a usage event.
+	GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*GetEventResponse, error)
+	// Updates a usage event, e.g. to correct its `execution_duration` or
+	// `action`.
+	UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*UpdateEventResponse, error)
+	// Deletes a usage event. The event is soft-deleted: it is hidden from
+	// `ListEvents` and can be restored with `UndeleteEvent` until its
+	// `purge_time`, after which it is permanently removed.
+	DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*DeleteEventResponse, error)
+	// Restores a soft-deleted usage event.
+	UndeleteEvent(ctx context.Context, in *UndeleteEventRequest, opts ...grpc.CallOption) (*UndeleteEventResponse, error)
 	// Lists usage events.
 	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	// Aggregates the usage recorded by events into totals, optionally grouped
+	// by event fields and time buckets. Deleted events are not counted.
+	//
+	// When grouping by `subject`, `source` and `action` only, with a filter on
+	// those fields and on `create_time` by `>=` and `<` comparisons with whole
+	// hours, the totals are read from hourly and daily rollups maintained as
+	// events are written, rather than from the events themselves. The
+	// percentiles and maximum of `execution_duration` are then estimates within
+	// 1% of the exact values.
+	AggregateUsage(ctx context.Context, in *AggregateUsageRequest, opts ...grpc.CallOption) (*AggregateUsageResponse, error)
+	// Reports the usage of a subject against the quotas that apply to its
+	// events of a source and action, so that clients can check whether such an
+	// event would be accepted before doing the work it records. When the server
+	// enforces quotas, CreateEvent and BatchCreateEvents reject events of
+	// exhausted quotas with `RESOURCE_EXHAUSTED` and a `google.rpc.QuotaFailure`
+	// detail.
+	CheckQuota(ctx context.Context, in *CheckQuotaRequest, opts ...grpc.CallOption) (*CheckQuotaResponse, error)
 }
 
 type eventServiceClient struct {
@@ -53,6 +103,88 @@ func (c *eventServiceClient) CreateEvent(ctx context.Context, in *CreateEventReq
 	return out, nil
 }
 
+func (c *eventServiceClient) BatchCreateEvents(ctx context.Context, in *BatchCreateEventsRequest, opts ...grpc.CallOption) (*BatchCreateEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_BatchCreateEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) IngestEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[IngestEventsRequest, IngestEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], EventService_IngestEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestEventsRequest, IngestEventsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_IngestEventsClient = grpc.BidiStreamingClient[IngestEventsRequest, IngestEventsResponse]
+
+func (c *eventServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[1], EventService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, WatchEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_WatchEventsClient = grpc.ServerStreamingClient[WatchEventsResponse]
+
+func (c *eventServiceClient) GetEvent(ctx context.Context, in *GetEventRequest, opts ...grpc.CallOption) (*GetEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEventResponse)
+	err := c.cc.Invoke(ctx, EventService_GetEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UpdateEvent(ctx context.Context, in *UpdateEventRequest, opts ...grpc.CallOption) (*UpdateEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateEventResponse)
+	err := c.cc.Invoke(ctx, EventService_UpdateEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*DeleteEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteEventResponse)
+	err := c.cc.Invoke(ctx, EventService_DeleteEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) UndeleteEvent(ctx context.Context, in *UndeleteEventRequest, opts ...grpc.CallOption) (*UndeleteEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndeleteEventResponse)
+	err := c.cc.Invoke(ctx, EventService_UndeleteEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eventServiceClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListEventsResponse)
@@ -63,6 +195,26 @@ func (c *eventServiceClient) ListEvents(ctx context.Context, in *ListEventsReque
 	return out, nil
 }
 
+func (c *eventServiceClient) AggregateUsage(ctx context.Context, in *AggregateUsageRequest, opts ...grpc.CallOption) (*AggregateUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AggregateUsageResponse)
+	err := c.cc.Invoke(ctx, EventService_AggregateUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) CheckQuota(ctx context.Context, in *CheckQuotaRequest, opts ...grpc.CallOption) (*CheckQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckQuotaResponse)
+	err := c.cc.Invoke(ctx, EventService_CheckQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventServiceServer is the server API for EventService service.
 // All implementations must embed UnimplementedEventServiceServer
 // for forward compatibility.
@@ -71,8 +223,49 @@ func (c *eventServiceClient) ListEvents(ctx context.Context, in *ListEventsReque
 type EventServiceServer interface {
 	// Creates a new usage event.
 	CreateEvent(context.Context, *CreateEventRequest) (*CreateEventResponse, error)
+	// Creates a batch of usage events.
+	BatchCreateEvents(context.Context, *BatchCreateEventsRequest) (*BatchCreateEventsResponse, error)
+	// Ingests a continuous stream of usage events. Each request is answered
+	// with a response carrying the same `sequence`, which either acknowledges
+	// the created event or rejects the request. Requests are processed in
+	// order; when the server falls behind it stops reading, so flow control
+	// slows the client down.
+	IngestEvents(grpc.BidiStreamingServer[IngestEventsRequest, IngestEventsResponse]) error
+	// Streams usage events as they are created. If `resume_token` is set, the
+	// events created after the one it was issued for are replayed first.
+	// Events are streamed in `create_time` order, so a resumed stream neither
+	// skips nor repeats events.
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error
+	// Gets a usage event.
+	GetEvent(context.Context, *GetEventRequest) (*GetEventResponse, error)
+	// Updates a usage event, e.g. to correct its `execution_duration` or
+	// `action`.
+	UpdateEvent(context.Context, *UpdateEventRequest) (*UpdateEventResponse, error)
+	// Deletes a usage event. The event is soft-deleted: it is hidden from
+	// `ListEvents` and can be restored with `UndeleteEvent` until its
+	// `purge_time`, after which it is permanently removed.
+	DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error)
+	// Restores a soft-deleted usage event.
+	UndeleteEvent(context.Context, *UndeleteEventRequest) (*UndeleteEventResponse, error)
 	// Lists usage events.
 	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	// Aggregates the usage recorded by events into totals, optionally grouped
+	// by event fields and time buckets. Deleted events are not counted.
+	//
+	// When grouping by `subject`, `source` and `action` only, with a filter on
+	// those fields and on `create_time` by `>=` and `<` comparisons with whole
+	// hours, the totals are read from hourly and daily rollups maintained as
+	// events are written, rather than from the events themselves. The
+	// percentiles and maximum of `execution_duration` are then estimates within
+	// 1% of the exact values.
+	AggregateUsage(context.Context, *AggregateUsageRequest) (*AggregateUsageResponse, error)
+	// Reports the usage of a subject against the quotas that apply to its
+	// events of a source and action, so that clients can check whether such an
+	// event would be accepted before doing the work it records. When the server
+	// enforces quotas, CreateEvent and BatchCreateEvents reject events of
+	// exhausted quotas with `RESOURCE_EXHAUSTED` and a `google.rpc.QuotaFailure`
+	// detail.
+	CheckQuota(context.Context, *CheckQuotaRequest) (*CheckQuotaResponse, error)
 	mustEmbedUnimplementedEventServiceServer()
 }
 
@@ -86,9 +279,36 @@ type UnimplementedEventServiceServer struct{}
 func (UnimplementedEventServiceServer) CreateEvent(context.Context, *CreateEventRequest) (*CreateEventResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateEvent not implemented")
 }
+func (UnimplementedEventServiceServer) BatchCreateEvents(context.Context, *BatchCreateEventsRequest) (*BatchCreateEventsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchCreateEvents not implemented")
+}
+func (UnimplementedEventServiceServer) IngestEvents(grpc.BidiStreamingServer[IngestEventsRequest, IngestEventsResponse]) error {
+	return status.Error(codes.Unimplemented, "method IngestEvents not implemented")
+}
+func (UnimplementedEventServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedEventServiceServer) GetEvent(context.Context, *GetEventRequest) (*GetEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEvent not implemented")
+}
+func (UnimplementedEventServiceServer) UpdateEvent(context.Context, *UpdateEventRequest) (*UpdateEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateEvent not implemented")
+}
+func (UnimplementedEventServiceServer) DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteEvent not implemented")
+}
+func (UnimplementedEventServiceServer) UndeleteEvent(context.Context, *UndeleteEventRequest) (*UndeleteEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UndeleteEvent not implemented")
+}
 func (UnimplementedEventServiceServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListEvents not implemented")
 }
+func (UnimplementedEventServiceServer) AggregateUsage(context.Context, *AggregateUsageRequest) (*AggregateUsageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AggregateUsage not implemented")
+}
+func (UnimplementedEventServiceServer) CheckQuota(context.Context, *CheckQuotaRequest) (*CheckQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckQuota not implemented")
+}
 func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
 func (UnimplementedEventServiceServer) testEmbeddedByValue()                      {}
 
@@ -128,6 +348,114 @@ func _EventService_CreateEvent_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_BatchCreateEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).BatchCreateEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_BatchCreateEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).BatchCreateEvents(ctx, req.(*BatchCreateEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_IngestEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventServiceServer).IngestEvents(&grpc.GenericServerStream[IngestEventsRequest, IngestEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_IngestEventsServer = grpc.BidiStreamingServer[IngestEventsRequest, IngestEventsResponse]
+
+func _EventService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, WatchEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_WatchEventsServer = grpc.ServerStreamingServer[WatchEventsResponse]
+
+func _EventService_GetEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetEvent(ctx, req.(*GetEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UpdateEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UpdateEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UpdateEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UpdateEvent(ctx, req.(*UpdateEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_DeleteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).DeleteEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_DeleteEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).DeleteEvent(ctx, req.(*DeleteEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_UndeleteEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndeleteEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).UndeleteEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_UndeleteEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).UndeleteEvent(ctx, req.(*UndeleteEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EventService_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListEventsRequest)
 	if err := dec(in); err != nil {
@@ -146,6 +474,42 @@ func _EventService_ListEvents_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_AggregateUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).AggregateUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_AggregateUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).AggregateUsage(ctx, req.(*AggregateUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_CheckQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).CheckQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_CheckQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).CheckQuota(ctx, req.(*CheckQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,11 +521,51 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateEvent",
 			Handler:    _EventService_CreateEvent_Handler,
 		},
+		{
+			MethodName: "BatchCreateEvents",
+			Handler:    _EventService_BatchCreateEvents_Handler,
+		},
+		{
+			MethodName: "GetEvent",
+			Handler:    _EventService_GetEvent_Handler,
+		},
+		{
+			MethodName: "UpdateEvent",
+			Handler:    _EventService_UpdateEvent_Handler,
+		},
+		{
+			MethodName: "DeleteEvent",
+			Handler:    _EventService_DeleteEvent_Handler,
+		},
+		{
+			MethodName: "UndeleteEvent",
+			Handler:    _EventService_UndeleteEvent_Handler,
+		},
 		{
 			MethodName: "ListEvents",
 			Handler:    _EventService_ListEvents_Handler,
 		},
+		{
+			MethodName: "AggregateUsage",
+			Handler:    _EventService_AggregateUsage_Handler,
+		},
+		{
+			MethodName: "CheckQuota",
+			Handler:    _EventService_CheckQuota_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestEvents",
+			Handler:       _EventService_IngestEvents_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _EventService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "ai/h2o/usage/v1/event_service.proto",
 }
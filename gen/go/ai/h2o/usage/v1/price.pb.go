@@ -0,0 +1,326 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ai/h2o/usage/v1/price.proto
+
+package usagev1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	interval "google.golang.org/genproto/googleapis/type/interval"
+	money "google.golang.org/genproto/googleapis/type/money"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// How charges are rounded.
+type RoundingMode int32
+
+const (
+	// Same as `ROUNDING_MODE_HALF_UP`.
+	RoundingMode_ROUNDING_MODE_UNSPECIFIED RoundingMode = 0
+	// Round to the nearest increment; halves are rounded up.
+	RoundingMode_ROUNDING_MODE_HALF_UP RoundingMode = 1
+	// Round up to the next increment.
+	RoundingMode_ROUNDING_MODE_UP RoundingMode = 2
+	// Round down to the previous increment.
+	RoundingMode_ROUNDING_MODE_DOWN RoundingMode = 3
+)
+
+// Enum value maps for RoundingMode.
+var (
+	RoundingMode_name = map[int32]string{
+		0: "ROUNDING_MODE_UNSPECIFIED",
+		1: "ROUNDING_MODE_HALF_UP",
+		2: "ROUNDING_MODE_UP",
+		3: "ROUNDING_MODE_DOWN",
+	}
+	RoundingMode_value = map[string]int32{
+		"ROUNDING_MODE_UNSPECIFIED": 0,
+		"ROUNDING_MODE_HALF_UP":     1,
+		"ROUNDING_MODE_UP":          2,
+		"ROUNDING_MODE_DOWN":        3,
+	}
+)
+
+func (x RoundingMode) Enum() *RoundingMode {
+	p := new(RoundingMode)
+	*p = x
+	return p
+}
+
+func (x RoundingMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RoundingMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_ai_h2o_usage_v1_price_proto_enumTypes[0].Descriptor()
+}
+
+func (RoundingMode) Type() protoreflect.EnumType {
+	return &file_ai_h2o_usage_v1_price_proto_enumTypes[0]
+}
+
+func (x RoundingMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RoundingMode.Descriptor instead.
+func (RoundingMode) EnumDescriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_proto_rawDescGZIP(), []int{0}
+}
+
+// The price of an action of a source during a period of time. An event is
+// priced with the price of its source and action in effect at its
+// `create_time`.
+//
+// Amounts are immutable: to change a price, end its `effective_time` and
+// create a new price effective from then on.
+type Price struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resource name of the price.
+	// Format: `sources/{source}/actions/{action}/prices/{price}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The period in which the price is in effect. The start time is inclusive
+	// and the end time exclusive; if either is unset, the period is open on
+	// that side. The periods of the prices of an action must not overlap.
+	EffectiveTime *interval.Interval `protobuf:"bytes,2,opt,name=effective_time,json=effectiveTime,proto3" json:"effective_time,omitempty"`
+	// The amount charged for every event. At least one of `per_call` and
+	// `per_second` must be set, and all amounts of a price must be in the same
+	// currency.
+	PerCall *money.Money `protobuf:"bytes,3,opt,name=per_call,json=perCall,proto3" json:"per_call,omitempty"`
+	// The amount charged per second of `execution_duration`.
+	PerSecond *money.Money `protobuf:"bytes,4,opt,name=per_second,json=perSecond,proto3" json:"per_second,omitempty"`
+	// The least amount charged for an event.
+	Minimum *money.Money `protobuf:"bytes,5,opt,name=minimum,proto3" json:"minimum,omitempty"`
+	// The granularity at which execution durations are billed: they are
+	// rounded up to a multiple of it. If unset, exact durations are billed.
+	BillingIncrement *durationpb.Duration `protobuf:"bytes,6,opt,name=billing_increment,json=billingIncrement,proto3" json:"billing_increment,omitempty"`
+	// The precision of charges, e.g. 0.01 for cents. If unset, charges are
+	// rounded to nanos.
+	RoundingIncrement *money.Money `protobuf:"bytes,7,opt,name=rounding_increment,json=roundingIncrement,proto3" json:"rounding_increment,omitempty"`
+	// How charges are rounded to `rounding_increment`.
+	RoundingMode RoundingMode `protobuf:"varint,8,opt,name=rounding_mode,json=roundingMode,proto3,enum=ai.h2o.usage.v1.RoundingMode" json:"rounding_mode,omitempty"`
+	// The time when the price was created.
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// The time when the price was last updated.
+	UpdateTime *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// A checksum of the price's current state, following AIP-154.
+	Etag          string `protobuf:"bytes,11,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Price) Reset() {
+	*x = Price{}
+	mi := &file_ai_h2o_usage_v1_price_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Price) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Price) ProtoMessage() {}
+
+func (x *Price) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Price.ProtoReflect.Descriptor instead.
+func (*Price) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Price) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Price) GetEffectiveTime() *interval.Interval {
+	if x != nil {
+		return x.EffectiveTime
+	}
+	return nil
+}
+
+func (x *Price) GetPerCall() *money.Money {
+	if x != nil {
+		return x.PerCall
+	}
+	return nil
+}
+
+func (x *Price) GetPerSecond() *money.Money {
+	if x != nil {
+		return x.PerSecond
+	}
+	return nil
+}
+
+func (x *Price) GetMinimum() *money.Money {
+	if x != nil {
+		return x.Minimum
+	}
+	return nil
+}
+
+func (x *Price) GetBillingIncrement() *durationpb.Duration {
+	if x != nil {
+		return x.BillingIncrement
+	}
+	return nil
+}
+
+func (x *Price) GetRoundingIncrement() *money.Money {
+	if x != nil {
+		return x.RoundingIncrement
+	}
+	return nil
+}
+
+func (x *Price) GetRoundingMode() RoundingMode {
+	if x != nil {
+		return x.RoundingMode
+	}
+	return RoundingMode_ROUNDING_MODE_UNSPECIFIED
+}
+
+func (x *Price) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Price) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Price) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+var File_ai_h2o_usage_v1_price_proto protoreflect.FileDescriptor
+
+const file_ai_h2o_usage_v1_price_proto_rawDesc = "" +
+	"\n" +
+	"\x1bai/h2o/usage/v1/price.proto\x12\x0fai.h2o.usage.v1\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1agoogle/type/interval.proto\x1a\x17google/type/money.proto\"\xe9\x05\n" +
+	"\x05Price\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tB\x03\xe0A\bR\x04name\x12A\n" +
+	"\x0eeffective_time\x18\x02 \x01(\v2\x15.google.type.IntervalB\x03\xe0A\x02R\reffectiveTime\x125\n" +
+	"\bper_call\x18\x03 \x01(\v2\x12.google.type.MoneyB\x06\xe0A\x01\xe0A\x05R\aperCall\x129\n" +
+	"\n" +
+	"per_second\x18\x04 \x01(\v2\x12.google.type.MoneyB\x06\xe0A\x01\xe0A\x05R\tperSecond\x124\n" +
+	"\aminimum\x18\x05 \x01(\v2\x12.google.type.MoneyB\x06\xe0A\x01\xe0A\x05R\aminimum\x12N\n" +
+	"\x11billing_increment\x18\x06 \x01(\v2\x19.google.protobuf.DurationB\x06\xe0A\x01\xe0A\x05R\x10billingIncrement\x12I\n" +
+	"\x12rounding_increment\x18\a \x01(\v2\x12.google.type.MoneyB\x06\xe0A\x01\xe0A\x05R\x11roundingIncrement\x12J\n" +
+	"\rrounding_mode\x18\b \x01(\x0e2\x1d.ai.h2o.usage.v1.RoundingModeB\x06\xe0A\x01\xe0A\x05R\froundingMode\x12@\n" +
+	"\vcreate_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"createTime\x12@\n" +
+	"\vupdate_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"updateTime\x12\x17\n" +
+	"\x04etag\x18\v \x01(\tB\x03\xe0A\x01R\x04etag:X\xeaAU\n" +
+	"\x12usage.h2o.ai/Price\x120sources/{source}/actions/{action}/prices/{price}*\x06prices2\x05price*v\n" +
+	"\fRoundingMode\x12\x1d\n" +
+	"\x19ROUNDING_MODE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ROUNDING_MODE_HALF_UP\x10\x01\x12\x14\n" +
+	"\x10ROUNDING_MODE_UP\x10\x02\x12\x16\n" +
+	"\x12ROUNDING_MODE_DOWN\x10\x03B\xbf\x01\n" +
+	"\x13com.ai.h2o.usage.v1B\n" +
+	"PriceProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
+
+var (
+	file_ai_h2o_usage_v1_price_proto_rawDescOnce sync.Once
+	file_ai_h2o_usage_v1_price_proto_rawDescData []byte
+)
+
+func file_ai_h2o_usage_v1_price_proto_rawDescGZIP() []byte {
+	file_ai_h2o_usage_v1_price_proto_rawDescOnce.Do(func() {
+		file_ai_h2o_usage_v1_price_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_price_proto_rawDesc), len(file_ai_h2o_usage_v1_price_proto_rawDesc)))
+	})
+	return file_ai_h2o_usage_v1_price_proto_rawDescData
+}
+
+var file_ai_h2o_usage_v1_price_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ai_h2o_usage_v1_price_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ai_h2o_usage_v1_price_proto_goTypes = []any{
+	(RoundingMode)(0),             // 0: ai.h2o.usage.v1.RoundingMode
+	(*Price)(nil),                 // 1: ai.h2o.usage.v1.Price
+	(*interval.Interval)(nil),     // 2: google.type.Interval
+	(*money.Money)(nil),           // 3: google.type.Money
+	(*durationpb.Duration)(nil),   // 4: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_ai_h2o_usage_v1_price_proto_depIdxs = []int32{
+	2, // 0: ai.h2o.usage.v1.Price.effective_time:type_name -> google.type.Interval
+	3, // 1: ai.h2o.usage.v1.Price.per_call:type_name -> google.type.Money
+	3, // 2: ai.h2o.usage.v1.Price.per_second:type_name -> google.type.Money
+	3, // 3: ai.h2o.usage.v1.Price.minimum:type_name -> google.type.Money
+	4, // 4: ai.h2o.usage.v1.Price.billing_increment:type_name -> google.protobuf.Duration
+	3, // 5: ai.h2o.usage.v1.Price.rounding_increment:type_name -> google.type.Money
+	0, // 6: ai.h2o.usage.v1.Price.rounding_mode:type_name -> ai.h2o.usage.v1.RoundingMode
+	5, // 7: ai.h2o.usage.v1.Price.create_time:type_name -> google.protobuf.Timestamp
+	5, // 8: ai.h2o.usage.v1.Price.update_time:type_name -> google.protobuf.Timestamp
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_ai_h2o_usage_v1_price_proto_init() }
+func file_ai_h2o_usage_v1_price_proto_init() {
+	if File_ai_h2o_usage_v1_price_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_price_proto_rawDesc), len(file_ai_h2o_usage_v1_price_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ai_h2o_usage_v1_price_proto_goTypes,
+		DependencyIndexes: file_ai_h2o_usage_v1_price_proto_depIdxs,
+		EnumInfos:         file_ai_h2o_usage_v1_price_proto_enumTypes,
+		MessageInfos:      file_ai_h2o_usage_v1_price_proto_msgTypes,
+	}.Build()
+	File_ai_h2o_usage_v1_price_proto = out.File
+	file_ai_h2o_usage_v1_price_proto_goTypes = nil
+	file_ai_h2o_usage_v1_price_proto_depIdxs = nil
+}
@@ -0,0 +1,671 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ai/h2o/usage/v1/price_catalog_service.proto
+
+package usagev1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Request message for CreatePrice.
+type CreatePriceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The action to create the price for.
+	// Format: `sources/{source}/actions/{action}`
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// The price to create.
+	Price *Price `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	// The ID to use for the price, which will become the final component of
+	// the price's resource name, e.g. `v2025-01`.
+	//
+	// Following AIP-122, the ID must be 1 to 63 characters long, consist of
+	// lowercase letters, digits and hyphens, start with a letter and not end
+	// with a hyphen.
+	PriceId       string `protobuf:"bytes,3,opt,name=price_id,json=priceId,proto3" json:"price_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePriceRequest) Reset() {
+	*x = CreatePriceRequest{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePriceRequest) ProtoMessage() {}
+
+func (x *CreatePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePriceRequest.ProtoReflect.Descriptor instead.
+func (*CreatePriceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreatePriceRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *CreatePriceRequest) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *CreatePriceRequest) GetPriceId() string {
+	if x != nil {
+		return x.PriceId
+	}
+	return ""
+}
+
+// Response message for CreatePrice.
+type CreatePriceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The created price.
+	Price         *Price `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePriceResponse) Reset() {
+	*x = CreatePriceResponse{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePriceResponse) ProtoMessage() {}
+
+func (x *CreatePriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePriceResponse.ProtoReflect.Descriptor instead.
+func (*CreatePriceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreatePriceResponse) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+// Request message for GetPrice.
+type GetPriceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the price to retrieve.
+	// Format: `sources/{source}/actions/{action}/prices/{price}`
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPriceRequest) Reset() {
+	*x = GetPriceRequest{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceRequest) ProtoMessage() {}
+
+func (x *GetPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceRequest.ProtoReflect.Descriptor instead.
+func (*GetPriceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetPriceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response message for GetPrice.
+type GetPriceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requested price.
+	Price         *Price `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPriceResponse) Reset() {
+	*x = GetPriceResponse{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPriceResponse) ProtoMessage() {}
+
+func (x *GetPriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPriceResponse.ProtoReflect.Descriptor instead.
+func (*GetPriceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPriceResponse) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+// Request message for ListPrices.
+type ListPricesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The action whose prices to list.
+	// Format: `sources/{source}/actions/{action}`
+	Parent string `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	// The maximum number of prices to return.
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// A page token, received from a previous `ListPrices` call.
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPricesRequest) Reset() {
+	*x = ListPricesRequest{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPricesRequest) ProtoMessage() {}
+
+func (x *ListPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPricesRequest.ProtoReflect.Descriptor instead.
+func (*ListPricesRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPricesRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *ListPricesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListPricesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Response message for ListPrices.
+type ListPricesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The list of prices.
+	Prices []*Price `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	// A token to retrieve the next page of results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPricesResponse) Reset() {
+	*x = ListPricesResponse{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPricesResponse) ProtoMessage() {}
+
+func (x *ListPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPricesResponse.ProtoReflect.Descriptor instead.
+func (*ListPricesResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPricesResponse) GetPrices() []*Price {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+func (x *ListPricesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Request message for UpdatePrice.
+type UpdatePriceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The price to update. Its `name` identifies the price; if its `etag` is
+	// set, it must match the current etag of the price.
+	Price *Price `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	// The fields to update, following AIP-134. If omitted, all populated
+	// mutable fields of `price` are updated; `*` replaces all mutable fields.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePriceRequest) Reset() {
+	*x = UpdatePriceRequest{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePriceRequest) ProtoMessage() {}
+
+func (x *UpdatePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePriceRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePriceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePriceRequest) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *UpdatePriceRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+// Response message for UpdatePrice.
+type UpdatePriceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The updated price.
+	Price         *Price `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePriceResponse) Reset() {
+	*x = UpdatePriceResponse{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePriceResponse) ProtoMessage() {}
+
+func (x *UpdatePriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePriceResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePriceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdatePriceResponse) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+// Request message for DeletePrice.
+type DeletePriceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the price to delete.
+	// Format: `sources/{source}/actions/{action}/prices/{price}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The current etag of the price. If set and the price has been modified
+	// since, the request fails with `ABORTED`.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePriceRequest) Reset() {
+	*x = DeletePriceRequest{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePriceRequest) ProtoMessage() {}
+
+func (x *DeletePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePriceRequest.ProtoReflect.Descriptor instead.
+func (*DeletePriceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeletePriceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeletePriceRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Response message for DeletePrice.
+type DeletePriceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePriceResponse) Reset() {
+	*x = DeletePriceResponse{}
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePriceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePriceResponse) ProtoMessage() {}
+
+func (x *DeletePriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePriceResponse.ProtoReflect.Descriptor instead.
+func (*DeletePriceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP(), []int{9}
+}
+
+var File_ai_h2o_usage_v1_price_catalog_service_proto protoreflect.FileDescriptor
+
+const file_ai_h2o_usage_v1_price_catalog_service_proto_rawDesc = "" +
+	"\n" +
+	"+ai/h2o/usage/v1/price_catalog_service.proto\x12\x0fai.h2o.usage.v1\x1a\x1bai/h2o/usage/v1/price.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a google/protobuf/field_mask.proto\"\x84\x01\n" +
+	"\x12CreatePriceRequest\x12\x1b\n" +
+	"\x06parent\x18\x01 \x01(\tB\x03\xe0A\x02R\x06parent\x121\n" +
+	"\x05price\x18\x02 \x01(\v2\x16.ai.h2o.usage.v1.PriceB\x03\xe0A\x02R\x05price\x12\x1e\n" +
+	"\bprice_id\x18\x03 \x01(\tB\x03\xe0A\x02R\apriceId\"C\n" +
+	"\x13CreatePriceResponse\x12,\n" +
+	"\x05price\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.PriceR\x05price\"A\n" +
+	"\x0fGetPriceRequest\x12.\n" +
+	"\x04name\x18\x01 \x01(\tB\x1a\xe0A\x02\xfaA\x14\n" +
+	"\x12usage.h2o.ai/PriceR\x04name\"@\n" +
+	"\x10GetPriceResponse\x12,\n" +
+	"\x05price\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.PriceR\x05price\"l\n" +
+	"\x11ListPricesRequest\x12\x1b\n" +
+	"\x06parent\x18\x01 \x01(\tB\x03\xe0A\x02R\x06parent\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"l\n" +
+	"\x12ListPricesResponse\x12.\n" +
+	"\x06prices\x18\x01 \x03(\v2\x16.ai.h2o.usage.v1.PriceR\x06prices\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x89\x01\n" +
+	"\x12UpdatePriceRequest\x121\n" +
+	"\x05price\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.PriceB\x03\xe0A\x02R\x05price\x12@\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskB\x03\xe0A\x01R\n" +
+	"updateMask\"C\n" +
+	"\x13UpdatePriceResponse\x12,\n" +
+	"\x05price\x18\x01 \x01(\v2\x16.ai.h2o.usage.v1.PriceR\x05price\"]\n" +
+	"\x12DeletePriceRequest\x12.\n" +
+	"\x04name\x18\x01 \x01(\tB\x1a\xe0A\x02\xfaA\x14\n" +
+	"\x12usage.h2o.ai/PriceR\x04name\x12\x17\n" +
+	"\x04etag\x18\x02 \x01(\tB\x03\xe0A\x01R\x04etag\"\x15\n" +
+	"\x13DeletePriceResponse2\xd9\x05\n" +
+	"\x13PriceCatalogService\x12\x90\x01\n" +
+	"\vCreatePrice\x12#.ai.h2o.usage.v1.CreatePriceRequest\x1a$.ai.h2o.usage.v1.CreatePriceResponse\"6\x82\xd3\xe4\x93\x020:\x05price\"'/v1/{parent=sources/*/actions/*}/prices\x12\x80\x01\n" +
+	"\bGetPrice\x12 .ai.h2o.usage.v1.GetPriceRequest\x1a!.ai.h2o.usage.v1.GetPriceResponse\"/\x82\xd3\xe4\x93\x02)\x12'/v1/{name=sources/*/actions/*/prices/*}\x12\x86\x01\n" +
+	"\n" +
+	"ListPrices\x12\".ai.h2o.usage.v1.ListPricesRequest\x1a#.ai.h2o.usage.v1.ListPricesResponse\"/\x82\xd3\xe4\x93\x02)\x12'/v1/{parent=sources/*/actions/*}/prices\x12\x96\x01\n" +
+	"\vUpdatePrice\x12#.ai.h2o.usage.v1.UpdatePriceRequest\x1a$.ai.h2o.usage.v1.UpdatePriceResponse\"<\x82\xd3\xe4\x93\x026:\x05price2-/v1/{price.name=sources/*/actions/*/prices/*}\x12\x89\x01\n" +
+	"\vDeletePrice\x12#.ai.h2o.usage.v1.DeletePriceRequest\x1a$.ai.h2o.usage.v1.DeletePriceResponse\"/\x82\xd3\xe4\x93\x02)*'/v1/{name=sources/*/actions/*/prices/*}B\xcd\x01\n" +
+	"\x13com.ai.h2o.usage.v1B\x18PriceCatalogServiceProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
+
+var (
+	file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescOnce sync.Once
+	file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescData []byte
+)
+
+func file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescGZIP() []byte {
+	file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescOnce.Do(func() {
+		file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_price_catalog_service_proto_rawDesc), len(file_ai_h2o_usage_v1_price_catalog_service_proto_rawDesc)))
+	})
+	return file_ai_h2o_usage_v1_price_catalog_service_proto_rawDescData
+}
+
+var file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_ai_h2o_usage_v1_price_catalog_service_proto_goTypes = []any{
+	(*CreatePriceRequest)(nil),    // 0: ai.h2o.usage.v1.CreatePriceRequest
+	(*CreatePriceResponse)(nil),   // 1: ai.h2o.usage.v1.CreatePriceResponse
+	(*GetPriceRequest)(nil),       // 2: ai.h2o.usage.v1.GetPriceRequest
+	(*GetPriceResponse)(nil),      // 3: ai.h2o.usage.v1.GetPriceResponse
+	(*ListPricesRequest)(nil),     // 4: ai.h2o.usage.v1.ListPricesRequest
+	(*ListPricesResponse)(nil),    // 5: ai.h2o.usage.v1.ListPricesResponse
+	(*UpdatePriceRequest)(nil),    // 6: ai.h2o.usage.v1.UpdatePriceRequest
+	(*UpdatePriceResponse)(nil),   // 7: ai.h2o.usage.v1.UpdatePriceResponse
+	(*DeletePriceRequest)(nil),    // 8: ai.h2o.usage.v1.DeletePriceRequest
+	(*DeletePriceResponse)(nil),   // 9: ai.h2o.usage.v1.DeletePriceResponse
+	(*Price)(nil),                 // 10: ai.h2o.usage.v1.Price
+	(*fieldmaskpb.FieldMask)(nil), // 11: google.protobuf.FieldMask
+}
+var file_ai_h2o_usage_v1_price_catalog_service_proto_depIdxs = []int32{
+	10, // 0: ai.h2o.usage.v1.CreatePriceRequest.price:type_name -> ai.h2o.usage.v1.Price
+	10, // 1: ai.h2o.usage.v1.CreatePriceResponse.price:type_name -> ai.h2o.usage.v1.Price
+	10, // 2: ai.h2o.usage.v1.GetPriceResponse.price:type_name -> ai.h2o.usage.v1.Price
+	10, // 3: ai.h2o.usage.v1.ListPricesResponse.prices:type_name -> ai.h2o.usage.v1.Price
+	10, // 4: ai.h2o.usage.v1.UpdatePriceRequest.price:type_name -> ai.h2o.usage.v1.Price
+	11, // 5: ai.h2o.usage.v1.UpdatePriceRequest.update_mask:type_name -> google.protobuf.FieldMask
+	10, // 6: ai.h2o.usage.v1.UpdatePriceResponse.price:type_name -> ai.h2o.usage.v1.Price
+	0,  // 7: ai.h2o.usage.v1.PriceCatalogService.CreatePrice:input_type -> ai.h2o.usage.v1.CreatePriceRequest
+	2,  // 8: ai.h2o.usage.v1.PriceCatalogService.GetPrice:input_type -> ai.h2o.usage.v1.GetPriceRequest
+	4,  // 9: ai.h2o.usage.v1.PriceCatalogService.ListPrices:input_type -> ai.h2o.usage.v1.ListPricesRequest
+	6,  // 10: ai.h2o.usage.v1.PriceCatalogService.UpdatePrice:input_type -> ai.h2o.usage.v1.UpdatePriceRequest
+	8,  // 11: ai.h2o.usage.v1.PriceCatalogService.DeletePrice:input_type -> ai.h2o.usage.v1.DeletePriceRequest
+	1,  // 12: ai.h2o.usage.v1.PriceCatalogService.CreatePrice:output_type -> ai.h2o.usage.v1.CreatePriceResponse
+	3,  // 13: ai.h2o.usage.v1.PriceCatalogService.GetPrice:output_type -> ai.h2o.usage.v1.GetPriceResponse
+	5,  // 14: ai.h2o.usage.v1.PriceCatalogService.ListPrices:output_type -> ai.h2o.usage.v1.ListPricesResponse
+	7,  // 15: ai.h2o.usage.v1.PriceCatalogService.UpdatePrice:output_type -> ai.h2o.usage.v1.UpdatePriceResponse
+	9,  // 16: ai.h2o.usage.v1.PriceCatalogService.DeletePrice:output_type -> ai.h2o.usage.v1.DeletePriceResponse
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_ai_h2o_usage_v1_price_catalog_service_proto_init() }
+func file_ai_h2o_usage_v1_price_catalog_service_proto_init() {
+	if File_ai_h2o_usage_v1_price_catalog_service_proto != nil {
+		return
+	}
+	file_ai_h2o_usage_v1_price_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_price_catalog_service_proto_rawDesc), len(file_ai_h2o_usage_v1_price_catalog_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ai_h2o_usage_v1_price_catalog_service_proto_goTypes,
+		DependencyIndexes: file_ai_h2o_usage_v1_price_catalog_service_proto_depIdxs,
+		MessageInfos:      file_ai_h2o_usage_v1_price_catalog_service_proto_msgTypes,
+	}.Build()
+	File_ai_h2o_usage_v1_price_catalog_service_proto = out.File
+	file_ai_h2o_usage_v1_price_catalog_service_proto_goTypes = nil
+	file_ai_h2o_usage_v1_price_catalog_service_proto_depIdxs = nil
+}
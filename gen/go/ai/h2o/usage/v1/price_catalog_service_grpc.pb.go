@@ -0,0 +1,291 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             (unknown)
+// source: ai/h2o/usage/v1/price_catalog_service.proto
+
+package usagev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PriceCatalogService_CreatePrice_FullMethodName = "/ai.h2o.usage.v1.PriceCatalogService/CreatePrice"
+	PriceCatalogService_GetPrice_FullMethodName    = "/ai.h2o.usage.v1.PriceCatalogService/GetPrice"
+	PriceCatalogService_ListPrices_FullMethodName  = "/ai.h2o.usage.v1.PriceCatalogService/ListPrices"
+	PriceCatalogService_UpdatePrice_FullMethodName = "/ai.h2o.usage.v1.PriceCatalogService/UpdatePrice"
+	PriceCatalogService_DeletePrice_FullMethodName = "/ai.h2o.usage.v1.PriceCatalogService/DeletePrice"
+)
+
+// PriceCatalogServiceClient is the client API for PriceCatalogService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Service for managing the prices of the actions of sources.
+type PriceCatalogServiceClient interface {
+	// Creates a price. Its `effective_time` must not overlap with the other
+	// prices of the action, and the action must be registered.
+	CreatePrice(ctx context.Context, in *CreatePriceRequest, opts ...grpc.CallOption) (*CreatePriceResponse, error)
+	// Gets a price.
+	GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*GetPriceResponse, error)
+	// Lists the prices of an action, ordered by name.
+	ListPrices(ctx context.Context, in *ListPricesRequest, opts ...grpc.CallOption) (*ListPricesResponse, error)
+	// Updates a price. Only its `effective_time` can be changed, e.g. to end
+	// it before a new price takes effect.
+	UpdatePrice(ctx context.Context, in *UpdatePriceRequest, opts ...grpc.CallOption) (*UpdatePriceResponse, error)
+	// Deletes a price. Events already priced with it keep their cost.
+	DeletePrice(ctx context.Context, in *DeletePriceRequest, opts ...grpc.CallOption) (*DeletePriceResponse, error)
+}
+
+type priceCatalogServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPriceCatalogServiceClient(cc grpc.ClientConnInterface) PriceCatalogServiceClient {
+	return &priceCatalogServiceClient{cc}
+}
+
+func (c *priceCatalogServiceClient) CreatePrice(ctx context.Context, in *CreatePriceRequest, opts ...grpc.CallOption) (*CreatePriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreatePriceResponse)
+	err := c.cc.Invoke(ctx, PriceCatalogService_CreatePrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceCatalogServiceClient) GetPrice(ctx context.Context, in *GetPriceRequest, opts ...grpc.CallOption) (*GetPriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPriceResponse)
+	err := c.cc.Invoke(ctx, PriceCatalogService_GetPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceCatalogServiceClient) ListPrices(ctx context.Context, in *ListPricesRequest, opts ...grpc.CallOption) (*ListPricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPricesResponse)
+	err := c.cc.Invoke(ctx, PriceCatalogService_ListPrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceCatalogServiceClient) UpdatePrice(ctx context.Context, in *UpdatePriceRequest, opts ...grpc.CallOption) (*UpdatePriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdatePriceResponse)
+	err := c.cc.Invoke(ctx, PriceCatalogService_UpdatePrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *priceCatalogServiceClient) DeletePrice(ctx context.Context, in *DeletePriceRequest, opts ...grpc.CallOption) (*DeletePriceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePriceResponse)
+	err := c.cc.Invoke(ctx, PriceCatalogService_DeletePrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PriceCatalogServiceServer is the server API for PriceCatalogService service.
+// All implementations must embed UnimplementedPriceCatalogServiceServer
+// for forward compatibility.
+//
+// Service for managing the prices of the actions of sources.
+type PriceCatalogServiceServer interface {
+	// Creates a price. Its `effective_time` must not overlap with the other
+	// prices of the action, and the action must be registered.
+	CreatePrice(context.Context, *CreatePriceRequest) (*CreatePriceResponse, error)
+	// Gets a price.
+	GetPrice(context.Context, *GetPriceRequest) (*GetPriceResponse, error)
+	// Lists the prices of an action, ordered by name.
+	ListPrices(context.Context, *ListPricesRequest) (*ListPricesResponse, error)
+	// Updates a price. Only its `effective_time` can be changed, e.g. to end
+	// it before a new price takes effect.
+	UpdatePrice(context.Context, *UpdatePriceRequest) (*UpdatePriceResponse, error)
+	// Deletes a price. Events already priced with it keep their cost.
+	DeletePrice(context.Context, *DeletePriceRequest) (*DeletePriceResponse, error)
+	mustEmbedUnimplementedPriceCatalogServiceServer()
+}
+
+// UnimplementedPriceCatalogServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPriceCatalogServiceServer struct{}
+
+func (UnimplementedPriceCatalogServiceServer) CreatePrice(context.Context, *CreatePriceRequest) (*CreatePriceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreatePrice not implemented")
+}
+func (UnimplementedPriceCatalogServiceServer) GetPrice(context.Context, *GetPriceRequest) (*GetPriceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPrice not implemented")
+}
+func (UnimplementedPriceCatalogServiceServer) ListPrices(context.Context, *ListPricesRequest) (*ListPricesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPrices not implemented")
+}
+func (UnimplementedPriceCatalogServiceServer) UpdatePrice(context.Context, *UpdatePriceRequest) (*UpdatePriceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdatePrice not implemented")
+}
+func (UnimplementedPriceCatalogServiceServer) DeletePrice(context.Context, *DeletePriceRequest) (*DeletePriceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeletePrice not implemented")
+}
+func (UnimplementedPriceCatalogServiceServer) mustEmbedUnimplementedPriceCatalogServiceServer() {}
+func (UnimplementedPriceCatalogServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafePriceCatalogServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PriceCatalogServiceServer will
+// result in compilation errors.
+type UnsafePriceCatalogServiceServer interface {
+	mustEmbedUnimplementedPriceCatalogServiceServer()
+}
+
+func RegisterPriceCatalogServiceServer(s grpc.ServiceRegistrar, srv PriceCatalogServiceServer) {
+	// If the following call panics, it indicates UnimplementedPriceCatalogServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PriceCatalogService_ServiceDesc, srv)
+}
+
+func _PriceCatalogService_CreatePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceCatalogServiceServer).CreatePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceCatalogService_CreatePrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceCatalogServiceServer).CreatePrice(ctx, req.(*CreatePriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceCatalogService_GetPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceCatalogServiceServer).GetPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceCatalogService_GetPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceCatalogServiceServer).GetPrice(ctx, req.(*GetPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceCatalogService_ListPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceCatalogServiceServer).ListPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceCatalogService_ListPrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceCatalogServiceServer).ListPrices(ctx, req.(*ListPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceCatalogService_UpdatePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceCatalogServiceServer).UpdatePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceCatalogService_UpdatePrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceCatalogServiceServer).UpdatePrice(ctx, req.(*UpdatePriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PriceCatalogService_DeletePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PriceCatalogServiceServer).DeletePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PriceCatalogService_DeletePrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PriceCatalogServiceServer).DeletePrice(ctx, req.(*DeletePriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PriceCatalogService_ServiceDesc is the grpc.ServiceDesc for PriceCatalogService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PriceCatalogService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ai.h2o.usage.v1.PriceCatalogService",
+	HandlerType: (*PriceCatalogServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePrice",
+			Handler:    _PriceCatalogService_CreatePrice_Handler,
+		},
+		{
+			MethodName: "GetPrice",
+			Handler:    _PriceCatalogService_GetPrice_Handler,
+		},
+		{
+			MethodName: "ListPrices",
+			Handler:    _PriceCatalogService_ListPrices_Handler,
+		},
+		{
+			MethodName: "UpdatePrice",
+			Handler:    _PriceCatalogService_UpdatePrice_Handler,
+		},
+		{
+			MethodName: "DeletePrice",
+			Handler:    _PriceCatalogService_DeletePrice_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ai/h2o/usage/v1/price_catalog_service.proto",
+}
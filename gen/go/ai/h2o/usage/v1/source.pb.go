@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ai/h2o/usage/v1/source.proto
+
+package usagev1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A product or tool that records usage events, with the actions it may
+// record. Events are only accepted for registered sources and actions.
+type Source struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The resource name of the source. Its ID is the `source` of the events
+	// recorded by it.
+	// Format: `sources/{source}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// A human-readable name of the source.
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// The actions that events of this source may record (e.g., "classify").
+	// Each action follows the same rules as resource IDs: 1 to 63 characters
+	// of lowercase letters, digits and hyphens, starting with a letter and not
+	// ending with a hyphen.
+	Actions []string `protobuf:"bytes,3,rep,name=actions,proto3" json:"actions,omitempty"`
+	// The time when the source was registered.
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	// The time when the source was last updated.
+	UpdateTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// A checksum of the source's current state, following AIP-154.
+	Etag          string `protobuf:"bytes,6,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Source) Reset() {
+	*x = Source{}
+	mi := &file_ai_h2o_usage_v1_source_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source) ProtoMessage() {}
+
+func (x *Source) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source.ProtoReflect.Descriptor instead.
+func (*Source) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Source) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Source) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *Source) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *Source) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Source) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Source) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+var File_ai_h2o_usage_v1_source_proto protoreflect.FileDescriptor
+
+const file_ai_h2o_usage_v1_source_proto_rawDesc = "" +
+	"\n" +
+	"\x1cai/h2o/usage/v1/source.proto\x12\x0fai.h2o.usage.v1\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc2\x02\n" +
+	"\x06Source\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tB\x03\xe0A\bR\x04name\x12&\n" +
+	"\fdisplay_name\x18\x02 \x01(\tB\x03\xe0A\x01R\vdisplayName\x12\x1d\n" +
+	"\aactions\x18\x03 \x03(\tB\x03\xe0A\x02R\aactions\x12@\n" +
+	"\vcreate_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"createTime\x12@\n" +
+	"\vupdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampB\x03\xe0A\x03R\n" +
+	"updateTime\x12\x17\n" +
+	"\x04etag\x18\x06 \x01(\tB\x03\xe0A\x01R\x04etag:;\xeaA8\n" +
+	"\x13usage.h2o.ai/Source\x12\x10sources/{source}*\asources2\x06sourceB\xc0\x01\n" +
+	"\x13com.ai.h2o.usage.v1B\vSourceProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
+
+var (
+	file_ai_h2o_usage_v1_source_proto_rawDescOnce sync.Once
+	file_ai_h2o_usage_v1_source_proto_rawDescData []byte
+)
+
+func file_ai_h2o_usage_v1_source_proto_rawDescGZIP() []byte {
+	file_ai_h2o_usage_v1_source_proto_rawDescOnce.Do(func() {
+		file_ai_h2o_usage_v1_source_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_source_proto_rawDesc), len(file_ai_h2o_usage_v1_source_proto_rawDesc)))
+	})
+	return file_ai_h2o_usage_v1_source_proto_rawDescData
+}
+
+var file_ai_h2o_usage_v1_source_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ai_h2o_usage_v1_source_proto_goTypes = []any{
+	(*Source)(nil),                // 0: ai.h2o.usage.v1.Source
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_ai_h2o_usage_v1_source_proto_depIdxs = []int32{
+	1, // 0: ai.h2o.usage.v1.Source.create_time:type_name -> google.protobuf.Timestamp
+	1, // 1: ai.h2o.usage.v1.Source.update_time:type_name -> google.protobuf.Timestamp
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_ai_h2o_usage_v1_source_proto_init() }
+func file_ai_h2o_usage_v1_source_proto_init() {
+	if File_ai_h2o_usage_v1_source_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_source_proto_rawDesc), len(file_ai_h2o_usage_v1_source_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ai_h2o_usage_v1_source_proto_goTypes,
+		DependencyIndexes: file_ai_h2o_usage_v1_source_proto_depIdxs,
+		MessageInfos:      file_ai_h2o_usage_v1_source_proto_msgTypes,
+	}.Build()
+	File_ai_h2o_usage_v1_source_proto = out.File
+	file_ai_h2o_usage_v1_source_proto_goTypes = nil
+	file_ai_h2o_usage_v1_source_proto_depIdxs = nil
+}
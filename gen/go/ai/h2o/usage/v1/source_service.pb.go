@@ -0,0 +1,648 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ai/h2o/usage/v1/source_service.proto
+
+package usagev1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Request message for CreateSource.
+type CreateSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The source to register.
+	Source *Source `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// The ID to use for the source, which will become the final component of
+	// the source's resource name and the `source` of its events.
+	//
+	// Following AIP-122, the ID must be 1 to 63 characters long, consist of
+	// lowercase letters, digits and hyphens, start with a letter and not end
+	// with a hyphen.
+	SourceId      string `protobuf:"bytes,2,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSourceRequest) Reset() {
+	*x = CreateSourceRequest{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSourceRequest) ProtoMessage() {}
+
+func (x *CreateSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSourceRequest.ProtoReflect.Descriptor instead.
+func (*CreateSourceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateSourceRequest) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *CreateSourceRequest) GetSourceId() string {
+	if x != nil {
+		return x.SourceId
+	}
+	return ""
+}
+
+// Response message for CreateSource.
+type CreateSourceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The registered source.
+	Source        *Source `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSourceResponse) Reset() {
+	*x = CreateSourceResponse{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSourceResponse) ProtoMessage() {}
+
+func (x *CreateSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSourceResponse.ProtoReflect.Descriptor instead.
+func (*CreateSourceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateSourceResponse) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+// Request message for GetSource.
+type GetSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the source to retrieve.
+	// Format: `sources/{source}`
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSourceRequest) Reset() {
+	*x = GetSourceRequest{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSourceRequest) ProtoMessage() {}
+
+func (x *GetSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSourceRequest.ProtoReflect.Descriptor instead.
+func (*GetSourceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSourceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response message for GetSource.
+type GetSourceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requested source.
+	Source        *Source `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSourceResponse) Reset() {
+	*x = GetSourceResponse{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSourceResponse) ProtoMessage() {}
+
+func (x *GetSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSourceResponse.ProtoReflect.Descriptor instead.
+func (*GetSourceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSourceResponse) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+// Request message for ListSources.
+type ListSourcesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The maximum number of sources to return.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// A page token, received from a previous `ListSources` call.
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSourcesRequest) Reset() {
+	*x = ListSourcesRequest{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSourcesRequest) ProtoMessage() {}
+
+func (x *ListSourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSourcesRequest.ProtoReflect.Descriptor instead.
+func (*ListSourcesRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListSourcesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSourcesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Response message for ListSources.
+type ListSourcesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The list of sources.
+	Sources []*Source `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	// A token to retrieve the next page of results.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSourcesResponse) Reset() {
+	*x = ListSourcesResponse{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSourcesResponse) ProtoMessage() {}
+
+func (x *ListSourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSourcesResponse.ProtoReflect.Descriptor instead.
+func (*ListSourcesResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSourcesResponse) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ListSourcesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Request message for UpdateSource.
+type UpdateSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The source to update. Its `name` identifies the source; if its `etag`
+	// is set, it must match the current etag of the source.
+	Source *Source `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// The fields to update, following AIP-134. If omitted, all populated
+	// fields of `source` are updated; `*` replaces all mutable fields.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSourceRequest) Reset() {
+	*x = UpdateSourceRequest{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSourceRequest) ProtoMessage() {}
+
+func (x *UpdateSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSourceRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSourceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateSourceRequest) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *UpdateSourceRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+// Response message for UpdateSource.
+type UpdateSourceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The updated source.
+	Source        *Source `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSourceResponse) Reset() {
+	*x = UpdateSourceResponse{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSourceResponse) ProtoMessage() {}
+
+func (x *UpdateSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSourceResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSourceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateSourceResponse) GetSource() *Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+// Request message for DeleteSource.
+type DeleteSourceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the source to delete.
+	// Format: `sources/{source}`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The current etag of the source. If set and the source has been
+	// modified since, the request fails with `ABORTED`.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSourceRequest) Reset() {
+	*x = DeleteSourceRequest{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSourceRequest) ProtoMessage() {}
+
+func (x *DeleteSourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSourceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSourceRequest) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteSourceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeleteSourceRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Response message for DeleteSource.
+type DeleteSourceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSourceResponse) Reset() {
+	*x = DeleteSourceResponse{}
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSourceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSourceResponse) ProtoMessage() {}
+
+func (x *DeleteSourceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ai_h2o_usage_v1_source_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSourceResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSourceResponse) Descriptor() ([]byte, []int) {
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP(), []int{9}
+}
+
+var File_ai_h2o_usage_v1_source_service_proto protoreflect.FileDescriptor
+
+const file_ai_h2o_usage_v1_source_service_proto_rawDesc = "" +
+	"\n" +
+	"$ai/h2o/usage/v1/source_service.proto\x12\x0fai.h2o.usage.v1\x1a\x1cai/h2o/usage/v1/source.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/api/field_behavior.proto\x1a\x19google/api/resource.proto\x1a google/protobuf/field_mask.proto\"m\n" +
+	"\x13CreateSourceRequest\x124\n" +
+	"\x06source\x18\x01 \x01(\v2\x17.ai.h2o.usage.v1.SourceB\x03\xe0A\x02R\x06source\x12 \n" +
+	"\tsource_id\x18\x02 \x01(\tB\x03\xe0A\x02R\bsourceId\"G\n" +
+	"\x14CreateSourceResponse\x12/\n" +
+	"\x06source\x18\x01 \x01(\v2\x17.ai.h2o.usage.v1.SourceR\x06source\"C\n" +
+	"\x10GetSourceRequest\x12/\n" +
+	"\x04name\x18\x01 \x01(\tB\x1b\xe0A\x02\xfaA\x15\n" +
+	"\x13usage.h2o.ai/SourceR\x04name\"D\n" +
+	"\x11GetSourceResponse\x12/\n" +
+	"\x06source\x18\x01 \x01(\v2\x17.ai.h2o.usage.v1.SourceR\x06source\"P\n" +
+	"\x12ListSourcesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"p\n" +
+	"\x13ListSourcesResponse\x121\n" +
+	"\asources\x18\x01 \x03(\v2\x17.ai.h2o.usage.v1.SourceR\asources\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x8d\x01\n" +
+	"\x13UpdateSourceRequest\x124\n" +
+	"\x06source\x18\x01 \x01(\v2\x17.ai.h2o.usage.v1.SourceB\x03\xe0A\x02R\x06source\x12@\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskB\x03\xe0A\x01R\n" +
+	"updateMask\"G\n" +
+	"\x14UpdateSourceResponse\x12/\n" +
+	"\x06source\x18\x01 \x01(\v2\x17.ai.h2o.usage.v1.SourceR\x06source\"_\n" +
+	"\x13DeleteSourceRequest\x12/\n" +
+	"\x04name\x18\x01 \x01(\tB\x1b\xe0A\x02\xfaA\x15\n" +
+	"\x13usage.h2o.ai/SourceR\x04name\x12\x17\n" +
+	"\x04etag\x18\x02 \x01(\tB\x03\xe0A\x01R\x04etag\"\x16\n" +
+	"\x14DeleteSourceResponse2\xf0\x04\n" +
+	"\rSourceService\x12x\n" +
+	"\fCreateSource\x12$.ai.h2o.usage.v1.CreateSourceRequest\x1a%.ai.h2o.usage.v1.CreateSourceResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x06source\"\v/v1/sources\x12p\n" +
+	"\tGetSource\x12!.ai.h2o.usage.v1.GetSourceRequest\x1a\".ai.h2o.usage.v1.GetSourceResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/{name=sources/*}\x12m\n" +
+	"\vListSources\x12#.ai.h2o.usage.v1.ListSourcesRequest\x1a$.ai.h2o.usage.v1.ListSourcesResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\v/v1/sources\x12\x88\x01\n" +
+	"\fUpdateSource\x12$.ai.h2o.usage.v1.UpdateSourceRequest\x1a%.ai.h2o.usage.v1.UpdateSourceResponse\"+\x82\xd3\xe4\x93\x02%:\x06source2\x1b/v1/{source.name=sources/*}\x12y\n" +
+	"\fDeleteSource\x12$.ai.h2o.usage.v1.DeleteSourceRequest\x1a%.ai.h2o.usage.v1.DeleteSourceResponse\"\x1c\x82\xd3\xe4\x93\x02\x16*\x14/v1/{name=sources/*}B\xc7\x01\n" +
+	"\x13com.ai.h2o.usage.v1B\x12SourceServiceProtoP\x01Z=github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1;usagev1\xa2\x02\x03AHU\xaa\x02\x0fAi.H2o.Usage.V1\xca\x02\x0fAi\\H2o\\Usage\\V1\xe2\x02\x1bAi\\H2o\\Usage\\V1\\GPBMetadata\xea\x02\x12Ai::H2o::Usage::V1b\x06proto3"
+
+var (
+	file_ai_h2o_usage_v1_source_service_proto_rawDescOnce sync.Once
+	file_ai_h2o_usage_v1_source_service_proto_rawDescData []byte
+)
+
+func file_ai_h2o_usage_v1_source_service_proto_rawDescGZIP() []byte {
+	file_ai_h2o_usage_v1_source_service_proto_rawDescOnce.Do(func() {
+		file_ai_h2o_usage_v1_source_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_source_service_proto_rawDesc), len(file_ai_h2o_usage_v1_source_service_proto_rawDesc)))
+	})
+	return file_ai_h2o_usage_v1_source_service_proto_rawDescData
+}
+
+var file_ai_h2o_usage_v1_source_service_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_ai_h2o_usage_v1_source_service_proto_goTypes = []any{
+	(*CreateSourceRequest)(nil),   // 0: ai.h2o.usage.v1.CreateSourceRequest
+	(*CreateSourceResponse)(nil),  // 1: ai.h2o.usage.v1.CreateSourceResponse
+	(*GetSourceRequest)(nil),      // 2: ai.h2o.usage.v1.GetSourceRequest
+	(*GetSourceResponse)(nil),     // 3: ai.h2o.usage.v1.GetSourceResponse
+	(*ListSourcesRequest)(nil),    // 4: ai.h2o.usage.v1.ListSourcesRequest
+	(*ListSourcesResponse)(nil),   // 5: ai.h2o.usage.v1.ListSourcesResponse
+	(*UpdateSourceRequest)(nil),   // 6: ai.h2o.usage.v1.UpdateSourceRequest
+	(*UpdateSourceResponse)(nil),  // 7: ai.h2o.usage.v1.UpdateSourceResponse
+	(*DeleteSourceRequest)(nil),   // 8: ai.h2o.usage.v1.DeleteSourceRequest
+	(*DeleteSourceResponse)(nil),  // 9: ai.h2o.usage.v1.DeleteSourceResponse
+	(*Source)(nil),                // 10: ai.h2o.usage.v1.Source
+	(*fieldmaskpb.FieldMask)(nil), // 11: google.protobuf.FieldMask
+}
+var file_ai_h2o_usage_v1_source_service_proto_depIdxs = []int32{
+	10, // 0: ai.h2o.usage.v1.CreateSourceRequest.source:type_name -> ai.h2o.usage.v1.Source
+	10, // 1: ai.h2o.usage.v1.CreateSourceResponse.source:type_name -> ai.h2o.usage.v1.Source
+	10, // 2: ai.h2o.usage.v1.GetSourceResponse.source:type_name -> ai.h2o.usage.v1.Source
+	10, // 3: ai.h2o.usage.v1.ListSourcesResponse.sources:type_name -> ai.h2o.usage.v1.Source
+	10, // 4: ai.h2o.usage.v1.UpdateSourceRequest.source:type_name -> ai.h2o.usage.v1.Source
+	11, // 5: ai.h2o.usage.v1.UpdateSourceRequest.update_mask:type_name -> google.protobuf.FieldMask
+	10, // 6: ai.h2o.usage.v1.UpdateSourceResponse.source:type_name -> ai.h2o.usage.v1.Source
+	0,  // 7: ai.h2o.usage.v1.SourceService.CreateSource:input_type -> ai.h2o.usage.v1.CreateSourceRequest
+	2,  // 8: ai.h2o.usage.v1.SourceService.GetSource:input_type -> ai.h2o.usage.v1.GetSourceRequest
+	4,  // 9: ai.h2o.usage.v1.SourceService.ListSources:input_type -> ai.h2o.usage.v1.ListSourcesRequest
+	6,  // 10: ai.h2o.usage.v1.SourceService.UpdateSource:input_type -> ai.h2o.usage.v1.UpdateSourceRequest
+	8,  // 11: ai.h2o.usage.v1.SourceService.DeleteSource:input_type -> ai.h2o.usage.v1.DeleteSourceRequest
+	1,  // 12: ai.h2o.usage.v1.SourceService.CreateSource:output_type -> ai.h2o.usage.v1.CreateSourceResponse
+	3,  // 13: ai.h2o.usage.v1.SourceService.GetSource:output_type -> ai.h2o.usage.v1.GetSourceResponse
+	5,  // 14: ai.h2o.usage.v1.SourceService.ListSources:output_type -> ai.h2o.usage.v1.ListSourcesResponse
+	7,  // 15: ai.h2o.usage.v1.SourceService.UpdateSource:output_type -> ai.h2o.usage.v1.UpdateSourceResponse
+	9,  // 16: ai.h2o.usage.v1.SourceService.DeleteSource:output_type -> ai.h2o.usage.v1.DeleteSourceResponse
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_ai_h2o_usage_v1_source_service_proto_init() }
+func file_ai_h2o_usage_v1_source_service_proto_init() {
+	if File_ai_h2o_usage_v1_source_service_proto != nil {
+		return
+	}
+	file_ai_h2o_usage_v1_source_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ai_h2o_usage_v1_source_service_proto_rawDesc), len(file_ai_h2o_usage_v1_source_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ai_h2o_usage_v1_source_service_proto_goTypes,
+		DependencyIndexes: file_ai_h2o_usage_v1_source_service_proto_depIdxs,
+		MessageInfos:      file_ai_h2o_usage_v1_source_service_proto_msgTypes,
+	}.Build()
+	File_ai_h2o_usage_v1_source_service_proto = out.File
+	file_ai_h2o_usage_v1_source_service_proto_goTypes = nil
+	file_ai_h2o_usage_v1_source_service_proto_depIdxs = nil
+}
@@ -0,0 +1,132 @@
+// Package apierror builds gRPC status errors carrying the error details
+// recommended by AIP-193, so that clients can handle errors by their stable
+// reason instead of parsing messages.
+package apierror
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain is the ErrorInfo domain of errors returned by the usage API.
+const Domain = "usage.h2o.ai"
+
+// FieldViolation describes a problem with a field of a request.
+type FieldViolation struct {
+	// Field is the path of the field in the request, e.g. "event.subject".
+	Field string
+	// Reason is a stable UPPER_SNAKE_CASE identifier of the problem.
+	Reason string
+	// Description is a human-readable explanation.
+	Description string
+}
+
+// New returns an error with the given code and message, with an ErrorInfo
+// detail carrying reason and metadata.
+func New(code codes.Code, reason, msg string, metadata map[string]string) error {
+	return withDetails(status.New(code, msg), &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+}
+
+// BadRequest returns an INVALID_ARGUMENT error reporting all violations in
+// a BadRequest detail. Its ErrorInfo carries the reason of the first
+// violation.
+func BadRequest(violations ...FieldViolation) error {
+	descriptions := make([]string, len(violations))
+	badRequest := &errdetails.BadRequest{}
+	for i, v := range violations {
+		descriptions[i] = v.Description
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+			Reason:      v.Reason,
+		})
+	}
+	info := &errdetails.ErrorInfo{Domain: Domain}
+	if len(violations) > 0 {
+		info.Reason = violations[0].Reason
+		info.Metadata = map[string]string{"field": violations[0].Field}
+	}
+	return withDetails(status.New(codes.InvalidArgument, strings.Join(descriptions, "; ")), info, badRequest)
+}
+
+// QuotaViolation describes an exhausted quota.
+type QuotaViolation struct {
+	// Subject is who exhausted the quota, e.g. "users/alice".
+	Subject string
+	// Description is a human-readable explanation.
+	Description string
+	// QuotaID identifies the quota.
+	QuotaID string
+	// QuotaMetric is what the quota limits, e.g. "events".
+	QuotaMetric string
+	// QuotaDimensions are the dimensions the quota applies to, e.g. the
+	// source of the events.
+	QuotaDimensions map[string]string
+	// QuotaValue is the limit of the quota.
+	QuotaValue int64
+}
+
+// ResourceExhausted returns a RESOURCE_EXHAUSTED error reporting all
+// violations in a QuotaFailure detail, with an ErrorInfo detail carrying
+// reason and metadata.
+func ResourceExhausted(reason, msg string, metadata map[string]string, violations ...QuotaViolation) error {
+	quotaFailure := &errdetails.QuotaFailure{}
+	for _, v := range violations {
+		quotaFailure.Violations = append(quotaFailure.Violations, &errdetails.QuotaFailure_Violation{
+			Subject:         v.Subject,
+			Description:     v.Description,
+			QuotaId:         v.QuotaID,
+			QuotaMetric:     v.QuotaMetric,
+			QuotaDimensions: v.QuotaDimensions,
+			QuotaValue:      v.QuotaValue,
+		})
+	}
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata}
+	return withDetails(status.New(codes.ResourceExhausted, msg), info, quotaFailure)
+}
+
+// Prefix returns err with its message prefixed by path, and the field paths
+// of its BadRequest details made relative to the field path, e.g. for
+// reporting the error of an element of a batch request.
+func Prefix(err error, path string) error {
+	st := status.Convert(err)
+	pb := st.Proto()
+	pb.Message = path + ": " + pb.Message
+
+	var details []protoadapt.MessageV1
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				v.Field = path + "." + v.Field
+			}
+			details = append(details, d)
+		case *errdetails.ErrorInfo:
+			if field, ok := d.GetMetadata()["field"]; ok {
+				d.Metadata["field"] = path + "." + field
+			}
+			details = append(details, d)
+		case protoadapt.MessageV1:
+			details = append(details, d)
+		}
+	}
+	pb.Details = nil
+	return withDetails(status.FromProto(pb), details...)
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		// Only happens if a detail cannot be marshaled
+		return st.Err()
+	}
+	return withDetails.Err()
+}
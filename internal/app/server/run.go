@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
@@ -12,7 +15,10 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
 	"github.com/jan-sykora/api-demo/internal/usage"
+	"github.com/jan-sykora/api-demo/internal/usage/postgres"
+	"github.com/jan-sykora/api-demo/internal/usage/sqlite"
 )
 
 const (
@@ -20,13 +26,106 @@ const (
 	httpAddr = ":8080"
 )
 
+// Supported values of Config.Store.
+const (
+	StoreMemory   = "memory"
+	StoreSQLite   = "sqlite"
+	StorePostgres = "postgres"
+)
+
+// Config configures the server.
+type Config struct {
+	// Store selects the event storage backend.
+	Store string
+	// SQLitePath is the database file used by the SQLite store.
+	SQLitePath string
+	// PostgresDSN is the connection string used by the PostgreSQL store.
+	PostgresDSN string
+	// PageTokenKey is the secret that signs ListEvents page tokens. It must
+	// be shared by all servers behind a load balancer.
+	PageTokenKey string
+	// RequestIDWindow is how long CreateEvent request IDs are remembered for
+	// deduplication, in the memory of this server.
+	RequestIDWindow time.Duration
+	// WatchBufferSize is the number of events buffered per WatchEvents
+	// stream.
+	WatchBufferSize int
+	// PurgeGracePeriod is how long deleted events can be restored before
+	// they are purged.
+	PurgeGracePeriod time.Duration
+	// DurationLimits lists the allowed execution durations of events per
+	// source and action, in the format of usage.ParseDurationLimits.
+	DurationLimits string
+	// PermissiveSources accepts events of sources and actions that are not
+	// registered with the SourceService.
+	PermissiveSources bool
+	// RateCardsFile is the path of a JSON file of rate cards pricing the
+	// events of actions without a catalog price, in the format of
+	// usage.ParseRateCards.
+	RateCardsFile string
+	// QuotasFile is the path of a JSON file of quotas limiting the usage of
+	// subjects, in the format of usage.ParseQuotas.
+	QuotasFile string
+	// EnforceQuotas rejects events of exhausted quotas with
+	// RESOURCE_EXHAUSTED.
+	EnforceQuotas bool
+}
+
 // Run starts the gRPC server and gRPC-Gateway HTTP server.
-func Run() error {
-	svc := usage.NewService()
+func Run(cfg Config) error {
+	store, closeStore, err := OpenStore(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	durationLimits, err := usage.ParseDurationLimits(cfg.DurationLimits)
+	if err != nil {
+		return fmt.Errorf("parse duration limits: %w", err)
+	}
+	rateCards, err := loadRateCards(cfg.RateCardsFile)
+	if err != nil {
+		return fmt.Errorf("load rate cards: %w", err)
+	}
+	quotas, err := loadQuotas(cfg.QuotasFile, cfg.EnforceQuotas)
+	if err != nil {
+		return fmt.Errorf("load quotas: %w", err)
+	}
+
+	if cfg.PageTokenKey == "" {
+		log.Printf("No page token key configured; page tokens will not survive a restart")
+	}
+	if cfg.PermissiveSources {
+		log.Printf("Source registry is not enforced; events of unregistered sources are accepted")
+	}
+	usageCfg := usage.Config{
+		PageTokenKey:      []byte(cfg.PageTokenKey),
+		RequestIDWindow:   cfg.RequestIDWindow,
+		WatchBufferSize:   cfg.WatchBufferSize,
+		PurgeGracePeriod:  cfg.PurgeGracePeriod,
+		DurationLimits:    &durationLimits,
+		PermissiveSources: cfg.PermissiveSources,
+		RateCards:         rateCards,
+		Quotas:            quotas,
+		EnforceQuotas:     cfg.EnforceQuotas,
+	}
+	svc, err := usage.NewService(store, usageCfg)
+	if err != nil {
+		return err
+	}
+	sourceSvc, err := usage.NewSourceService(store, usageCfg)
+	if err != nil {
+		return err
+	}
+	priceSvc, err := usage.NewPriceCatalogService(store, usageCfg)
+	if err != nil {
+		return err
+	}
+	go svc.RunPurger(context.Background())
 
 	// Start gRPC server in a goroutine
 	go func() {
-		if err := runGRPCServer(svc); err != nil {
+		if err := runGRPCServer(svc, sourceSvc, priceSvc); err != nil {
 			log.Fatalf("gRPC server failed: %v", err)
 		}
 	}()
@@ -35,14 +134,89 @@ func Run() error {
 	return runHTTPServer()
 }
 
-func runGRPCServer(svc *usage.Service) error {
+// OpenStore creates the event store selected by the config. The returned
+// function releases the store's resources.
+func OpenStore(ctx context.Context, cfg Config) (usage.Store, func(), error) {
+	switch cfg.Store {
+	case StoreMemory, "":
+		log.Printf("Using in-memory event store")
+		return usage.NewMemoryStore(), func() {}, nil
+	case StoreSQLite:
+		store, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open SQLite store: %w", err)
+		}
+		log.Printf("Using SQLite event store at %s", cfg.SQLitePath)
+		return store, func() { store.Close() }, nil
+	case StorePostgres:
+		store, err := postgres.Open(ctx, cfg.PostgresDSN)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open PostgreSQL store: %w", err)
+		}
+		log.Printf("Using PostgreSQL event store")
+		return store, store.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store %q", cfg.Store)
+	}
+}
+
+// loadRateCards reads the rate cards in the file at path. Without a path,
+// only events of actions with a catalog price are priced.
+func loadRateCards(path string) (usage.RateCards, error) {
+	if path == "" {
+		log.Printf("No rate cards configured; only events with a catalog price will be priced")
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := usage.ParseRateCards(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	log.Printf("Pricing events with %d rate cards from %s", len(cards), path)
+	return cards, nil
+}
+
+// loadQuotas reads the quotas in the file at path. Without a path, no usage
+// is limited.
+func loadQuotas(path string, enforce bool) (usage.Quotas, error) {
+	if path == "" {
+		if enforce {
+			log.Printf("Quotas are enforced, but none are configured")
+		}
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	quotas, err := usage.ParseQuotas(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if enforce {
+		log.Printf("Enforcing %d quotas from %s", len(quotas), path)
+	} else {
+		log.Printf("Loaded %d quotas from %s; they are reported by CheckQuota but not enforced", len(quotas), path)
+	}
+	return quotas, nil
+}
+
+func runGRPCServer(svc *usage.Service, sourceSvc *usage.SourceService, priceSvc *usage.PriceCatalogService) error {
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(fieldbehavior.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(fieldbehavior.StreamServerInterceptor()),
+	)
 	usagev1.RegisterEventServiceServer(grpcServer, svc)
+	usagev1.RegisterSourceServiceServer(grpcServer, sourceSvc)
+	usagev1.RegisterPriceCatalogServiceServer(grpcServer, priceSvc)
 
 	// Enable reflection for tools like grpcurl
 	reflection.Register(grpcServer)
@@ -62,6 +236,26 @@ func runHTTPServer() error {
 	if err != nil {
 		return err
 	}
+	err = usagev1.RegisterSourceServiceHandlerFromEndpoint(ctx, mux, "localhost"+grpcAddr, opts)
+	if err != nil {
+		return err
+	}
+	err = usagev1.RegisterPriceCatalogServiceHandlerFromEndpoint(ctx, mux, "localhost"+grpcAddr, opts)
+	if err != nil {
+		return err
+	}
+
+	// Streaming RPCs are not exposed by the gateway; serve WatchEvents as
+	// Server-Sent Events instead
+	conn, err := grpc.NewClient("localhost"+grpcAddr, opts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = mux.HandlePath(http.MethodGet, "/v1/events:watch", watchEventsHandler(mux, usagev1.NewEventServiceClient(conn)))
+	if err != nil {
+		return err
+	}
 
 	// Wrap with CORS handler for browser requests
 	handler := corsHandler(mux)
@@ -73,8 +267,8 @@ func runHTTPServer() error {
 func corsHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
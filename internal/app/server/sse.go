@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// sseHeartbeatInterval is how often a comment is sent on an idle event
+// stream, so that proxies do not close the connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseMarshaler encodes events like the gateway's default JSON marshaler.
+var sseMarshaler = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// watchEventsHandler serves WatchEvents as Server-Sent Events, for browsers
+// that cannot consume gRPC streams. Each event's SSE id is its resume token,
+// so an EventSource that reconnects resumes where it left off through the
+// Last-Event-ID header. The filter and an initial resume token can be passed
+// as the filter and resume_token query parameters.
+func watchEventsHandler(mux *runtime.ServeMux, client usagev1.EventServiceClient) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		req := &usagev1.WatchEventsRequest{
+			Filter:      r.URL.Query().Get("filter"),
+			ResumeToken: r.URL.Query().Get("resume_token"),
+		}
+		if id := r.Header.Get("Last-Event-ID"); id != "" {
+			req.ResumeToken = id
+		}
+
+		// The server sends headers once it has accepted the request, so that
+		// invalid requests can still be answered with an HTTP error status.
+		var first *usagev1.WatchEventsResponse
+		stream, err := client.WatchEvents(r.Context(), req)
+		if err == nil {
+			var md metadata.MD
+			md, err = stream.Header()
+			if err == nil && md == nil {
+				first, err = stream.Recv()
+			}
+		}
+		if err != nil {
+			_, outbound := runtime.MarshalerForRequest(mux, r)
+			runtime.HTTPError(r.Context(), mux, outbound, w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		// Keep reverse proxies such as nginx from buffering the stream
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		rc := http.NewResponseController(w)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		responses := make(chan *usagev1.WatchEventsResponse, 1)
+		if first != nil {
+			responses <- first
+		}
+		streamErr := make(chan error, 1)
+		go func() {
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					streamErr <- err
+					return
+				}
+				select {
+				case responses <- resp:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(sseHeartbeatInterval)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case resp := <-responses:
+				data, err := sseMarshaler.Marshal(resp.GetEvent())
+				if err != nil {
+					return
+				}
+				if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", resp.GetResumeToken(), data); err != nil {
+					return
+				}
+				heartbeat.Reset(sseHeartbeatInterval)
+			case err := <-streamErr:
+				// The client reconnects after the stream ends, resuming from
+				// the last event it received
+				st := status.Convert(err)
+				data, _ := sseMarshaler.Marshal(st.Proto())
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+				rc.Flush()
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
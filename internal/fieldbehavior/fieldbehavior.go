@@ -0,0 +1,155 @@
+// Package fieldbehavior enforces google.api.field_behavior annotations
+// (AIP-203) on request messages, based on their descriptors.
+package fieldbehavior
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// updateMaskField is the name of the field mask field of AIP-134 update
+// requests.
+const updateMaskField = "update_mask"
+
+// ReasonRequired is the reason of violations reporting a missing REQUIRED
+// field.
+const ReasonRequired = "FIELD_REQUIRED"
+
+// Validate enforces the field behaviors of m and of the messages it
+// contains: OUTPUT_ONLY fields set by the client are cleared, and REQUIRED
+// fields that are not set are reported as violations. Field paths are
+// relative to m, e.g. "event.subject" or "requests[2].event".
+//
+// In a message with an update_mask field, the REQUIRED fields of the other
+// message fields are only checked if they are listed in the mask, as an
+// update may leave them out (AIP-134).
+func Validate(m proto.Message) []apierror.FieldViolation {
+	v := validator{clearOutputOnly: true, repeated: true}
+	v.message(m.ProtoReflect(), "", nil)
+	return v.violations
+}
+
+// CheckRequired reports the REQUIRED fields of m and of the messages it
+// contains that are not set, without modifying m.
+func CheckRequired(m proto.Message) []apierror.FieldViolation {
+	v := validator{repeated: true}
+	v.message(m.ProtoReflect(), "", nil)
+	return v.violations
+}
+
+// validateTopLevel is like Validate but does not descend into repeated
+// fields, whose elements handlers may want to validate and report one by
+// one, e.g. for partial success of batch requests.
+func validateTopLevel(m proto.Message) []apierror.FieldViolation {
+	v := validator{clearOutputOnly: true}
+	v.message(m.ProtoReflect(), "", nil)
+	return v.violations
+}
+
+// Error returns an INVALID_ARGUMENT error reporting the violations, or nil
+// if there are none.
+func Error(violations []apierror.FieldViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return apierror.BadRequest(violations...)
+}
+
+type validator struct {
+	clearOutputOnly bool
+	repeated        bool // whether to descend into repeated fields
+	violations      []apierror.FieldViolation
+}
+
+// message validates m, whose path is prefix. If required is not nil, only
+// the REQUIRED fields it contains are checked.
+func (v *validator) message(m protoreflect.Message, prefix string, required map[string]bool) {
+	mask := updateMask(m)
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		behaviors := Behaviors(fd)
+		if slices.Contains(behaviors, annotations.FieldBehavior_OUTPUT_ONLY) {
+			if v.clearOutputOnly {
+				m.Clear(fd)
+			}
+			continue
+		}
+		if !m.Has(fd) {
+			if slices.Contains(behaviors, annotations.FieldBehavior_REQUIRED) && (required == nil || required[string(fd.Name())]) {
+				v.violations = append(v.violations, apierror.FieldViolation{
+					Field:       path,
+					Reason:      ReasonRequired,
+					Description: path + " is required",
+				})
+			}
+			continue
+		}
+
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+			continue
+		}
+		var nested map[string]bool
+		if mask != nil && fd.Name() != updateMaskField {
+			nested = mask
+		}
+		switch {
+		case fd.IsList():
+			if !v.repeated {
+				continue
+			}
+			list := m.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				v.message(list.Get(j).Message(), fmt.Sprintf("%s[%d]", path, j), nested)
+			}
+		default:
+			v.message(m.Get(fd).Message(), path, nested)
+		}
+	}
+}
+
+// updateMask returns the top-level paths of the update mask of an update
+// request, or nil if m has no update mask field. An empty mask stands for
+// the populated fields only, so none of the REQUIRED fields are checked.
+func updateMask(m protoreflect.Message) map[string]bool {
+	fd := m.Descriptor().Fields().ByName(updateMaskField)
+	if fd == nil || fd.Message() == nil || fd.Message().FullName() != "google.protobuf.FieldMask" {
+		return nil
+	}
+	paths := map[string]bool{}
+	if !m.Has(fd) {
+		return paths
+	}
+	mask, ok := m.Get(fd).Message().Interface().(*fieldmaskpb.FieldMask)
+	if !ok {
+		return paths
+	}
+	for _, p := range mask.GetPaths() {
+		if p == "*" {
+			return nil
+		}
+		first, _, _ := strings.Cut(p, ".")
+		paths[first] = true
+	}
+	return paths
+}
+
+// Behaviors returns the field behaviors a field is annotated with.
+func Behaviors(fd protoreflect.FieldDescriptor) []annotations.FieldBehavior {
+	behaviors, _ := proto.GetExtension(fd.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	return behaviors
+}
@@ -0,0 +1,161 @@
+package fieldbehavior
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+func validEvent() *usagev1.Event {
+	return &usagev1.Event{
+		Subject:           "users/alice",
+		Source:            "animal-classifier",
+		Action:            "classify",
+		ExecutionDuration: durationpb.New(time.Second),
+	}
+}
+
+// fields returns the fields of violations, all of which must report a
+// missing REQUIRED field.
+func fields(t *testing.T, violations []apierror.FieldViolation) []string {
+	t.Helper()
+	var fields []string
+	for _, v := range violations {
+		if v.Reason != ReasonRequired {
+			t.Errorf("violation of %s reason = %s, want %s", v.Field, v.Reason, ReasonRequired)
+		}
+		fields = append(fields, v.Field)
+	}
+	return fields
+}
+
+func TestValidateRequired(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		req  proto.Message
+		want []string
+	}{
+		{"valid", &usagev1.CreateEventRequest{Event: validEvent()}, nil},
+		{"missing message", &usagev1.CreateEventRequest{}, []string{"event"}},
+		{"empty message", &usagev1.CreateEventRequest{Event: &usagev1.Event{}},
+			[]string{"event.subject", "event.source", "event.action", "event.execution_duration"}},
+		{"empty repeated field", &usagev1.BatchCreateEventsRequest{}, []string{"requests"}},
+		{"element of a repeated field", &usagev1.BatchCreateEventsRequest{Requests: []*usagev1.CreateEventRequest{
+			{Event: validEvent()},
+			{Event: &usagev1.Event{Subject: "users/alice", Source: "animal-classifier", Action: "classify"}},
+			{},
+		}}, []string{"requests[1].event.execution_duration", "requests[2].event"}},
+
+		// Update requests only require the fields in their update mask
+		{"update without mask", &usagev1.UpdateEventRequest{Event: &usagev1.Event{Name: "events/a"}}, nil},
+		{"update of other fields", &usagev1.UpdateEventRequest{
+			Event:      &usagev1.Event{Name: "events/a"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels", "etag"}},
+		}, nil},
+		{"update of a required field", &usagev1.UpdateEventRequest{
+			Event:      &usagev1.Event{Name: "events/a"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"labels", "subject"}},
+		}, []string{"event.subject"}},
+		{"update of all fields", &usagev1.UpdateEventRequest{
+			Event:      &usagev1.Event{Name: "events/a", Subject: "users/alice"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"*"}},
+		}, []string{"event.source", "event.action", "event.execution_duration"}},
+		{"update of a nested field", &usagev1.UpdatePriceRequest{
+			Price:      &usagev1.Price{Name: "sources/s/actions/a/prices/p"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"effective_time.end_time"}},
+		}, []string{"price.effective_time"}},
+		{"update missing the resource", &usagev1.UpdateEventRequest{}, []string{"event"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fields(t, Validate(tc.req)); !slices.Equal(got, tc.want) {
+				t.Errorf("Validate() fields = %q, want %q", got, tc.want)
+			}
+			if got := fields(t, CheckRequired(tc.req)); !slices.Equal(got, tc.want) {
+				t.Errorf("CheckRequired() fields = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateTopLevel(t *testing.T) {
+	// Elements of repeated fields are left to the handler
+	req := &usagev1.BatchCreateEventsRequest{Requests: []*usagev1.CreateEventRequest{{}}}
+	if got := fields(t, validateTopLevel(req)); len(got) != 0 {
+		t.Errorf("validateTopLevel() fields = %q, want none", got)
+	}
+	if got := fields(t, validateTopLevel(&usagev1.BatchCreateEventsRequest{})); !slices.Equal(got, []string{"requests"}) {
+		t.Errorf("validateTopLevel() of an empty batch fields = %q, want requests", got)
+	}
+}
+
+func TestValidateClearsOutputOnly(t *testing.T) {
+	event := validEvent()
+	event.Name = "events/a"
+	event.Etag = `"etag"`
+	event.CreateTime = timestamppb.Now()
+	event.DeleteTime = timestamppb.Now()
+	event.Cost = &money.Money{CurrencyCode: "USD", Units: 1}
+	event.PriceVersion = "v1"
+	req := &usagev1.BatchCreateEventsRequest{Requests: []*usagev1.CreateEventRequest{{Event: event}}}
+
+	// CheckRequired leaves the request as it is
+	before := proto.Clone(req)
+	CheckRequired(req)
+	if !proto.Equal(req, before) {
+		t.Errorf("CheckRequired() changed the request to %v", req)
+	}
+
+	// Validate clears the output only fields, including those of elements
+	// of repeated fields, and keeps the others, IDENTIFIER ones included
+	if violations := Validate(req); len(violations) > 0 {
+		t.Errorf("Validate() = %v, want no violations", violations)
+	}
+	want := validEvent()
+	want.Name = "events/a"
+	want.Etag = `"etag"`
+	if got := req.GetRequests()[0].GetEvent(); !proto.Equal(got, want) {
+		t.Errorf("Validate() left the event %v, want %v", got, want)
+	}
+
+	// validateTopLevel only clears those outside of repeated fields
+	update := &usagev1.UpdatePriceRequest{Price: &usagev1.Price{
+		Name:          "sources/s/actions/a/prices/p",
+		EffectiveTime: &interval.Interval{EndTime: timestamppb.Now()},
+		CreateTime:    timestamppb.Now(),
+	}}
+	validateTopLevel(update)
+	if update.GetPrice().GetCreateTime() != nil || update.GetPrice().GetEffectiveTime() == nil {
+		t.Errorf("validateTopLevel() left the price %v, want it without create_time", update.GetPrice())
+	}
+	req.Requests[0].Event.CreateTime = timestamppb.Now()
+	validateTopLevel(req)
+	if req.GetRequests()[0].GetEvent().GetCreateTime() == nil {
+		t.Errorf("validateTopLevel() cleared the create_time of an element of a repeated field")
+	}
+}
+
+func TestBehaviors(t *testing.T) {
+	fields := (&usagev1.Price{}).ProtoReflect().Descriptor().Fields()
+	for name, want := range map[string][]annotations.FieldBehavior{
+		"name":           {annotations.FieldBehavior_IDENTIFIER},
+		"effective_time": {annotations.FieldBehavior_REQUIRED},
+		"per_call":       {annotations.FieldBehavior_OPTIONAL, annotations.FieldBehavior_IMMUTABLE},
+		"create_time":    {annotations.FieldBehavior_OUTPUT_ONLY},
+	} {
+		if got := Behaviors(fields.ByName(protoreflect.Name(name))); !slices.Equal(got, want) {
+			t.Errorf("Behaviors(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
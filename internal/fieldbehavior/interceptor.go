@@ -0,0 +1,79 @@
+package fieldbehavior
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// Validator is implemented by services whose requests have constraints
+// beyond field behaviors. The interceptors report its violations together
+// with the field behavior violations, so that clients learn about all
+// problems of a request at once.
+//
+// Handlers call ValidateRequest again themselves, as they may be called
+// without the interceptors, e.g. in tests. Behind the interceptors, that
+// check finds nothing new: the interceptors report all violations of a
+// request, and the handlers only those of requests that bypassed them. The
+// field behaviors of a request are only enforced by the interceptors, and
+// those of the elements of its repeated fields by the handlers.
+type Validator interface {
+	// ValidateRequest returns the violations of req other than those of its
+	// field behaviors.
+	ValidateRequest(req proto.Message) []apierror.FieldViolation
+}
+
+// UnaryServerInterceptor validates the field behaviors of requests before
+// they reach the handler, failing with INVALID_ARGUMENT on violations. If
+// the service implements Validator, its violations are reported as well.
+// Elements of repeated fields are left to the handler; see Validate.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if m, ok := req.(proto.Message); ok {
+			if err := Error(violations(info.Server, m)); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor validates the request of server-streaming RPCs
+// like UnaryServerInterceptor. Messages of client streams are left to the
+// handler, which can reject them individually without ending the stream.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.IsClientStream {
+			return handler(srv, ss)
+		}
+		return handler(srv, &validatingStream{ServerStream: ss, srv: srv})
+	}
+}
+
+// validatingStream validates the messages received on a stream.
+type validatingStream struct {
+	grpc.ServerStream
+	srv any
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		return Error(violations(s.srv, msg))
+	}
+	return nil
+}
+
+// violations returns the violations of a request received by srv.
+func violations(srv any, req proto.Message) []apierror.FieldViolation {
+	violations := validateTopLevel(req)
+	if v, ok := srv.(Validator); ok {
+		violations = append(violations, v.ValidateRequest(req)...)
+	}
+	return violations
+}
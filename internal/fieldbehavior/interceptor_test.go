@@ -0,0 +1,153 @@
+package fieldbehavior
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// testValidator is a service reporting the same violations for every
+// request.
+type testValidator struct {
+	violations []apierror.FieldViolation
+}
+
+func (v testValidator) ValidateRequest(proto.Message) []apierror.FieldViolation {
+	return v.violations
+}
+
+// badRequestFields returns the code of err and the fields of the violations
+// of its BadRequest detail.
+func badRequestFields(err error) (codes.Code, []string) {
+	st := status.Convert(err)
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	return st.Code(), fields
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	invalidID := apierror.FieldViolation{Field: "event_id", Reason: "INVALID_EVENT_ID", Description: "invalid event_id"}
+	event := validEvent()
+	event.CreateTime = timestamppb.Now()
+
+	for _, tc := range []struct {
+		name   string
+		server any
+		req    any
+		want   []string // the fields of the violations, if any
+	}{
+		{"valid", testValidator{}, &usagev1.CreateEventRequest{Event: event}, nil},
+		{"field behaviors", testValidator{}, &usagev1.CreateEventRequest{Event: &usagev1.Event{Subject: "users/alice"}},
+			[]string{"event.source", "event.action", "event.execution_duration"}},
+		{"validator", testValidator{[]apierror.FieldViolation{invalidID}}, &usagev1.CreateEventRequest{Event: validEvent()},
+			[]string{"event_id"}},
+		{"field behaviors and validator", testValidator{[]apierror.FieldViolation{invalidID}}, &usagev1.CreateEventRequest{},
+			[]string{"event", "event_id"}},
+		{"no validator", struct{}{}, &usagev1.CreateEventRequest{}, []string{"event"}},
+		{"elements of repeated fields", testValidator{}, &usagev1.BatchCreateEventsRequest{
+			Requests: []*usagev1.CreateEventRequest{{}},
+		}, nil},
+		{"not a message", testValidator{}, "request", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled any
+			handler := func(ctx context.Context, req any) (any, error) {
+				handled = req
+				return "response", nil
+			}
+			resp, err := UnaryServerInterceptor()(context.Background(), tc.req, &grpc.UnaryServerInfo{Server: tc.server}, handler)
+
+			if tc.want != nil {
+				if code, fields := badRequestFields(err); code != codes.InvalidArgument || !slices.Equal(fields, tc.want) {
+					t.Errorf("interceptor error = %v, want %v of %q", err, codes.InvalidArgument, tc.want)
+				}
+				if handled != nil {
+					t.Errorf("interceptor called the handler with an invalid request")
+				}
+				return
+			}
+			if err != nil || resp != "response" {
+				t.Fatalf("interceptor = %v, %v, want the response of the handler", resp, err)
+			}
+			if handled != tc.req {
+				t.Errorf("interceptor called the handler with %v, want %v", handled, tc.req)
+			}
+		})
+	}
+
+	if event.GetCreateTime() != nil {
+		t.Errorf("interceptor left the output only create_time of the request")
+	}
+}
+
+// testServerStream is a server stream receiving the given requests.
+type testServerStream struct {
+	grpc.ServerStream
+	requests []proto.Message
+}
+
+func (s *testServerStream) RecvMsg(m any) error {
+	if len(s.requests) == 0 {
+		return io.EOF
+	}
+	proto.Merge(m.(proto.Message), s.requests[0])
+	s.requests = s.requests[1:]
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	server := testValidator{[]apierror.FieldViolation{{Field: "filter", Reason: "INVALID_FILTER"}}}
+	for _, tc := range []struct {
+		name         string
+		clientStream bool
+		want         []string
+	}{
+		{"server stream", false, []string{"event", "filter"}},
+		{"client stream", true, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := &testServerStream{requests: []proto.Message{&usagev1.CreateEventRequest{}}}
+			info := &grpc.StreamServerInfo{IsServerStream: true, IsClientStream: tc.clientStream}
+			err := StreamServerInterceptor()(server, stream, info, func(srv any, ss grpc.ServerStream) error {
+				return ss.RecvMsg(&usagev1.CreateEventRequest{})
+			})
+
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("interceptor error = %v, want the messages of client streams left to the handler", err)
+				}
+				return
+			}
+			if code, fields := badRequestFields(err); code != codes.InvalidArgument || !slices.Equal(fields, tc.want) {
+				t.Errorf("interceptor error = %v, want %v of %q", err, codes.InvalidArgument, tc.want)
+			}
+		})
+	}
+
+	// Errors of the stream are passed through
+	stream := &testServerStream{}
+	err := StreamServerInterceptor()(server, stream, &grpc.StreamServerInfo{IsServerStream: true}, func(srv any, ss grpc.ServerStream) error {
+		return ss.RecvMsg(&usagev1.CreateEventRequest{})
+	})
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("interceptor error = %v, want %v", err, io.EOF)
+	}
+}
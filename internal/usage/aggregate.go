@@ -0,0 +1,466 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/usage/filter"
+)
+
+const (
+	// aggregateBatchSize is the number of events AggregateUsage reads from
+	// the store at a time.
+	aggregateBatchSize = 1000
+	// maxAggregateGroups bounds the number of groups AggregateUsage keeps
+	// totals of, and so the memory it uses.
+	maxAggregateGroups = 10000
+)
+
+// Fields events can be grouped by, besides labels.{key}.
+const (
+	groupBySubject = "subject"
+	groupBySource  = "source"
+	groupByAction  = "action"
+)
+
+// grouping describes how AggregateUsage groups events.
+type grouping struct {
+	// fields are the group_by fields: groupBy* constants, or label keys
+	// prefixed with "labels.".
+	fields []string
+	bucket usagev1.TimeBucket
+	loc    *time.Location
+}
+
+// parseGrouping validates the grouping of an AggregateUsage request.
+func parseGrouping(req *usagev1.AggregateUsageRequest) (grouping, []apierror.FieldViolation) {
+	var violations []apierror.FieldViolation
+	g := grouping{bucket: req.GetTimeBucket(), loc: time.UTC}
+	for i, field := range req.GetGroupBy() {
+		path := fmt.Sprintf("group_by[%d]", i)
+		switch key, isLabel := strings.CutPrefix(field, FieldLabels+"."); {
+		case field == groupBySubject, field == groupBySource, field == groupByAction:
+		case isLabel:
+			if err := ValidateLabelKey(key); err != nil {
+				violations = append(violations, invalidField(path, ReasonInvalidGroupBy, fmt.Errorf("invalid label in group_by: %w", err)))
+				continue
+			}
+		default:
+			violations = append(violations, invalidField(path, ReasonInvalidGroupBy,
+				fmt.Errorf("cannot group by %q: expected subject, source, action or labels.{key}", field)))
+			continue
+		}
+		if slices.Contains(g.fields, field) {
+			violations = append(violations, invalidField(path, ReasonInvalidGroupBy, fmt.Errorf("duplicate group_by field %q", field)))
+			continue
+		}
+		g.fields = append(g.fields, field)
+	}
+	if _, ok := usagev1.TimeBucket_name[int32(g.bucket)]; !ok {
+		violations = append(violations, invalidField("time_bucket", ReasonInvalidTimeBucket, fmt.Errorf("unknown time_bucket %d", g.bucket)))
+	}
+	if tz := req.GetTimeZone(); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			violations = append(violations, invalidField("time_zone", ReasonInvalidTimeZone,
+				fmt.Errorf("unknown time_zone %q: must be an IANA time zone such as Europe/Prague", tz)))
+		} else {
+			g.loc = loc
+		}
+	}
+	return g, violations
+}
+
+// values returns the values of the group_by fields of event. Labels the
+// event does not have are reported as not ok, so that they can be told
+// apart from empty values.
+func (g grouping) values(event *usagev1.Event) (values []string, ok []bool) {
+	values, ok = make([]string, len(g.fields)), make([]bool, len(g.fields))
+	for i, field := range g.fields {
+		switch field {
+		case groupBySubject:
+			values[i], ok[i] = event.GetSubject(), true
+		case groupBySource:
+			values[i], ok[i] = event.GetSource(), true
+		case groupByAction:
+			values[i], ok[i] = event.GetAction(), true
+		default:
+			values[i], ok[i] = event.GetLabels()[strings.TrimPrefix(field, FieldLabels+".")]
+		}
+	}
+	return values, ok
+}
+
+// bucketOf returns the time bucket containing t.
+func (g grouping) bucketOf(t time.Time) (start, end time.Time) {
+	t = t.In(g.loc)
+	switch g.bucket {
+	case usagev1.TimeBucket_TIME_BUCKET_HOUR:
+		// Hours start at the same offset from UTC as t, which also handles
+		// zones offset by fractions of an hour and daylight saving changes
+		_, offset := t.Zone()
+		shift := time.Duration(offset) * time.Second
+		start = t.Add(shift).Truncate(time.Hour).Add(-shift)
+		return start, start.Add(time.Hour)
+	case usagev1.TimeBucket_TIME_BUCKET_DAY:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, g.loc)
+		return start, start.AddDate(0, 0, 1)
+	default:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, g.loc)
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// usageGroup accumulates the totals of a group of events.
+type usageGroup struct {
+	aggregate *usagev1.UsageAggregate
+	key       string
+	start     time.Time
+	values    []string
+	count     int64
+	total     time.Duration
+	costs     map[string]int64 // nanos per currency code
+	// durations are the execution durations of the events for the exact
+	// percentiles, unless the totals come from rollups, whose sketch
+	// estimates them instead.
+	durations []time.Duration
+	sketch    DurationSketch
+}
+
+// add adds event to the totals of g.
+func (g *usageGroup) add(event *usagev1.Event) {
+	d := event.GetExecutionDuration().AsDuration()
+	g.count++
+	g.total += d
+	g.durations = append(g.durations, d)
+	if cost := event.GetCost(); cost != nil {
+		g.costs[cost.GetCurrencyCode()] += moneyNanos(cost)
+	}
+}
+
+// addRollup adds the totals of r to g.
+func (g *usageGroup) addRollup(r *Rollup) {
+	g.count += r.EventCount
+	g.total += r.TotalDuration
+	if g.sketch == nil {
+		g.sketch = make(DurationSketch)
+	}
+	for i, n := range r.Durations {
+		g.sketch[i] += n
+	}
+	for currency, nanos := range r.Costs {
+		g.costs[currency] += nanos
+	}
+}
+
+// finish computes the totals of g into its aggregate.
+func (g *usageGroup) finish() *usagev1.UsageAggregate {
+	a := g.aggregate
+	a.EventCount = g.count
+	a.TotalExecutionDuration = durationpb.New(g.total)
+	a.AverageExecutionDuration = durationpb.New(g.total / time.Duration(g.count))
+	if g.sketch != nil {
+		a.P50ExecutionDuration = durationpb.New(g.sketch.Quantile(50))
+		a.P95ExecutionDuration = durationpb.New(g.sketch.Quantile(95))
+		a.MaxExecutionDuration = durationpb.New(g.sketch.Quantile(100))
+	} else {
+		slices.Sort(g.durations)
+		a.P50ExecutionDuration = durationpb.New(percentile(g.durations, 50))
+		a.P95ExecutionDuration = durationpb.New(percentile(g.durations, 95))
+		a.MaxExecutionDuration = durationpb.New(g.durations[len(g.durations)-1])
+	}
+	for _, currency := range slices.Sorted(maps.Keys(g.costs)) {
+		nanos := g.costs[currency]
+		a.TotalCost = append(a.TotalCost, &money.Money{
+			CurrencyCode: currency,
+			Units:        nanos / nanosPerUnit,
+			Nanos:        int32(nanos % nanosPerUnit),
+		})
+	}
+	return a
+}
+
+// percentile returns the p-th percentile of sorted durations by the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// AggregateUsage returns the totals of the events matching a filter, grouped
+// by event fields and time buckets. Where the grouping and filter allow it,
+// the totals are read from rollups, see Rollup. Otherwise events are read
+// from the store in batches; of each event, only its execution duration is
+// kept for the percentiles.
+func (s *Service) AggregateUsage(ctx context.Context, req *usagev1.AggregateUsageRequest) (*usagev1.AggregateUsageResponse, error) {
+	var violations []apierror.FieldViolation
+	predicate, err := compileFilter(req.GetFilter())
+	if err != nil {
+		violations = append(violations, invalidField("filter", ReasonInvalidFilter, fmt.Errorf("invalid filter: %w", err)))
+	}
+	g, groupingViolations := parseGrouping(req)
+	violations = append(violations, groupingViolations...)
+	if len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	var groups map[string]*usageGroup
+	expr, _ := filter.Parse(req.GetFilter())
+	if query, ok := g.rollupQuery(expr); ok {
+		groups, err = s.aggregateRollups(ctx, g, query, predicate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if groups == nil {
+		groups, err = s.aggregateEvents(ctx, g, predicate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &usagev1.AggregateUsageResponse{Aggregates: finishGroups(groups)}, nil
+}
+
+// finishGroups returns the aggregates of groups ordered by time bucket and
+// group_by values.
+func finishGroups(groups map[string]*usageGroup) []*usagev1.UsageAggregate {
+	sorted := slices.SortedFunc(maps.Values(groups), func(a, b *usageGroup) int {
+		if c := a.start.Compare(b.start); c != 0 {
+			return c
+		}
+		if c := slices.Compare(a.values, b.values); c != 0 {
+			return c
+		}
+		// An empty label and a missing one have the same value
+		return strings.Compare(a.key, b.key)
+	})
+	aggregates := make([]*usagev1.UsageAggregate, len(sorted))
+	for i, group := range sorted {
+		aggregates[i] = group.finish()
+	}
+	return aggregates
+}
+
+// aggregateEvents groups the live events matching predicate.
+func (s *Service) aggregateEvents(ctx context.Context, g grouping, predicate Predicate) (map[string]*usageGroup, error) {
+	groups := make(map[string]*usageGroup)
+	query := ListQuery{Limit: aggregateBatchSize, Filter: predicate}
+	for {
+		events, err := s.store.ListEvents(ctx, query)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list events: %v", err)
+		}
+		for _, event := range events {
+			group, err := g.group(groups, event)
+			if err != nil {
+				return nil, err
+			}
+			group.add(event)
+		}
+		if len(events) < aggregateBatchSize {
+			return groups, nil
+		}
+		cursor := CursorOf(events[len(events)-1])
+		query.After = &cursor
+	}
+}
+
+// aggregateRollups groups the rollups matching predicate. It returns nil
+// groups if a rollup spans more than one time bucket, as rollups of hours
+// do in zones offset from UTC by fractions of an hour, and the events must
+// be read instead.
+func (s *Service) aggregateRollups(ctx context.Context, g grouping, query RollupQuery, predicate Predicate) (map[string]*usageGroup, error) {
+	rollups, err := s.store.ListRollups(ctx, query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list rollups: %v", err)
+	}
+	groups := make(map[string]*usageGroup)
+	for _, r := range rollups {
+		if g.bucket != usagev1.TimeBucket_TIME_BUCKET_UNSPECIFIED {
+			if _, end := g.bucketOf(r.Start); r.Start.Add(r.Granularity.Duration()).After(end) {
+				return nil, nil
+			}
+		}
+		// The rollup stands for its events, which the filter matches alike,
+		// see rollupQuery
+		event := &usagev1.Event{
+			Subject:    r.Subject,
+			Source:     r.Source,
+			Action:     r.Action,
+			CreateTime: timestamppb.New(r.Start),
+		}
+		if r.EventCount <= 0 || predicate != nil && !predicate(event) {
+			continue
+		}
+		group, err := g.group(groups, event)
+		if err != nil {
+			return nil, err
+		}
+		group.addRollup(r)
+	}
+	return groups, nil
+}
+
+// rollupQuery returns the query of the rollups that AggregateUsage can read
+// instead of the events matching expr, if there are such. That requires
+// grouping by rollup key fields only, buckets made of whole rollups, and a
+// filter that matches either all or none of the events of each rollup: one
+// on the subject, source and action, and on the create time at most by >=
+// and < comparisons with starts of rollups.
+func (g grouping) rollupQuery(expr filter.Expr) (RollupQuery, bool) {
+	for _, field := range g.fields {
+		if field != groupBySubject && field != groupBySource && field != groupByAction {
+			return RollupQuery{}, false
+		}
+	}
+	var times []time.Time
+	if !rollupFilter(expr, &times) {
+		return RollupQuery{}, false
+	}
+	for _, granularity := range []Granularity{RollupDay, RollupHour} {
+		if granularity == RollupDay && g.bucket != usagev1.TimeBucket_TIME_BUCKET_UNSPECIFIED &&
+			(g.bucket == usagev1.TimeBucket_TIME_BUCKET_HOUR || g.loc != time.UTC) {
+			continue
+		}
+		aligned := !slices.ContainsFunc(times, func(t time.Time) bool {
+			return !t.Equal(t.Truncate(granularity.Duration()))
+		})
+		if aligned {
+			query := RollupQuery{Granularity: granularity}
+			query.Start, query.End = timeRange(expr)
+			return query, true
+		}
+	}
+	return RollupQuery{}, false
+}
+
+// rollupFilter reports whether expr matches either all or none of the
+// events of each rollup, collecting the create times it compares with.
+func rollupFilter(expr filter.Expr, times *[]time.Time) bool {
+	switch e := expr.(type) {
+	case nil:
+		return true
+	case *filter.And:
+		return !slices.ContainsFunc(e.Args, func(arg filter.Expr) bool { return !rollupFilter(arg, times) })
+	case *filter.Or:
+		return !slices.ContainsFunc(e.Args, func(arg filter.Expr) bool { return !rollupFilter(arg, times) })
+	case *filter.Not:
+		return rollupFilter(e.Arg, times)
+	case *filter.Restriction:
+		switch e.Field {
+		case FieldSubject, FieldSource, FieldAction:
+			return true
+		case FieldCreateTime:
+			t, err := time.Parse(time.RFC3339Nano, e.Value.Text)
+			if err != nil || e.Comparator != filter.GreaterEquals && e.Comparator != filter.Less {
+				return false
+			}
+			*times = append(*times, t)
+			return true
+		}
+	}
+	return false
+}
+
+// timeRange returns the range of create times expr restricts events to by
+// top-level comparisons; a zero time leaves that end open.
+func timeRange(expr filter.Expr) (start, end time.Time) {
+	switch e := expr.(type) {
+	case *filter.And:
+		for _, arg := range e.Args {
+			s, t := timeRange(arg)
+			if s.After(start) {
+				start = s
+			}
+			if !t.IsZero() && (end.IsZero() || t.Before(end)) {
+				end = t
+			}
+		}
+	case *filter.Restriction:
+		if e.Field == FieldCreateTime {
+			t, _ := time.Parse(time.RFC3339Nano, e.Value.Text)
+			if e.Comparator == filter.GreaterEquals {
+				start = t
+			} else {
+				end = t
+			}
+		}
+	}
+	return start, end
+}
+
+// group returns the group of event in groups, adding it if there is none.
+func (g grouping) group(groups map[string]*usageGroup, event *usagev1.Event) (*usageGroup, error) {
+	values, ok := g.values(event)
+	var start, end time.Time
+	if g.bucket != usagev1.TimeBucket_TIME_BUCKET_UNSPECIFIED {
+		start, end = g.bucketOf(event.GetCreateTime().AsTime())
+	}
+
+	// Values are quoted so that they cannot run into each other, and labels
+	// the event does not have are told apart from empty ones
+	key := strconv.AppendInt(nil, start.Unix(), 10)
+	for i, value := range values {
+		if ok[i] {
+			key = strconv.AppendQuote(key, value)
+		} else {
+			key = append(key, '-')
+		}
+	}
+
+	group, found := groups[string(key)]
+	if !found {
+		if len(groups) == maxAggregateGroups {
+			return nil, apierror.New(codes.InvalidArgument, ReasonTooManyGroups,
+				fmt.Sprintf("more than %d groups; narrow the filter, group by fewer fields or use larger time buckets", maxAggregateGroups),
+				map[string]string{"maxGroups": fmt.Sprint(maxAggregateGroups)})
+		}
+		group = &usageGroup{
+			aggregate: &usagev1.UsageAggregate{},
+			key:       string(key),
+			start:     start,
+			values:    values,
+			costs:     make(map[string]int64),
+		}
+		for i, field := range g.fields {
+			switch field {
+			case groupBySubject:
+				group.aggregate.Subject = values[i]
+			case groupBySource:
+				group.aggregate.Source = values[i]
+			case groupByAction:
+				group.aggregate.Action = values[i]
+			default:
+				if ok[i] {
+					if group.aggregate.Labels == nil {
+						group.aggregate.Labels = make(map[string]string)
+					}
+					group.aggregate.Labels[strings.TrimPrefix(field, FieldLabels+".")] = values[i]
+				}
+			}
+		}
+		if !start.IsZero() {
+			group.aggregate.Interval = &interval.Interval{
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			}
+		}
+		groups[string(key)] = group
+	}
+	return group, nil
+}
@@ -0,0 +1,357 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage/filter"
+)
+
+// aggregateTestEvents returns events spread over the end of March 2025,
+// which includes the change to daylight saving time in Europe and the end
+// of a month, and events right at and just before the starts of buckets in
+// zones offset from UTC by whole hours and by fractions of an hour.
+func aggregateTestEvents() []*usagev1.Event {
+	start := time.Date(2025, 3, 29, 12, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := range 240 {
+		times = append(times, start.Add(time.Duration(i)*18*time.Minute+time.Duration(i*7919%3600)*time.Second))
+	}
+	for _, t := range []string{
+		"2025-03-30T01:00:00Z", // Europe/Prague switches to CEST
+		"2025-03-31T18:15:00Z", // midnight in Asia/Kathmandu
+		"2025-03-31T18:30:00Z", // midnight in Asia/Kolkata
+		"2025-03-31T22:00:00Z", // midnight in Europe/Prague
+		"2025-04-01T00:00:00Z",
+		"2025-04-01T04:00:00Z", // midnight in America/New_York
+	} {
+		edge, _ := time.Parse(time.RFC3339, t)
+		times = append(times, edge, edge.Add(-time.Microsecond))
+	}
+
+	subjects := []string{"users/alice", "users/bob", "service-accounts/etl"}
+	sources := []string{"animal-classifier", "object-detector"}
+	events := make([]*usagev1.Event, len(times))
+	for i, t := range times {
+		event := &usagev1.Event{
+			Name:              fmt.Sprintf("events/e-%03d", i),
+			Subject:           subjects[i%len(subjects)],
+			Source:            sources[i%len(sources)],
+			Action:            "classify",
+			ExecutionDuration: durationpb.New(time.Duration(i*i%997) * time.Millisecond),
+			CreateTime:        timestamppb.New(t),
+		}
+		if i%5 >= 3 {
+			event.Action = "detect"
+		}
+		switch i % 3 {
+		case 0:
+			event.Labels = map[string]string{"model": "v2"}
+		case 1:
+			event.Labels = map[string]string{"model": ""}
+		}
+		switch {
+		case i%7 == 0:
+			event.Cost = &money.Money{CurrencyCode: "EUR", Units: int64(i), Nanos: 500000000}
+		case i%4 != 0:
+			event.Cost = &money.Money{CurrencyCode: "USD", Nanos: int32(i * 1000003)}
+		}
+		events[i] = event
+	}
+	return events
+}
+
+// newAggregateTestService returns a service whose store holds
+// aggregateTestEvents and their rollups.
+func newAggregateTestService(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, event := range aggregateTestEvents() {
+		if err := store.CreateEvent(ctx, event); err != nil {
+			t.Fatalf("CreateEvent() error = %v", err)
+		}
+	}
+	if _, err := RebuildRollups(ctx, store); err != nil {
+		t.Fatalf("RebuildRollups() error = %v", err)
+	}
+	return newTestServiceWithStore(t, store, Config{})
+}
+
+// compareAggregates reports the differences between the aggregates read
+// from rollups and those of the events. Percentiles estimated by a
+// DurationSketch may differ by its accuracy.
+func compareAggregates(t *testing.T, rollups, events []*usagev1.UsageAggregate) {
+	t.Helper()
+	if len(rollups) != len(events) {
+		t.Fatalf("got %d aggregates from rollups, want %d as from events", len(rollups), len(events))
+	}
+	for i := range rollups {
+		got, want := proto.Clone(rollups[i]).(*usagev1.UsageAggregate), proto.Clone(events[i]).(*usagev1.UsageAggregate)
+		percentiles := func(a *usagev1.UsageAggregate) []time.Duration {
+			p := []time.Duration{
+				a.GetP50ExecutionDuration().AsDuration(),
+				a.GetP95ExecutionDuration().AsDuration(),
+				a.GetMaxExecutionDuration().AsDuration(),
+			}
+			a.P50ExecutionDuration, a.P95ExecutionDuration, a.MaxExecutionDuration = nil, nil, nil
+			return p
+		}
+		gotPercentiles, wantPercentiles := percentiles(got), percentiles(want)
+		if !proto.Equal(got, want) {
+			t.Errorf("aggregate %d from rollups = %v, want %v", i, got, want)
+		}
+		for j, p := range []string{"p50", "p95", "max"} {
+			if diff := math.Abs(float64(gotPercentiles[j] - wantPercentiles[j])); diff > sketchAccuracy*float64(wantPercentiles[j])+1 {
+				t.Errorf("aggregate %d %s from rollups = %v, want %v within %v%%", i, p, gotPercentiles[j], wantPercentiles[j], 100*sketchAccuracy)
+			}
+		}
+	}
+}
+
+func TestAggregateRollupsMatchEvents(t *testing.T) {
+	ctx := context.Background()
+	s := newAggregateTestService(t)
+
+	for _, tz := range []string{"", "Europe/Prague", "America/New_York", "Asia/Kolkata", "Asia/Kathmandu"} {
+		for bucket := range usagev1.TimeBucket_name {
+			for _, groupBy := range [][]string{
+				nil,
+				{"subject"},
+				{"source", "action"},
+				{"action", "subject", "source"},
+			} {
+				for _, f := range []string{
+					"",
+					`subject = "users/alice"`,
+					`NOT action = "detect" OR subject = "users/*"`,
+					`create_time >= "2025-03-30T00:00:00Z" AND create_time < "2025-03-31T00:00:00Z"`,
+					`create_time >= "2025-03-31T18:00:00Z" AND source = "object-detector"`,
+				} {
+					req := &usagev1.AggregateUsageRequest{
+						Filter:     f,
+						GroupBy:    groupBy,
+						TimeBucket: usagev1.TimeBucket(bucket),
+						TimeZone:   tz,
+					}
+					name := fmt.Sprintf("%s/%s/%s/%s", tz, req.GetTimeBucket(), strings.Join(groupBy, ","), f)
+					t.Run(name, func(t *testing.T) {
+						g, violations := parseGrouping(req)
+						if len(violations) > 0 {
+							t.Fatalf("parseGrouping() violations = %v", violations)
+						}
+						predicate, err := compileFilter(f)
+						if err != nil {
+							t.Fatalf("compileFilter() error = %v", err)
+						}
+						expr, _ := filter.Parse(f)
+						query, ok := g.rollupQuery(expr)
+						if !ok {
+							t.Fatalf("rollupQuery() = false, want rollups to be used")
+						}
+
+						rollupGroups, err := s.aggregateRollups(ctx, g, query, predicate)
+						if err != nil {
+							t.Fatalf("aggregateRollups() error = %v", err)
+						}
+						eventGroups, err := s.aggregateEvents(ctx, g, predicate)
+						if err != nil {
+							t.Fatalf("aggregateEvents() error = %v", err)
+						}
+						want := finishGroups(eventGroups)
+
+						// Rollups of hours do not fit the buckets of zones
+						// offset by fractions of an hour, and the events are
+						// read instead
+						_, offset := time.Now().In(g.loc).Zone()
+						fractional := offset%3600 != 0
+						switch {
+						case rollupGroups == nil && !fractional:
+							t.Errorf("aggregateRollups() = nil, want the groups of the rollups")
+						case rollupGroups != nil && fractional && req.GetTimeBucket() == usagev1.TimeBucket_TIME_BUCKET_HOUR:
+							t.Errorf("aggregateRollups() = %d groups, want nil for hours offset by a fraction", len(rollupGroups))
+						case rollupGroups != nil:
+							compareAggregates(t, finishGroups(rollupGroups), want)
+						}
+
+						resp, err := s.AggregateUsage(ctx, req)
+						if err != nil {
+							t.Fatalf("AggregateUsage() error = %v", err)
+						}
+						compareAggregates(t, resp.GetAggregates(), want)
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestAggregateUsageGroupBy(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, Config{})
+	for _, e := range []struct {
+		subject, action string
+		duration        time.Duration
+		labels          map[string]string
+	}{
+		{"users/alice", "classify", 1 * time.Second, map[string]string{"model": "v2"}},
+		{"users/alice", "classify", 2 * time.Second, map[string]string{"model": ""}},
+		{"users/alice", "detect", 3 * time.Second, nil},
+		{"users/bob", "classify", 4 * time.Second, nil},
+		{"users/bob", "classify", 5 * time.Second, map[string]string{"model": "v2"}},
+	} {
+		event := testEvent(e.subject, e.action, e.duration)
+		event.Labels = e.labels
+		createTestEvent(t, s, event)
+	}
+
+	// Groups are given as their group_by values and total duration in
+	// seconds; an empty label is told apart from a missing one, shown as -
+	for _, tc := range []struct {
+		groupBy []string
+		want    []string
+	}{
+		{nil, []string{"15"}},
+		{[]string{"subject"}, []string{"users/alice 6", "users/bob 9"}},
+		{[]string{"action"}, []string{"classify 12", "detect 3"}},
+		{[]string{"action", "subject"}, []string{"classify users/alice 3", "classify users/bob 9", "detect users/alice 3"}},
+		{[]string{"subject", "action"}, []string{"users/alice classify 3", "users/alice detect 3", "users/bob classify 9"}},
+		{[]string{"labels.model"}, []string{" 2", "- 7", "v2 6"}},
+		{[]string{"labels.model", "subject"}, []string{" users/alice 2", "- users/alice 3", "- users/bob 4", "v2 users/alice 1", "v2 users/bob 5"}},
+		{[]string{"source", "labels.gpu"}, []string{"animal-classifier - 15"}},
+	} {
+		t.Run(strings.Join(tc.groupBy, ","), func(t *testing.T) {
+			resp, err := s.AggregateUsage(ctx, &usagev1.AggregateUsageRequest{GroupBy: tc.groupBy})
+			if err != nil {
+				t.Fatalf("AggregateUsage() error = %v", err)
+			}
+			var got []string
+			for _, a := range resp.GetAggregates() {
+				var fields []string
+				for _, field := range tc.groupBy {
+					switch field {
+					case "subject":
+						fields = append(fields, a.GetSubject())
+					case "source":
+						fields = append(fields, a.GetSource())
+					case "action":
+						fields = append(fields, a.GetAction())
+					default:
+						value, ok := a.GetLabels()[strings.TrimPrefix(field, "labels.")]
+						if !ok {
+							value = "-"
+						}
+						fields = append(fields, value)
+					}
+				}
+				fields = append(fields, fmt.Sprint(a.GetTotalExecutionDuration().AsDuration().Seconds()))
+				got = append(got, strings.Join(fields, " "))
+			}
+			if strings.Join(got, "; ") != strings.Join(tc.want, "; ") {
+				t.Errorf("AggregateUsage() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBucketOf(t *testing.T) {
+	for _, tc := range []struct {
+		tz         string
+		bucket     usagev1.TimeBucket
+		t          string
+		start, end string
+	}{
+		{"UTC", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-31T18:29:59Z", "2025-03-31T18:00:00Z", "2025-03-31T19:00:00Z"},
+		{"UTC", usagev1.TimeBucket_TIME_BUCKET_DAY, "2025-04-01T00:00:00Z", "2025-04-01T00:00:00Z", "2025-04-02T00:00:00Z"},
+		{"UTC", usagev1.TimeBucket_TIME_BUCKET_MONTH, "2025-03-31T23:59:59.999Z", "2025-03-01T00:00:00Z", "2025-04-01T00:00:00Z"},
+
+		// Hours and days of zones offset by fractions of an hour
+		{"Asia/Kolkata", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-31T18:29:59.999Z", "2025-03-31T17:30:00Z", "2025-03-31T18:30:00Z"},
+		{"Asia/Kolkata", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-31T18:30:00Z", "2025-03-31T18:30:00Z", "2025-03-31T19:30:00Z"},
+		{"Asia/Kolkata", usagev1.TimeBucket_TIME_BUCKET_DAY, "2025-03-31T18:29:59Z", "2025-03-30T18:30:00Z", "2025-03-31T18:30:00Z"},
+		{"Asia/Kolkata", usagev1.TimeBucket_TIME_BUCKET_MONTH, "2025-03-31T18:30:00Z", "2025-03-31T18:30:00Z", "2025-04-30T18:30:00Z"},
+		{"Asia/Kathmandu", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-31T18:14:59Z", "2025-03-31T17:15:00Z", "2025-03-31T18:15:00Z"},
+		{"Asia/Kathmandu", usagev1.TimeBucket_TIME_BUCKET_DAY, "2025-03-31T18:15:00Z", "2025-03-31T18:15:00Z", "2025-04-01T18:15:00Z"},
+
+		// Days and months across the change to daylight saving time
+		{"Europe/Prague", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-30T00:59:59Z", "2025-03-30T00:00:00Z", "2025-03-30T01:00:00Z"},
+		{"Europe/Prague", usagev1.TimeBucket_TIME_BUCKET_HOUR, "2025-03-30T01:30:00Z", "2025-03-30T01:00:00Z", "2025-03-30T02:00:00Z"},
+		{"Europe/Prague", usagev1.TimeBucket_TIME_BUCKET_DAY, "2025-03-30T12:00:00Z", "2025-03-29T23:00:00Z", "2025-03-30T22:00:00Z"},
+		{"Europe/Prague", usagev1.TimeBucket_TIME_BUCKET_MONTH, "2025-03-31T21:59:59Z", "2025-02-28T23:00:00Z", "2025-03-31T22:00:00Z"},
+		{"Europe/Prague", usagev1.TimeBucket_TIME_BUCKET_MONTH, "2025-03-31T22:00:00Z", "2025-03-31T22:00:00Z", "2025-04-30T22:00:00Z"},
+		{"America/New_York", usagev1.TimeBucket_TIME_BUCKET_DAY, "2025-03-09T12:00:00Z", "2025-03-09T05:00:00Z", "2025-03-10T04:00:00Z"},
+	} {
+		t.Run(fmt.Sprintf("%s/%s/%s", tc.tz, tc.bucket, tc.t), func(t *testing.T) {
+			g, violations := parseGrouping(&usagev1.AggregateUsageRequest{TimeBucket: tc.bucket, TimeZone: tc.tz})
+			if len(violations) > 0 {
+				t.Fatalf("parseGrouping() violations = %v", violations)
+			}
+			at, _ := time.Parse(time.RFC3339Nano, tc.t)
+			wantStart, _ := time.Parse(time.RFC3339Nano, tc.start)
+			wantEnd, _ := time.Parse(time.RFC3339Nano, tc.end)
+			start, end := g.bucketOf(at)
+			if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+				t.Errorf("bucketOf(%s) = [%s, %s), want [%s, %s)", tc.t,
+					start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), tc.start, tc.end)
+			}
+		})
+	}
+}
+
+func TestRollupQuery(t *testing.T) {
+	for _, tc := range []struct {
+		groupBy []string
+		bucket  usagev1.TimeBucket
+		tz      string
+		filter  string
+		want    Granularity // empty if rollups cannot be read
+	}{
+		{nil, usagev1.TimeBucket_TIME_BUCKET_UNSPECIFIED, "", "", RollupDay},
+		{[]string{"subject", "source", "action"}, usagev1.TimeBucket_TIME_BUCKET_DAY, "", "", RollupDay},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_MONTH, "", `subject = "users/*"`, RollupDay},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_HOUR, "", "", RollupHour},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "Europe/Prague", "", RollupHour},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "Asia/Kolkata", "", RollupHour},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_UNSPECIFIED, "Asia/Kolkata", "", RollupDay},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `create_time >= "2025-03-30T00:00:00Z"`, RollupDay},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `create_time < "2025-03-30T05:00:00Z"`, RollupHour},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `NOT create_time >= "2025-03-30T05:00:00+01:00"`, RollupHour},
+
+		// Filters and groupings that split rollups
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `create_time >= "2025-03-30T05:30:00Z"`, ""},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `create_time > "2025-03-30T00:00:00Z"`, ""},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `create_time <= "2025-03-30T00:00:00Z"`, ""},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", "execution_duration > 1s", ""},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `labels.model = "v2"`, ""},
+		{nil, usagev1.TimeBucket_TIME_BUCKET_DAY, "", `name = "events/e-001"`, ""},
+		{[]string{"labels.model"}, usagev1.TimeBucket_TIME_BUCKET_DAY, "", "", ""},
+	} {
+		t.Run(fmt.Sprintf("%v/%s/%s/%s", tc.groupBy, tc.bucket, tc.tz, tc.filter), func(t *testing.T) {
+			g, violations := parseGrouping(&usagev1.AggregateUsageRequest{GroupBy: tc.groupBy, TimeBucket: tc.bucket, TimeZone: tc.tz})
+			if len(violations) > 0 {
+				t.Fatalf("parseGrouping() violations = %v", violations)
+			}
+			expr, err := filter.Parse(tc.filter)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got Granularity
+			if query, ok := g.rollupQuery(expr); ok {
+				got = query.Granularity
+			}
+			if got != tc.want {
+				t.Errorf("rollupQuery() granularity = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
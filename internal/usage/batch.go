@@ -0,0 +1,187 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// maxBatchSize is the maximum number of events in a BatchCreateEvents
+// request.
+const maxBatchSize = 1000
+
+// BatchCreateEvents creates several usage events in one call.
+func (s *Service) BatchCreateEvents(ctx context.Context, req *usagev1.BatchCreateEventsRequest) (*usagev1.BatchCreateEventsResponse, error) {
+	if violations := validateBatchCreateEventsRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	if req.GetRequestId() == "" {
+		return s.batchCreateEvents(ctx, req)
+	}
+	resp, err := s.requests.do(ctx, req.GetRequestId(), batchCreateEventsFingerprint(req), func() (proto.Message, error) {
+		return s.batchCreateEvents(ctx, req)
+	})
+	if errors.Is(err, errRequestIDReused) {
+		return nil, requestIDReused(req.GetRequestId())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*usagev1.BatchCreateEventsResponse), nil
+}
+
+// validateBatchCreateEventsRequest returns the violations of the fields of a
+// BatchCreateEvents request other than its individual requests, which are
+// validated by batchCreateEvents.
+func validateBatchCreateEventsRequest(req *usagev1.BatchCreateEventsRequest) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+	if n := len(req.GetRequests()); n > maxBatchSize {
+		violations = append(violations, invalidField("requests", ReasonBatchTooLarge,
+			fmt.Errorf("at most %d events can be created in a batch, got %d", maxBatchSize, n)))
+	}
+	if req.GetRequestId() != "" {
+		if err := validateRequestID(req.GetRequestId()); err != nil {
+			violations = append(violations, invalidField("request_id", ReasonInvalidRequestID, err))
+		}
+	}
+	return violations
+}
+
+// batchCreateEvents validates and stores the events of a BatchCreateEvents
+// request. In atomic mode the first error fails the whole batch; otherwise
+// errors are reported per request.
+func (s *Service) batchCreateEvents(ctx context.Context, req *usagev1.BatchCreateEventsRequest) (*usagev1.BatchCreateEventsResponse, error) {
+	partial := req.GetAllowPartialSuccess()
+	reservation := s.watchers.reserve(len(req.GetRequests()))
+	var created []*usagev1.Event
+	defer func() { reservation.publish(created...) }()
+
+	var (
+		events   []*usagev1.Event
+		indexes  []int // request index of each event
+		failures []*usagev1.BatchCreateEventsResponse_Failure
+		sources  = make(map[string]*usagev1.Source)
+		quotas   = s.newQuotaTracker()
+	)
+	if s.enforceQuotas {
+		subjects := make([]string, len(req.GetRequests()))
+		for i, r := range req.GetRequests() {
+			subjects[i] = r.GetEvent().GetSubject()
+		}
+		defer s.quotaLocks.lock(subjects...)()
+	}
+	for i, r := range req.GetRequests() {
+		violations := append(fieldbehavior.Validate(r), s.validateCreateEventRequest(r)...)
+		if r.GetRequestId() != "" {
+			violations = append(violations, invalidField("request_id", ReasonInvalidRequestID,
+				errors.New("request_id must be set on the batch request, not on individual requests")))
+		}
+		registryViolations, err := s.sourceViolations(ctx, "event", r.GetEvent(), sources)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, registryViolations...)
+		if len(violations) > 0 {
+			err := apierror.Prefix(apierror.BadRequest(violations...), fmt.Sprintf("requests[%d]", i))
+			if !partial {
+				return nil, err
+			}
+			failures = append(failures, batchFailure(i, err))
+			continue
+		}
+		event, err := s.newEvent(ctx, r, reservation.createTime(i))
+		if err != nil {
+			return nil, err
+		}
+		if s.enforceQuotas {
+			if err := quotas.admit(ctx, event); err != nil {
+				if status.Code(err) != codes.ResourceExhausted {
+					return nil, err
+				}
+				err = apierror.Prefix(err, fmt.Sprintf("requests[%d]", i))
+				if !partial {
+					return nil, err
+				}
+				failures = append(failures, batchFailure(i, err))
+				continue
+			}
+		}
+		events = append(events, event)
+		indexes = append(indexes, i)
+	}
+	if len(events) == 0 {
+		return &usagev1.BatchCreateEventsResponse{Failures: failures}, nil
+	}
+
+	err := s.store.BatchCreateEvents(ctx, events)
+	if err == nil {
+		created = events
+		s.updateRollups(ctx, createdRollups(events))
+		return &usagev1.BatchCreateEventsResponse{Events: events, Failures: failures}, nil
+	}
+	if !errors.Is(err, ErrAlreadyExists) {
+		return nil, status.Errorf(codes.Internal, "failed to store events: %v", err)
+	}
+	if !partial {
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, status.Errorf(codes.Internal, "failed to store events: %v", err)
+		}
+		return nil, apierror.Prefix(eventAlreadyExists(batchErr.Name), fmt.Sprintf("requests[%d]", indexes[batchErr.Index]))
+	}
+
+	// Some events already exist: fall back to storing the events one by one
+	// to find out which.
+	for j, event := range events {
+		err := s.store.CreateEvent(ctx, event)
+		switch {
+		case errors.Is(err, ErrAlreadyExists):
+			err = eventAlreadyExists(event.GetName())
+		case err != nil:
+			err = status.Errorf(codes.Internal, "failed to store event: %v", err)
+		default:
+			created = append(created, event)
+			continue
+		}
+		failures = append(failures, batchFailure(indexes[j], err))
+	}
+	s.updateRollups(ctx, createdRollups(created))
+	slices.SortFunc(failures, func(a, b *usagev1.BatchCreateEventsResponse_Failure) int {
+		return int(a.GetIndex() - b.GetIndex())
+	})
+	return &usagev1.BatchCreateEventsResponse{Events: created, Failures: failures}, nil
+}
+
+// createdRollups returns the rollups of created events.
+func createdRollups(events []*usagev1.Event) []*Rollup {
+	var rollups []*Rollup
+	for _, event := range events {
+		rollups = append(rollups, eventRollups(event, 1)...)
+	}
+	return rollups
+}
+
+func batchFailure(index int, err error) *usagev1.BatchCreateEventsResponse_Failure {
+	return &usagev1.BatchCreateEventsResponse_Failure{
+		Index:  int32(index),
+		Status: status.Convert(err).Proto(),
+	}
+}
+
+// batchCreateEventsFingerprint identifies the payload of a BatchCreateEvents
+// request.
+func batchCreateEventsFingerprint(req *usagev1.BatchCreateEventsRequest) []byte {
+	return requestFingerprint(req, func(m proto.Message) {
+		m.(*usagev1.BatchCreateEventsRequest).RequestId = ""
+	})
+}
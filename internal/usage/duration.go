@@ -0,0 +1,131 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// DefaultDurationBounds are the allowed execution durations of events when
+// no DurationLimits are configured for their source and action.
+var DefaultDurationBounds = DurationBounds{Min: 0, Max: 24 * time.Hour}
+
+// DurationBounds is an inclusive range of execution durations. A zero Max
+// means that there is no upper bound.
+type DurationBounds struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (b DurationBounds) contains(d time.Duration) bool {
+	return d >= b.Min && (b.Max == 0 || d <= b.Max)
+}
+
+func (b DurationBounds) String() string {
+	if b.Max == 0 {
+		return fmt.Sprintf("[%v, ∞)", b.Min)
+	}
+	return fmt.Sprintf("[%v, %v]", b.Min, b.Max)
+}
+
+// DurationLimits configures the allowed execution durations of events per
+// source and action.
+type DurationLimits struct {
+	// Default applies to events that no rule matches.
+	Default DurationBounds
+	// Rules are keyed by "source/action", or by "source" for all actions of
+	// a source. The more specific key wins.
+	Rules map[string]DurationBounds
+}
+
+// ParseDurationLimits parses a comma-separated list of duration limits such
+// as `0s..1h,animal-classifier=10ms..5m,animal-classifier/train=..24h`. Each
+// entry is a range of Go durations with optional ends, keyed by source or
+// source/action; an entry without a key sets the default, which is
+// DefaultDurationBounds otherwise. The lower end defaults to zero and the
+// upper end to no limit.
+func ParseDurationLimits(s string) (DurationLimits, error) {
+	limits := DurationLimits{Default: DefaultDurationBounds, Rules: make(map[string]DurationBounds)}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, bounds, ok := strings.Cut(entry, "=")
+		if !ok {
+			key, bounds = "", entry
+		}
+		b, err := parseDurationBounds(bounds)
+		if err != nil {
+			return DurationLimits{}, fmt.Errorf("invalid duration limit %q: %w", entry, err)
+		}
+		if key == "" {
+			limits.Default = b
+		} else {
+			limits.Rules[key] = b
+		}
+	}
+	return limits, nil
+}
+
+func parseDurationBounds(s string) (DurationBounds, error) {
+	lo, hi, ok := strings.Cut(s, "..")
+	if !ok {
+		return DurationBounds{}, fmt.Errorf("expected a range such as 0s..1h")
+	}
+	var b DurationBounds
+	var err error
+	if lo != "" {
+		if b.Min, err = time.ParseDuration(lo); err != nil {
+			return DurationBounds{}, err
+		}
+	}
+	if hi != "" {
+		if b.Max, err = time.ParseDuration(hi); err != nil {
+			return DurationBounds{}, err
+		}
+		if b.Max <= 0 || b.Max < b.Min {
+			return DurationBounds{}, fmt.Errorf("upper end must be positive and not below the lower end")
+		}
+	}
+	if b.Min < 0 {
+		return DurationBounds{}, fmt.Errorf("lower end must not be negative")
+	}
+	return b, nil
+}
+
+// bounds returns the allowed execution durations of events with the given
+// source and action.
+func (l DurationLimits) bounds(source, action string) DurationBounds {
+	if b, ok := l.Rules[source+"/"+action]; ok {
+		return b
+	}
+	if b, ok := l.Rules[source]; ok {
+		return b
+	}
+	return l.Default
+}
+
+// validate returns the violations of the execution duration of event, whose
+// path in the request is given by field. A missing duration is left to the
+// field behavior checks.
+func (l DurationLimits) validate(field string, event *usagev1.Event) []apierror.FieldViolation {
+	duration := event.GetExecutionDuration()
+	if duration == nil {
+		return nil
+	}
+	if err := duration.CheckValid(); err != nil {
+		return []apierror.FieldViolation{invalidField(field, ReasonInvalidExecutionDuration,
+			fmt.Errorf("invalid execution_duration: %w", err))}
+	}
+	d := duration.AsDuration()
+	if b := l.bounds(event.GetSource(), event.GetAction()); !b.contains(d) {
+		return []apierror.FieldViolation{invalidField(field, ReasonExecutionDurationOutOfRange,
+			fmt.Errorf("execution_duration %v is outside of %v allowed for source %q and action %q",
+				d, b, event.GetSource(), event.GetAction()))}
+	}
+	return nil
+}
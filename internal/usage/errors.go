@@ -0,0 +1,126 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// Reasons of the ErrorInfo and BadRequest details of errors returned by the
+// services. They are part of the API: clients may rely on them, so they must
+// not change.
+const (
+	ReasonInvalidEventName = "INVALID_EVENT_NAME"
+	ReasonInvalidEventID   = "INVALID_EVENT_ID"
+	ReasonInvalidSubject   = "INVALID_SUBJECT"
+	ReasonInvalidLabel     = "INVALID_LABEL"
+	ReasonTooManyLabels    = "TOO_MANY_LABELS"
+
+	ReasonSourceNotRegistered = "SOURCE_NOT_REGISTERED"
+	ReasonActionNotRegistered = "ACTION_NOT_REGISTERED"
+
+	ReasonInvalidExecutionDuration    = "INVALID_EXECUTION_DURATION"
+	ReasonExecutionDurationOutOfRange = "EXECUTION_DURATION_OUT_OF_RANGE"
+
+	ReasonInvalidRequestID   = "INVALID_REQUEST_ID"
+	ReasonInvalidFilter      = "INVALID_FILTER"
+	ReasonInvalidOrderBy     = "INVALID_ORDER_BY"
+	ReasonInvalidPageToken   = "INVALID_PAGE_TOKEN"
+	ReasonInvalidResumeToken = "INVALID_RESUME_TOKEN"
+	ReasonInvalidUpdateMask  = "INVALID_UPDATE_MASK"
+	ReasonBatchTooLarge      = "BATCH_TOO_LARGE"
+
+	ReasonInvalidGroupBy    = "INVALID_GROUP_BY"
+	ReasonInvalidTimeBucket = "INVALID_TIME_BUCKET"
+	ReasonInvalidTimeZone   = "INVALID_TIME_ZONE"
+	ReasonTooManyGroups     = "TOO_MANY_GROUPS"
+
+	ReasonInvalidSourceName = "INVALID_SOURCE_NAME"
+	ReasonInvalidSourceID   = "INVALID_SOURCE_ID"
+	ReasonInvalidAction     = "INVALID_ACTION"
+
+	ReasonInvalidParent           = "INVALID_PARENT"
+	ReasonInvalidPriceName        = "INVALID_PRICE_NAME"
+	ReasonInvalidPriceID          = "INVALID_PRICE_ID"
+	ReasonInvalidEffectiveTime    = "INVALID_EFFECTIVE_TIME"
+	ReasonInvalidAmount           = "INVALID_AMOUNT"
+	ReasonInvalidBillingIncrement = "INVALID_BILLING_INCREMENT"
+
+	ReasonEventNotFound          = "EVENT_NOT_FOUND"
+	ReasonEventAlreadyExists     = "EVENT_ALREADY_EXISTS"
+	ReasonEventDeleted           = "EVENT_DELETED"
+	ReasonEventNotDeleted        = "EVENT_NOT_DELETED"
+	ReasonEtagMismatch           = "ETAG_MISMATCH"
+	ReasonConcurrentModification = "CONCURRENT_MODIFICATION"
+	ReasonRequestIDReused        = "REQUEST_ID_REUSED"
+	ReasonWatcherTooSlow         = "WATCHER_TOO_SLOW"
+
+	ReasonSourceNotFound      = "SOURCE_NOT_FOUND"
+	ReasonSourceAlreadyExists = "SOURCE_ALREADY_EXISTS"
+	ReasonActionNotFound      = "ACTION_NOT_FOUND"
+
+	ReasonPriceNotFound      = "PRICE_NOT_FOUND"
+	ReasonPriceAlreadyExists = "PRICE_ALREADY_EXISTS"
+	ReasonPricesOverlap      = "PRICES_OVERLAP"
+
+	ReasonQuotaExceeded = "QUOTA_EXCEEDED"
+)
+
+// invalidField returns a violation of field described by err.
+func invalidField(field, reason string, err error) apierror.FieldViolation {
+	return apierror.FieldViolation{Field: field, Reason: reason, Description: err.Error()}
+}
+
+// required returns the violation of a required field that is not set.
+func required(field string) apierror.FieldViolation {
+	return apierror.FieldViolation{
+		Field:       field,
+		Reason:      fieldbehavior.ReasonRequired,
+		Description: field + " is required",
+	}
+}
+
+// joinPath returns the path of field in the message at path prefix, which
+// is empty for the request itself.
+func joinPath(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+// invalidArgument returns an INVALID_ARGUMENT error reporting a single
+// violation of field.
+func invalidArgument(field, reason string, err error) error {
+	return apierror.BadRequest(invalidField(field, reason, err))
+}
+
+func eventNotFound(name string) error {
+	return apierror.New(codes.NotFound, ReasonEventNotFound,
+		fmt.Sprintf("event %q not found", name), map[string]string{"name": name})
+}
+
+func eventAlreadyExists(name string) error {
+	return apierror.New(codes.AlreadyExists, ReasonEventAlreadyExists,
+		fmt.Sprintf("event %q already exists", name), map[string]string{"name": name})
+}
+
+func requestIDReused(requestID string) error {
+	return apierror.New(codes.AlreadyExists, ReasonRequestIDReused,
+		fmt.Sprintf("request_id %q was already used with a different payload", requestID),
+		map[string]string{"requestId": requestID})
+}
+
+func quotaExceeded(subject string, violations []apierror.QuotaViolation) error {
+	descriptions := make([]string, len(violations))
+	for i, v := range violations {
+		descriptions[i] = v.Description
+	}
+	return apierror.ResourceExhausted(ReasonQuotaExceeded,
+		fmt.Sprintf("%s: %s", subject, strings.Join(descriptions, "; ")),
+		map[string]string{"subject": subject, "quotaId": violations[0].QuotaID}, violations...)
+}
@@ -0,0 +1,229 @@
+package usage
+
+import (
+	"cmp"
+	"strings"
+	"time"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage/filter"
+)
+
+// Predicate reports whether an event matches a filter.
+type Predicate func(event *usagev1.Event) bool
+
+// compileFilter parses an AIP-160 filter and compiles it into a predicate
+// over events. An empty filter yields a nil predicate, which matches
+// everything. Errors are *filter.Error values pointing at the offending token.
+func compileFilter(input string) (Predicate, error) {
+	expr, err := filter.Parse(input)
+	if err != nil || expr == nil {
+		return nil, err
+	}
+	return compileExpr(expr)
+}
+
+func compileExpr(expr filter.Expr) (Predicate, error) {
+	switch e := expr.(type) {
+	case *filter.And:
+		args, err := compileArgs(e.Args)
+		if err != nil {
+			return nil, err
+		}
+		return func(event *usagev1.Event) bool {
+			for _, arg := range args {
+				if !arg(event) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case *filter.Or:
+		args, err := compileArgs(e.Args)
+		if err != nil {
+			return nil, err
+		}
+		return func(event *usagev1.Event) bool {
+			for _, arg := range args {
+				if arg(event) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case *filter.Not:
+		arg, err := compileExpr(e.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(event *usagev1.Event) bool {
+			return !arg(event)
+		}, nil
+	case *filter.Restriction:
+		return compileRestriction(e)
+	default:
+		return nil, filter.Errorf(expr.Pos(), "", "unsupported expression")
+	}
+}
+
+func compileArgs(exprs []filter.Expr) ([]Predicate, error) {
+	args := make([]Predicate, len(exprs))
+	for i, expr := range exprs {
+		arg, err := compileExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// stringFields are the filterable string fields of an Event.
+var stringFields = map[string]func(*usagev1.Event) string{
+	FieldName:    (*usagev1.Event).GetName,
+	FieldSubject: (*usagev1.Event).GetSubject,
+	FieldSource:  (*usagev1.Event).GetSource,
+	FieldAction:  (*usagev1.Event).GetAction,
+}
+
+func compileRestriction(r *filter.Restriction) (Predicate, error) {
+	if r.Field == FieldLabels {
+		return compileLabelsRestriction(r)
+	}
+	if key, ok := strings.CutPrefix(r.Field, FieldLabels+"."); ok {
+		return compileLabelRestriction(key, r)
+	}
+
+	if r.Comparator == filter.Has {
+		return nil, filter.Errorf(r.ComparatorPos, string(r.Comparator),
+			"operator not supported on field %q", r.Field)
+	}
+
+	if get, ok := stringFields[r.Field]; ok {
+		if r.Comparator == filter.Equals || r.Comparator == filter.NotEquals {
+			match := wildcardMatcher(r.Value.Text)
+			want := r.Comparator == filter.Equals
+			return func(event *usagev1.Event) bool {
+				return match(get(event)) == want
+			}, nil
+		}
+		return ordered(r.Comparator, get, r.Value.Text, strings.Compare), nil
+	}
+
+	switch r.Field {
+	case FieldCreateTime:
+		t, err := time.Parse(time.RFC3339Nano, r.Value.Text)
+		if err != nil {
+			return nil, filter.Errorf(r.Value.Pos, r.Value.Text,
+				`invalid timestamp, expected a quoted RFC 3339 value such as "2025-01-02T15:04:05Z"`)
+		}
+		return ordered(r.Comparator, func(event *usagev1.Event) time.Time {
+			return event.GetCreateTime().AsTime()
+		}, t, time.Time.Compare), nil
+	case FieldExecutionDuration:
+		d, err := time.ParseDuration(r.Value.Text)
+		if err != nil {
+			return nil, filter.Errorf(r.Value.Pos, r.Value.Text,
+				"invalid duration, expected a value such as 1.5s or 200ms")
+		}
+		return ordered(r.Comparator, func(event *usagev1.Event) time.Duration {
+			return event.GetExecutionDuration().AsDuration()
+		}, d, cmp.Compare[time.Duration]), nil
+	default:
+		return nil, filter.Errorf(r.FieldPos, r.Field, "unknown field")
+	}
+}
+
+// compileLabelsRestriction compiles `labels:key`, which matches events that
+// have a label with the given key.
+func compileLabelsRestriction(r *filter.Restriction) (Predicate, error) {
+	if r.Comparator != filter.Has {
+		return nil, filter.Errorf(r.ComparatorPos, string(r.Comparator),
+			"operator not supported on field %q, compare labels.<key> instead", r.Field)
+	}
+	key := r.Value.Text
+	return func(event *usagev1.Event) bool {
+		_, ok := event.GetLabels()[key]
+		return ok
+	}, nil
+}
+
+// compileLabelRestriction compiles a restriction on the label with the given
+// key, e.g. `labels.region = "us-east1"`. `labels.key:*` matches events that
+// have the label; comparisons, like those of string fields, only match events
+// that have it.
+func compileLabelRestriction(key string, r *filter.Restriction) (Predicate, error) {
+	if r.Comparator == filter.Has {
+		if r.Value.Text != "*" || r.Value.Quoted {
+			return nil, filter.Errorf(r.Value.Pos, r.Value.Text,
+				"only presence tests such as %s:* are supported on labels", r.Field)
+		}
+		return func(event *usagev1.Event) bool {
+			_, ok := event.GetLabels()[key]
+			return ok
+		}, nil
+	}
+
+	var compare Predicate
+	if r.Comparator == filter.Equals || r.Comparator == filter.NotEquals {
+		match := wildcardMatcher(r.Value.Text)
+		want := r.Comparator == filter.Equals
+		compare = func(event *usagev1.Event) bool {
+			return match(event.GetLabels()[key]) == want
+		}
+	} else {
+		compare = ordered(r.Comparator, func(event *usagev1.Event) string {
+			return event.GetLabels()[key]
+		}, r.Value.Text, strings.Compare)
+	}
+	return func(event *usagev1.Event) bool {
+		_, ok := event.GetLabels()[key]
+		return ok && compare(event)
+	}, nil
+}
+
+// ordered compiles a comparison of a field against a constant.
+func ordered[T any](op filter.Comparator, get func(*usagev1.Event) T, value T, compare func(a, b T) int) Predicate {
+	return func(event *usagev1.Event) bool {
+		c := compare(get(event), value)
+		switch op {
+		case filter.Equals:
+			return c == 0
+		case filter.NotEquals:
+			return c != 0
+		case filter.Less:
+			return c < 0
+		case filter.LessEquals:
+			return c <= 0
+		case filter.Greater:
+			return c > 0
+		case filter.GreaterEquals:
+			return c >= 0
+		default:
+			return false
+		}
+	}
+}
+
+// wildcardMatcher returns a function matching strings against pattern, in
+// which `*` stands for any sequence of characters.
+func wildcardMatcher(pattern string) func(string) bool {
+	if !strings.Contains(pattern, "*") {
+		return func(s string) bool { return s == pattern }
+	}
+	parts := strings.Split(pattern, "*")
+	return func(s string) bool {
+		if !strings.HasPrefix(s, parts[0]) {
+			return false
+		}
+		s = s[len(parts[0]):]
+		for _, part := range parts[1 : len(parts)-1] {
+			i := strings.Index(s, part)
+			if i < 0 {
+				return false
+			}
+			s = s[i+len(part):]
+		}
+		return strings.HasSuffix(s, parts[len(parts)-1])
+	}
+}
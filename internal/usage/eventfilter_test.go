@@ -0,0 +1,168 @@
+package usage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage/filter"
+)
+
+func TestCompileFilter(t *testing.T) {
+	event := &usagev1.Event{
+		Name:              "events/e1",
+		Subject:           "users/alice",
+		Source:            "animal-classifier",
+		Action:            "classify",
+		ExecutionDuration: durationpb.New(1500 * time.Millisecond),
+		Labels:            map[string]string{"region": "us-east1", "model": "v2", "empty": ""},
+		CreateTime:        timestamppb.New(time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)),
+	}
+
+	for _, tc := range []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{`action = "classify"`, true},
+		{"action = classify", true},
+		{`action != "classify"`, false},
+		{`subject = "users/*"`, true},
+		{`subject = "*/bob"`, false},
+		{`source = "animal-*-classifier"`, false},
+		{`source = "animal-*"`, true},
+		{`source != "*classifier"`, false},
+		{`action < "detect"`, true},
+		{`action >= "detect"`, false},
+		{`name = "events/e1"`, true},
+
+		// Precedence: OR binds tighter than AND
+		{`action = "detect" OR action = "classify" AND subject = "users/bob"`, false},
+		{`action = "detect" OR (action = "classify" AND subject = "users/alice")`, true},
+		{`action = "classify" subject = "users/alice"`, true},
+		{`action = "classify" subject = "users/bob" OR source = "animal-classifier"`, true},
+
+		// NOT and -
+		{`NOT action = "classify"`, false},
+		{`NOT action = "detect"`, true},
+		{`-action = "detect"`, true},
+		{`NOT (action = "detect" OR subject = "users/alice")`, false},
+		{`NOT action = "detect" AND NOT subject = "users/bob"`, true},
+
+		// `:` tests for the presence of labels
+		{"labels:region", true},
+		{"labels:zone", false},
+		{`labels:"region"`, true},
+		{"labels.region:*", true},
+		{"labels.zone:*", false},
+		{"labels:empty", true},
+		{"-labels:zone", true},
+
+		// Label comparisons only match events that have the label
+		{`labels.region = "us-east1"`, true},
+		{`labels.region = "us-*"`, true},
+		{`labels.region != "us-east1"`, false},
+		{`labels.zone != "a"`, false},
+		{`labels.zone = "*"`, false},
+		{`labels.empty = ""`, true},
+		{`labels.model > "v1"`, true},
+		{`labels.zone < "z"`, false},
+
+		// Timestamps
+		{`create_time = "2025-01-02T15:04:05Z"`, true},
+		{`create_time = "2025-01-02T16:04:05+01:00"`, true},
+		{`create_time > "2025-01-02T15:04:04.999999999Z"`, true},
+		{`create_time > "2025-01-02T15:04:05Z"`, false},
+		{`create_time >= "2025-01-02T15:04:05Z"`, true},
+		{`create_time < "2025-01-03T00:00:00Z"`, true},
+		{`create_time <= "2025-01-02T15:04:04Z"`, false},
+		{`create_time != "2025-01-02T15:04:05Z"`, false},
+
+		// Durations
+		{"execution_duration = 1.5s", true},
+		{"execution_duration = 1500ms", true},
+		{"execution_duration > 1s", true},
+		{"execution_duration > 1.5s", false},
+		{"execution_duration >= 1.5s", true},
+		{"execution_duration < 2s", true},
+		{"execution_duration <= 1499ms", false},
+		{`execution_duration != "1.5s"`, false},
+		{"execution_duration > 1s AND execution_duration < 2s", true},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			predicate, err := compileFilter(tc.filter)
+			if err != nil {
+				t.Fatalf("compileFilter(%q) error = %v", tc.filter, err)
+			}
+			got := predicate == nil || predicate(event)
+			if got != tc.want {
+				t.Errorf("compileFilter(%q) matches = %v, want %v", tc.filter, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	for _, tc := range []struct {
+		filter string
+		pos    int
+		token  string
+		msg    string
+	}{
+		{`color = "red"`, 0, "color", "unknown field"},
+		{`action = "a" AND labelz.x = "y"`, 17, "labelz.x", "unknown field"},
+		{`action:classify`, 6, ":", "operator not supported"},
+		{`labels = "x"`, 7, "=", "operator not supported"},
+		{`labels.region:"us-east1"`, 14, "us-east1", "only presence tests"},
+		{`labels.region:us`, 14, "us", "only presence tests"},
+		{`labels.region:"*"`, 14, "*", "only presence tests"},
+		{`create_time > "yesterday"`, 14, "yesterday", "invalid timestamp"},
+		{`create_time > "2025-01-02"`, 14, "2025-01-02", "invalid timestamp"},
+		{`execution_duration > 1.5`, 21, "1.5", "invalid duration"},
+		{`execution_duration > fast`, 21, "fast", "invalid duration"},
+		{`NOT (action = "a" OR execution_duration < 1x)`, 42, "1x", "invalid duration"},
+		{`action = `, 9, "", "expected value"},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			_, err := compileFilter(tc.filter)
+			var ferr *filter.Error
+			if !errors.As(err, &ferr) {
+				t.Fatalf("compileFilter(%q) error = %v, want *filter.Error", tc.filter, err)
+			}
+			if ferr.Pos != tc.pos || ferr.Token != tc.token || !strings.HasPrefix(ferr.Msg, tc.msg) {
+				t.Errorf("compileFilter(%q) error = {Pos: %d, Token: %q, Msg: %q}, want {Pos: %d, Token: %q, Msg: %q...}",
+					tc.filter, ferr.Pos, ferr.Token, ferr.Msg, tc.pos, tc.token, tc.msg)
+			}
+		})
+	}
+}
+
+func TestWildcardMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"users/alice", "users/alice", true},
+		{"users/alice", "users/alice2", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"users/*", "users/", true},
+		{"users/*", "user/alice", false},
+		{"*/alice", "users/alice", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "acb", false},
+		{"a*a", "a", false},
+		{"a*a", "aa", true},
+		{"**", "x", true},
+	} {
+		if got := wildcardMatcher(tc.pattern)(tc.s); got != tc.want {
+			t.Errorf("wildcardMatcher(%q)(%q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package filter parses the subset of the AIP-160 filtering language
+// supported by the usage API into an abstract syntax tree.
+//
+// The supported grammar is:
+//
+//	expression = sequence { "AND" sequence }
+//	sequence   = factor { factor }
+//	factor     = term { "OR" term }
+//	term       = [ "NOT" | "-" ] simple
+//	simple     = "(" expression ")" | restriction
+//	restriction = field comparator value
+//	comparator = "=" | "!=" | "<" | "<=" | ">" | ">=" | ":"
+//
+// As in AIP-160, OR binds tighter than AND, and a sequence of terms separated
+// only by whitespace is an implicit AND. Values are either double-quoted
+// strings or bare words such as `classify`, `20` or `1.5s`.
+package filter
+
+import "fmt"
+
+// Expr is a node of the filter syntax tree.
+type Expr interface {
+	// Pos returns the byte offset of the expression in the filter string.
+	Pos() int
+}
+
+// And is satisfied when all of its arguments are.
+type And struct {
+	Args []Expr
+}
+
+// Or is satisfied when any of its arguments is.
+type Or struct {
+	Args []Expr
+}
+
+// Not negates its argument.
+type Not struct {
+	Arg Expr
+	// OpPos is the offset of the NOT keyword or `-` prefix.
+	OpPos int
+}
+
+// Comparator is a restriction operator.
+type Comparator string
+
+// Supported comparators.
+const (
+	Equals        Comparator = "="
+	NotEquals     Comparator = "!="
+	Less          Comparator = "<"
+	LessEquals    Comparator = "<="
+	Greater       Comparator = ">"
+	GreaterEquals Comparator = ">="
+	Has           Comparator = ":"
+)
+
+// Restriction compares a field with a value, e.g. `action = "classify"`.
+type Restriction struct {
+	// Field is the dot-separated field path on the left-hand side.
+	Field         string
+	FieldPos      int
+	Comparator    Comparator
+	ComparatorPos int
+	Value         Value
+}
+
+// Value is the literal right-hand side of a restriction.
+type Value struct {
+	// Text is the value with quotes removed and escapes resolved.
+	Text string
+	// Quoted reports whether the value was a quoted string.
+	Quoted bool
+	Pos    int
+}
+
+// Pos implements Expr.
+func (e *And) Pos() int { return e.Args[0].Pos() }
+
+// Pos implements Expr.
+func (e *Or) Pos() int { return e.Args[0].Pos() }
+
+// Pos implements Expr.
+func (e *Not) Pos() int { return e.OpPos }
+
+// Pos implements Expr.
+func (e *Restriction) Pos() int { return e.FieldPos }
+
+// Error is a syntax or semantic error in a filter, pointing at the offending
+// token.
+type Error struct {
+	// Pos is the byte offset of the offending token in the filter string.
+	Pos int
+	// Token is the offending token, empty at the end of the input.
+	Token string
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at end of filter", e.Msg)
+	}
+	return fmt.Sprintf("%s: %q at position %d", e.Msg, e.Token, e.Pos)
+}
+
+// Errorf returns an Error pointing at token, which starts at offset pos.
+func Errorf(pos int, token string, format string, args ...any) *Error {
+	return &Error{Pos: pos, Token: token, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,274 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenText
+	tokenString
+	tokenComparator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string // raw token text; unquoted content for strings
+	pos  int
+}
+
+// lex splits the filter into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			text, n, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i += n
+		case strings.ContainsRune("=!<>:", r):
+			n := 1
+			if i+1 < len(input) && input[i+1] == '=' && r != '=' && r != ':' {
+				n = 2
+			}
+			op := input[i : i+n]
+			if op == "!" {
+				return nil, Errorf(i, op, "unknown operator")
+			}
+			tokens = append(tokens, token{kind: tokenComparator, text: op, pos: i})
+			i += n
+		default:
+			start := i
+			for i < len(input) {
+				r, size := utf8.DecodeRuneInString(input[i:])
+				if unicode.IsSpace(r) || strings.ContainsRune("()\"'=!<>:", r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenText, text: input[start:i], pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexString reads the quoted string starting at input[start] and returns its
+// unescaped content and its length in the input.
+func lexString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var b strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		switch c := input[i]; c {
+		case quote:
+			return b.String(), i + 1 - start, nil
+		case '\\':
+			if i+1 == len(input) {
+				break
+			}
+			i++
+			b.WriteByte(input[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, Errorf(start, input[start:], "unterminated string")
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+// Parse parses a filter string. An empty or blank filter yields a nil
+// expression.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, Errorf(tok.pos, tok.text, "unexpected token")
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenText && tok.text == keyword
+}
+
+// expression = sequence { "AND" sequence }
+func (p *parser) expression() (Expr, error) {
+	var args []Expr
+	for {
+		seq, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, seq)
+		if !p.isKeyword("AND") {
+			break
+		}
+		p.advance()
+	}
+	return and(args), nil
+}
+
+// sequence = factor { factor }
+func (p *parser) sequence() (Expr, error) {
+	var args []Expr
+	for {
+		factor, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, factor)
+
+		tok := p.peek()
+		if tok.kind == tokenEOF || tok.kind == tokenRParen || p.isKeyword("AND") {
+			break
+		}
+	}
+	return and(args), nil
+}
+
+// factor = term { "OR" term }
+func (p *parser) factor() (Expr, error) {
+	var args []Expr
+	for {
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, term)
+		if !p.isKeyword("OR") {
+			break
+		}
+		p.advance()
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &Or{Args: args}, nil
+}
+
+// term = [ "NOT" | "-" ] simple
+func (p *parser) term() (Expr, error) {
+	tok := p.peek()
+	if p.isKeyword("NOT") {
+		p.advance()
+		arg, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Arg: arg, OpPos: tok.pos}, nil
+	}
+	if tok.kind == tokenText && len(tok.text) > 1 && tok.text[0] == '-' {
+		// Split the `-` prefix off the field name
+		p.tokens[p.next] = token{kind: tokenText, text: tok.text[1:], pos: tok.pos + 1}
+		arg, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Arg: arg, OpPos: tok.pos}, nil
+	}
+	return p.simple()
+}
+
+// simple = "(" expression ")" | restriction
+func (p *parser) simple() (Expr, error) {
+	tok := p.peek()
+	if tok.kind == tokenLParen {
+		p.advance()
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokenRParen {
+			return nil, Errorf(closing.pos, closing.text, "expected closing parenthesis")
+		}
+		p.advance()
+		return expr, nil
+	}
+	return p.restriction()
+}
+
+// restriction = field comparator value
+func (p *parser) restriction() (Expr, error) {
+	field := p.advance()
+	if field.kind != tokenText || isReserved(field.text) {
+		return nil, Errorf(field.pos, field.text, "expected field name")
+	}
+
+	op := p.advance()
+	if op.kind != tokenComparator {
+		return nil, Errorf(op.pos, op.text, "expected comparator after field %q", field.text)
+	}
+
+	value := p.advance()
+	if value.kind != tokenText && value.kind != tokenString || value.kind == tokenText && isReserved(value.text) {
+		return nil, Errorf(value.pos, value.text, "expected value after %q", op.text)
+	}
+
+	return &Restriction{
+		Field:         field.text,
+		FieldPos:      field.pos,
+		Comparator:    Comparator(op.text),
+		ComparatorPos: op.pos,
+		Value: Value{
+			Text:   value.text,
+			Quoted: value.kind == tokenString,
+			Pos:    value.pos,
+		},
+	}, nil
+}
+
+func isReserved(text string) bool {
+	return text == "AND" || text == "OR" || text == "NOT"
+}
+
+func and(args []Expr) Expr {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return &And{Args: args}
+}
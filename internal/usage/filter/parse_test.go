@@ -0,0 +1,175 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// format renders an expression as an S-expression, e.g.
+// `(AND a="x" (NOT b:y))`, with quoted values in double quotes.
+func format(expr Expr) string {
+	switch e := expr.(type) {
+	case nil:
+		return "<nil>"
+	case *And:
+		return "(AND " + formatArgs(e.Args) + ")"
+	case *Or:
+		return "(OR " + formatArgs(e.Args) + ")"
+	case *Not:
+		return "(NOT " + format(e.Arg) + ")"
+	case *Restriction:
+		value := e.Value.Text
+		if e.Value.Quoted {
+			value = fmt.Sprintf("%q", value)
+		}
+		return e.Field + string(e.Comparator) + value
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+func formatArgs(args []Expr) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = format(arg)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"", "<nil>"},
+		{"   ", "<nil>"},
+		{`action = "classify"`, `action="classify"`},
+		{"action=classify", "action=classify"},
+		{"execution_duration >= 1.5s", "execution_duration>=1.5s"},
+		{"a != b", "a!=b"},
+		{"a < b", "a<b"},
+		{"a <= b", "a<=b"},
+		{"a > b", "a>b"},
+		{"labels:region", "labels:region"},
+		{"labels.region:*", "labels.region:*"},
+
+		// OR binds tighter than AND, and whitespace is an implicit AND
+		{"a=1 AND b=2 OR c=3", "(AND a=1 (OR b=2 c=3))"},
+		{"a=1 OR b=2 AND c=3", "(AND (OR a=1 b=2) c=3)"},
+		{"a=1 OR b=2 OR c=3", "(OR a=1 b=2 c=3)"},
+		{"a=1 b=2 OR c=3", "(AND a=1 (OR b=2 c=3))"},
+		{"a=1 b=2 AND c=3", "(AND (AND a=1 b=2) c=3)"},
+		{"(a=1 AND b=2) OR c=3", "(OR (AND a=1 b=2) c=3)"},
+		{"((a=1))", "a=1"},
+
+		// NOT and `-` apply to the simple expression that follows
+		{"NOT a=1", "(NOT a=1)"},
+		{"-a=1", "(NOT a=1)"},
+		{"NOT a=1 OR b=2", "(OR (NOT a=1) b=2)"},
+		{"NOT (a=1 OR b=2)", "(NOT (OR a=1 b=2))"},
+		{"a=1 AND -labels:gpu", "(AND a=1 (NOT labels:gpu))"},
+		{"a=-1", "a=-1"},
+
+		// Quoted values keep keywords, operators and escapes as text
+		{`a = "AND"`, `a="AND"`},
+		{`a = "x OR y"`, `a="x OR y"`},
+		{`a = 'single'`, `a="single"`},
+		{`a = "say \"hi\""`, `a="say \"hi\""`},
+		{`a = "back\\slash"`, `a="back\\slash"`},
+		{`create_time > "2025-01-02T15:04:05Z"`, `create_time>"2025-01-02T15:04:05Z"`},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			expr, err := Parse(tc.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tc.input, err)
+			}
+			if got := format(expr); got != tc.want {
+				t.Errorf("Parse(%q) = %s, want %s", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	expr, err := Parse(`a = 1 AND NOT labels.x != "y"`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	and := expr.(*And)
+	first := and.Args[0].(*Restriction)
+	if first.FieldPos != 0 || first.ComparatorPos != 2 || first.Value.Pos != 4 {
+		t.Errorf("first restriction positions = %d, %d, %d, want 0, 2, 4",
+			first.FieldPos, first.ComparatorPos, first.Value.Pos)
+	}
+	not := and.Args[1].(*Not)
+	if not.Pos() != 10 {
+		t.Errorf("NOT position = %d, want 10", not.Pos())
+	}
+	second := not.Arg.(*Restriction)
+	if second.FieldPos != 14 || second.ComparatorPos != 23 || second.Value.Pos != 26 {
+		t.Errorf("second restriction positions = %d, %d, %d, want 14, 23, 26",
+			second.FieldPos, second.ComparatorPos, second.Value.Pos)
+	}
+
+	expr, err = Parse("-a=1")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if not := expr.(*Not); not.OpPos != 0 || not.Arg.Pos() != 1 {
+		t.Errorf("- positions = %d, %d, want 0, 1", not.OpPos, not.Arg.Pos())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		pos   int
+		token string
+		msg   string
+	}{
+		{"a", 1, "", "expected comparator"},
+		{"a =", 3, "", "expected value"},
+		{"a = b c", 7, "", "expected comparator"},
+		{"= b", 0, "=", "expected field name"},
+		{"a == b", 3, "=", "expected value"},
+		{"a ! b", 2, "!", "unknown operator"},
+		{"AND a = b", 0, "AND", "expected field name"},
+		{"a = b AND", 9, "", "expected field name"},
+		{"a = b OR", 8, "", "expected field name"},
+		{"a = AND", 4, "AND", "expected value"},
+		{"NOT", 3, "", "expected field name"},
+		{"(a = b", 6, "", "expected closing parenthesis"},
+		{"a = b)", 5, ")", "unexpected token"},
+		{"()", 1, ")", "expected field name"},
+		{`a = "open`, 4, `"open`, "unterminated string"},
+		{`a = "escaped\"`, 4, `"escaped\"`, "unterminated string"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := Parse(tc.input)
+			var ferr *Error
+			if !errors.As(err, &ferr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tc.input, err)
+			}
+			if ferr.Pos != tc.pos || ferr.Token != tc.token || !strings.HasPrefix(ferr.Msg, tc.msg) {
+				t.Errorf("Parse(%q) error = {Pos: %d, Token: %q, Msg: %q}, want {Pos: %d, Token: %q, Msg: %q...}",
+					tc.input, ferr.Pos, ferr.Token, ferr.Msg, tc.pos, tc.token, tc.msg)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	for _, tc := range []struct {
+		err  *Error
+		want string
+	}{
+		{Errorf(4, "AND", "expected value after %q", "="), `expected value after "=": "AND" at position 4`},
+		{Errorf(3, "", "expected value"), "expected value at end of filter"},
+	} {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("Error() = %q, want %q", got, tc.want)
+		}
+	}
+}
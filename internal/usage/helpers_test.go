@@ -0,0 +1,104 @@
+package usage
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// newTestService returns a service on an empty memory store that accepts
+// events of any source.
+func newTestService(t *testing.T, cfg Config) *Service {
+	t.Helper()
+	return newTestServiceWithStore(t, NewMemoryStore(), cfg)
+}
+
+// newTestServiceWithStore returns a service on store that accepts events of
+// any source.
+func newTestServiceWithStore(t *testing.T, store Store, cfg Config) *Service {
+	t.Helper()
+	cfg.PermissiveSources = true
+	s, err := NewService(store, cfg)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	return s
+}
+
+// testStores open the empty stores that service tests run against, by kind.
+// Stores of other packages cannot be imported here, as they import this
+// package; their tests add them with RegisterTestStore.
+var testStores = map[string]func(t *testing.T) Store{
+	"memory": func(*testing.T) Store { return NewMemoryStore() },
+}
+
+// RegisterTestStore adds a kind of store for the service tests to run
+// against. It must be called from an init function.
+func RegisterTestStore(kind string, open func(t *testing.T) Store) {
+	testStores[kind] = open
+}
+
+// runServiceTest runs test against a service on each kind of test store.
+func runServiceTest(t *testing.T, cfg Config, test func(t *testing.T, s *Service)) {
+	for _, kind := range slices.Sorted(maps.Keys(testStores)) {
+		t.Run(kind, func(t *testing.T) {
+			test(t, newTestServiceWithStore(t, testStores[kind](t), cfg))
+		})
+	}
+}
+
+// testEvent returns the event of a CreateEvent request.
+func testEvent(subject, action string, duration time.Duration) *usagev1.Event {
+	return &usagev1.Event{
+		Subject:           subject,
+		Source:            "animal-classifier",
+		Action:            action,
+		ExecutionDuration: durationpb.New(duration),
+	}
+}
+
+// testEventAt returns an event created at createTime, as the service would
+// have before storing it.
+func testEventAt(subject, action string, duration time.Duration, createTime time.Time) *usagev1.Event {
+	event := testEvent(subject, action, duration)
+	event.CreateTime = timestamppb.New(createTime)
+	return event
+}
+
+// createTestEvent creates event through the service and returns the created
+// event.
+func createTestEvent(t *testing.T, s *Service, event *usagev1.Event) *usagev1.Event {
+	t.Helper()
+	resp, err := s.CreateEvent(context.Background(), &usagev1.CreateEventRequest{Event: event})
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	return resp.GetEvent()
+}
+
+// errorReason returns the code of err, the reason of its ErrorInfo detail,
+// if any, and the fields of the violations of its BadRequest detail.
+func errorReason(err error) (code codes.Code, reason string, fields []string) {
+	st := status.Convert(err)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = d.GetReason()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	return st.Code(), reason, fields
+}
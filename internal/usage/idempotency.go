@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// errRequestIDReused is returned when a request_id is reused for a request
+// with a different payload.
+var errRequestIDReused = errors.New("request_id reused with a different payload")
+
+// validateRequestID checks that a request_id is a UUID in its canonical
+// textual form, as required by AIP-155.
+func validateRequestID(requestID string) error {
+	if _, err := uuid.Parse(requestID); err != nil || len(requestID) != 36 {
+		return fmt.Errorf("request_id %q must be a UUID such as %q", requestID, "123e4567-e89b-42d3-a456-426614174000")
+	}
+	return nil
+}
+
+// idempotencyCache remembers the outcome of requests carrying an AIP-155
+// request_id for a limited window, so that retried requests are not applied
+// twice. Entries live in process memory.
+type idempotencyCache struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*idempotentRequest
+	queue   []queuedRequest // in order of creation, for expiration
+}
+
+type queuedRequest struct {
+	requestID string
+	entry     *idempotentRequest
+}
+
+// idempotentRequest tracks a request_id that is being or has been processed.
+type idempotentRequest struct {
+	fingerprint []byte
+	expires     time.Time
+	done        chan struct{} // closed when the first request completes
+
+	// Set before done is closed. A nil result means the request failed and
+	// the request_id may be retried.
+	result proto.Message
+}
+
+func newIdempotencyCache(window time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		window:  window,
+		entries: make(map[string]*idempotentRequest),
+	}
+}
+
+// requestFingerprint hashes a request with its request_id field cleared.
+func requestFingerprint(req proto.Message, clearRequestID func(proto.Message)) []byte {
+	req = proto.Clone(req)
+	clearRequestID(req)
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// do runs fn at most once per request ID within the window and returns its
+// result. Concurrent and later calls with the same ID wait for and share the
+// result of the first call; if fn fails, the next call runs it again. It
+// returns errRequestIDReused if the ID was used with a different fingerprint.
+func (c *idempotencyCache) do(ctx context.Context, requestID string, fingerprint []byte, fn func() (proto.Message, error)) (proto.Message, error) {
+	for {
+		entry, first := c.begin(requestID, fingerprint)
+		if !bytes.Equal(entry.fingerprint, fingerprint) {
+			return nil, errRequestIDReused
+		}
+
+		if first {
+			result, err := fn()
+			if err != nil {
+				c.finish(requestID, entry, nil)
+				return nil, err
+			}
+			c.finish(requestID, entry, result)
+			return result, nil
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.result != nil {
+			return entry.result, nil
+		}
+	}
+}
+
+// begin returns the entry for requestID, creating it if there is none.
+// first reports whether the entry was created by this call.
+func (c *idempotencyCache) begin(requestID string, fingerprint []byte) (entry *idempotentRequest, first bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.expire(now)
+
+	if entry, ok := c.entries[requestID]; ok {
+		return entry, false
+	}
+	entry = &idempotentRequest{
+		fingerprint: fingerprint,
+		expires:     now.Add(c.window),
+		done:        make(chan struct{}),
+	}
+	c.entries[requestID] = entry
+	c.queue = append(c.queue, queuedRequest{requestID: requestID, entry: entry})
+	return entry, true
+}
+
+// finish records the result of the first request. Failed requests are
+// forgotten so that they can be retried.
+func (c *idempotencyCache) finish(requestID string, entry *idempotentRequest, result proto.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry.result = result
+	if result == nil || !time.Now().Before(entry.expires) {
+		if c.entries[requestID] == entry {
+			delete(c.entries, requestID)
+		}
+	}
+	close(entry.done)
+}
+
+// expire drops completed entries older than the window. Entries expire in
+// creation order, so only the head of the queue needs to be checked.
+// Entries still in progress are dropped by finish.
+func (c *idempotencyCache) expire(now time.Time) {
+	n := 0
+	for _, q := range c.queue {
+		if now.Before(q.entry.expires) {
+			break
+		}
+		if q.entry.result != nil && c.entries[q.requestID] == q.entry {
+			delete(c.entries, q.requestID)
+		}
+		n++
+	}
+	c.queue = c.queue[n:]
+}
+
+// createEventFingerprint identifies the payload of a CreateEvent request.
+func createEventFingerprint(req *usagev1.CreateEventRequest) []byte {
+	return requestFingerprint(req, func(m proto.Message) {
+		m.(*usagev1.CreateEventRequest).RequestId = ""
+	})
+}
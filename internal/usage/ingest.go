@@ -0,0 +1,70 @@
+package usage
+
+import (
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// ingestBufferSize is the number of IngestEvents requests read ahead of the
+// one being processed. Once the buffer is full the stream is not read, so
+// HTTP/2 flow control blocks the client until the server catches up.
+const ingestBufferSize = 64
+
+// IngestEvents creates events sent over a stream, acknowledging each of them.
+func (s *Service) IngestEvents(stream grpc.BidiStreamingServer[usagev1.IngestEventsRequest, usagev1.IngestEventsResponse]) error {
+	ctx := stream.Context()
+
+	// Receive in a separate goroutine so that reading the next requests
+	// overlaps with storing the current one.
+	requests := make(chan *usagev1.IngestEventsRequest, ingestBufferSize)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(requests)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	for req := range requests {
+		resp := &usagev1.IngestEventsResponse{Sequence: req.GetSequence()}
+		var created *usagev1.CreateEventResponse
+		violations := fieldbehavior.Validate(req)
+		for _, v := range s.ValidateRequest(req.GetRequest()) {
+			v.Field = "request." + v.Field
+			violations = append(violations, v)
+		}
+		err := fieldbehavior.Error(violations)
+		if err == nil {
+			created, err = s.CreateEvent(ctx, req.GetRequest())
+		}
+		if err != nil {
+			resp.Status = status.Convert(err).Proto()
+		} else {
+			resp.Event = created.GetEvent()
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	if err := <-recvErr; !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// Limits of event labels, following the constraints of Google Cloud labels.
+// Values are less strict, so that they can hold versions such as "v1.2".
+const (
+	maxLabels      = 64
+	maxLabelLength = 63
+)
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	labelValuePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]*$`)
+)
+
+// ValidateLabelKey checks that a label key is 1-63 characters of lowercase
+// letters, digits, underscores and hyphens, starting with a letter.
+func ValidateLabelKey(key string) error {
+	if len(key) > maxLabelLength || !labelKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid label key %q: must be 1-%d characters of lowercase letters, digits, "+
+			"underscores and hyphens, and start with a letter", key, maxLabelLength)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks that a label value is up to 63 characters of
+// letters, digits, underscores, hyphens and dots.
+func ValidateLabelValue(value string) error {
+	if len(value) > maxLabelLength || !labelValuePattern.MatchString(value) {
+		return fmt.Errorf("invalid label value %q: must be up to %d characters of letters, digits, "+
+			"underscores, hyphens and dots", value, maxLabelLength)
+	}
+	return nil
+}
+
+// validateLabels returns the violations of the labels of an event, whose
+// path in the request is given by field. Labels are reported in key order.
+func validateLabels(field string, labels map[string]string) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+	if len(labels) > maxLabels {
+		violations = append(violations, invalidField(field, ReasonTooManyLabels,
+			fmt.Errorf("%d labels exceed the maximum of %d", len(labels), maxLabels)))
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		path := fmt.Sprintf("%s[%q]", field, key)
+		if err := ValidateLabelKey(key); err != nil {
+			violations = append(violations, invalidField(path, ReasonInvalidLabel, err))
+		} else if err := ValidateLabelValue(labels[key]); err != nil {
+			violations = append(violations, invalidField(path, ReasonInvalidLabel, err))
+		}
+	}
+	return violations
+}
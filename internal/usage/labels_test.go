@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateLabelKey(t *testing.T) {
+	for _, tt := range []struct {
+		key   string
+		valid bool
+	}{
+		{"model", true},
+		{"model_version", true},
+		{"gpu-type2", true},
+		{strings.Repeat("k", maxLabelLength), true},
+		{"", false},
+		{"2gpu", false},
+		{"_model", false},
+		{"Model", false},
+		{"model.version", false},
+		{strings.Repeat("k", maxLabelLength+1), false},
+	} {
+		if err := ValidateLabelKey(tt.key); (err == nil) != tt.valid {
+			t.Errorf("ValidateLabelKey(%q) error = %v, want valid %t", tt.key, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateLabelValue(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		valid bool
+	}{
+		{"", true},
+		{"mock-1", true},
+		{"v1.2", true},
+		{"V1.2.3-RC_1", true},
+		{"us-east1", true},
+		{strings.Repeat("v", maxLabelLength), true},
+		{"v1 2", false},
+		{"v1/2", false},
+		{"v1:2", false},
+		{"modèle", false},
+		{strings.Repeat("v", maxLabelLength+1), false},
+	} {
+		if err := ValidateLabelValue(tt.value); (err == nil) != tt.valid {
+			t.Errorf("ValidateLabelValue(%q) error = %v, want valid %t", tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	labels := map[string]string{"model": "v1.2", "Region": "us", "zone": "a b"}
+	var fields []string
+	for _, v := range validateLabels("event.labels", labels) {
+		if v.Reason != ReasonInvalidLabel {
+			t.Errorf("validateLabels() reason = %s, want %s", v.Reason, ReasonInvalidLabel)
+		}
+		fields = append(fields, v.Field)
+	}
+	if want := []string{`event.labels["Region"]`, `event.labels["zone"]`}; !slices.Equal(fields, want) {
+		t.Errorf("validateLabels() fields = %q, want %q", fields, want)
+	}
+
+	labels = make(map[string]string)
+	for i := range maxLabels + 1 {
+		labels[fmt.Sprintf("k%d", i)] = ""
+	}
+	if v := validateLabels("labels", labels); len(v) != 1 || v[0].Reason != ReasonTooManyLabels {
+		t.Errorf("validateLabels() of %d labels = %+v, want %s", len(labels), v, ReasonTooManyLabels)
+	}
+}
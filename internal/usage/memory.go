@@ -0,0 +1,464 @@
+package usage
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// maxEventIndexes is the maximum number of orderings that a MemoryStore
+// keeps its events sorted by.
+const maxEventIndexes = 16
+
+// MemoryStore is a Store that keeps events, sources, prices and rollups in
+// process memory. Its contents are lost when the process exits.
+//
+// Events are kept sorted by DefaultOrdering and by each other ordering they
+// are listed by, so that a page is found by binary search. The index of an
+// ordering is built on its first listing, which sorts all events, and then
+// kept up to date, which makes creating, updating and deleting an event
+// linear in the number of events for every index. At most maxEventIndexes
+// are kept: beyond that, the index of another ordering is dropped and
+// built again when that ordering is listed next.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	events  map[string]*usagev1.Event  // keyed by resource name
+	indexes map[string]*eventIndex     // keyed by Ordering.String
+	sources map[string]*usagev1.Source // keyed by resource name
+	prices  map[string]*usagev1.Price  // keyed by resource name
+	rollups map[RollupKey]*Rollup
+}
+
+// eventIndex is the list of the events of a MemoryStore sorted by an
+// ordering.
+type eventIndex struct {
+	ordering Ordering
+	events   []*usagev1.Event
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		events:  make(map[string]*usagev1.Event),
+		indexes: map[string]*eventIndex{DefaultOrdering.String(): {ordering: DefaultOrdering}},
+		sources: make(map[string]*usagev1.Source),
+		prices:  make(map[string]*usagev1.Price),
+		rollups: make(map[RollupKey]*Rollup),
+	}
+}
+
+// CreateEvent implements EventStore.
+func (m *MemoryStore) CreateEvent(ctx context.Context, event *usagev1.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.events[event.GetName()]; ok {
+		return ErrAlreadyExists
+	}
+	m.insert(proto.Clone(event).(*usagev1.Event))
+	return nil
+}
+
+// BatchCreateEvents implements EventStore.
+func (m *MemoryStore) BatchCreateEvents(ctx context.Context, events []*usagev1.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make(map[string]bool, len(events))
+	for i, event := range events {
+		if _, ok := m.events[event.GetName()]; ok || names[event.GetName()] {
+			return &BatchError{Index: i, Name: event.GetName(), Err: ErrAlreadyExists}
+		}
+		names[event.GetName()] = true
+	}
+	for _, event := range events {
+		m.insert(proto.Clone(event).(*usagev1.Event))
+	}
+	return nil
+}
+
+// insert adds a new event. The caller must hold m.mu.
+func (m *MemoryStore) insert(event *usagev1.Event) {
+	m.events[event.GetName()] = event
+	for _, index := range m.indexes {
+		index.events = slices.Insert(index.events, index.search(CursorOf(event)), event)
+	}
+}
+
+// GetEvent implements EventStore.
+func (m *MemoryStore) GetEvent(ctx context.Context, name string) (*usagev1.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	event, ok := m.events[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(event).(*usagev1.Event), nil
+}
+
+// ListEvents implements EventStore.
+func (m *MemoryStore) ListEvents(ctx context.Context, query ListQuery) ([]*usagev1.Event, error) {
+	ordering := query.OrderBy
+	if ordering == nil {
+		ordering = DefaultOrdering
+	}
+	m.mu.RLock()
+	index, ok := m.indexes[ordering.String()]
+	if ok {
+		defer m.mu.RUnlock()
+	} else {
+		// Build the index and list the events without letting go of the
+		// lock, so that the index cannot be dropped in between
+		m.mu.RUnlock()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		index = m.index(ordering)
+	}
+
+	events := index.events
+	start := 0
+	if query.After != nil {
+		after := *query.After
+		start = sort.Search(len(events), func(i int) bool {
+			return ordering.Compare(after, CursorOf(events[i])) < 0
+		})
+	}
+	var result []*usagev1.Event
+	for _, event := range events[start:] {
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+		if !query.ShowDeleted && event.GetDeleteTime() != nil || query.Filter != nil && !query.Filter(event) {
+			continue
+		}
+		result = append(result, proto.Clone(event).(*usagev1.Event))
+	}
+	return result, nil
+}
+
+// UpdateEvent implements EventStore.
+func (m *MemoryStore) UpdateEvent(ctx context.Context, name string, update func(*usagev1.Event) (*usagev1.Event, error)) (*usagev1.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, ok := m.events[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated, err := update(proto.Clone(event).(*usagev1.Event))
+	if err != nil {
+		return nil, err
+	}
+	m.remove(event)
+	m.insert(proto.Clone(updated).(*usagev1.Event))
+	return updated, nil
+}
+
+// DeleteEvent implements EventStore.
+func (m *MemoryStore) DeleteEvent(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event, ok := m.events[name]
+	if !ok {
+		return ErrNotFound
+	}
+	m.remove(event)
+	return nil
+}
+
+// PurgeEvents implements EventStore.
+func (m *MemoryStore) PurgeEvents(ctx context.Context, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, event := range m.events {
+		if event.GetPurgeTime() != nil && !event.GetPurgeTime().AsTime().After(now) {
+			m.remove(event)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// remove deletes a stored event. The caller must hold m.mu.
+func (m *MemoryStore) remove(event *usagev1.Event) {
+	delete(m.events, event.GetName())
+	for _, index := range m.indexes {
+		// Events the ordering does not tell apart follow each other
+		i := index.search(CursorOf(event))
+		for index.events[i] != event {
+			i++
+		}
+		index.events = slices.Delete(index.events, i, i+1)
+	}
+}
+
+// index returns the index of the events sorted by ordering, building it if
+// there is none. The caller must hold m.mu for writing.
+func (m *MemoryStore) index(ordering Ordering) *eventIndex {
+	key := ordering.String()
+	if index, ok := m.indexes[key]; ok {
+		return index
+	}
+	if len(m.indexes) >= maxEventIndexes {
+		for k := range m.indexes {
+			if k != DefaultOrdering.String() {
+				delete(m.indexes, k)
+				break
+			}
+		}
+	}
+
+	index := &eventIndex{ordering: ordering, events: slices.Collect(maps.Values(m.events))}
+	slices.SortFunc(index.events, func(a, b *usagev1.Event) int {
+		return ordering.Compare(CursorOf(a), CursorOf(b))
+	})
+	m.indexes[key] = index
+	return index
+}
+
+// search returns the position of the first event in the index that does
+// not sort before c.
+func (x *eventIndex) search(c Cursor) int {
+	return sort.Search(len(x.events), func(i int) bool {
+		return x.ordering.Compare(CursorOf(x.events[i]), c) >= 0
+	})
+}
+
+// CreateSource implements SourceStore.
+func (m *MemoryStore) CreateSource(ctx context.Context, source *usagev1.Source) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sources[source.GetName()]; ok {
+		return ErrAlreadyExists
+	}
+	m.sources[source.GetName()] = proto.Clone(source).(*usagev1.Source)
+	return nil
+}
+
+// GetSource implements SourceStore.
+func (m *MemoryStore) GetSource(ctx context.Context, name string) (*usagev1.Source, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	source, ok := m.sources[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(source).(*usagev1.Source), nil
+}
+
+// ListSources implements SourceStore.
+func (m *MemoryStore) ListSources(ctx context.Context, after string, limit int) ([]*usagev1.Source, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*usagev1.Source
+	for _, source := range m.sources {
+		if source.GetName() > after {
+			result = append(result, source)
+		}
+	}
+	slices.SortFunc(result, func(a, b *usagev1.Source) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	for i, source := range result {
+		result[i] = proto.Clone(source).(*usagev1.Source)
+	}
+	return result, nil
+}
+
+// UpdateSource implements SourceStore.
+func (m *MemoryStore) UpdateSource(ctx context.Context, name string, update func(*usagev1.Source) (*usagev1.Source, error)) (*usagev1.Source, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.sources[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated, err := update(proto.Clone(source).(*usagev1.Source))
+	if err != nil {
+		return nil, err
+	}
+	m.sources[name] = proto.Clone(updated).(*usagev1.Source)
+	return updated, nil
+}
+
+// DeleteSource implements SourceStore.
+func (m *MemoryStore) DeleteSource(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sources[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.sources, name)
+	return nil
+}
+
+// CreatePrice implements PriceStore.
+func (m *MemoryStore) CreatePrice(ctx context.Context, price *usagev1.Price, check func(siblings []*usagev1.Price) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.prices[price.GetName()]; ok {
+		return ErrAlreadyExists
+	}
+	if err := check(m.siblings(PriceParent(price.GetName()))); err != nil {
+		return err
+	}
+	m.prices[price.GetName()] = proto.Clone(price).(*usagev1.Price)
+	return nil
+}
+
+// GetPrice implements PriceStore.
+func (m *MemoryStore) GetPrice(ctx context.Context, name string) (*usagev1.Price, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	price, ok := m.prices[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return proto.Clone(price).(*usagev1.Price), nil
+}
+
+// ListPrices implements PriceStore.
+func (m *MemoryStore) ListPrices(ctx context.Context, parent, after string, limit int) ([]*usagev1.Price, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*usagev1.Price
+	for _, price := range m.siblings(parent) {
+		if price.GetName() > after {
+			result = append(result, price)
+		}
+	}
+	slices.SortFunc(result, func(a, b *usagev1.Price) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// UpdatePrice implements PriceStore.
+func (m *MemoryStore) UpdatePrice(ctx context.Context, name string, update func(price *usagev1.Price, siblings []*usagev1.Price) (*usagev1.Price, error)) (*usagev1.Price, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	price, ok := m.prices[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated, err := update(proto.Clone(price).(*usagev1.Price), m.siblings(PriceParent(name)))
+	if err != nil {
+		return nil, err
+	}
+	m.prices[name] = proto.Clone(updated).(*usagev1.Price)
+	return updated, nil
+}
+
+// DeletePrice implements PriceStore.
+func (m *MemoryStore) DeletePrice(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.prices[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.prices, name)
+	return nil
+}
+
+// FindPrice implements PriceStore.
+func (m *MemoryStore) FindPrice(ctx context.Context, parent string, t time.Time) (*usagev1.Price, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, price := range m.siblings(parent) {
+		if PriceInEffect(price, t) {
+			return price, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// siblings returns copies of the prices of the action with the given
+// resource name. The caller must hold m.mu.
+func (m *MemoryStore) siblings(parent string) []*usagev1.Price {
+	var prices []*usagev1.Price
+	for _, price := range m.prices {
+		if PriceParent(price.GetName()) == parent {
+			prices = append(prices, proto.Clone(price).(*usagev1.Price))
+		}
+	}
+	return prices
+}
+
+// AddRollups implements RollupStore.
+func (m *MemoryStore) AddRollups(ctx context.Context, deltas []*Rollup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, delta := range deltas {
+		r, ok := m.rollups[delta.RollupKey]
+		if !ok {
+			r = &Rollup{RollupKey: delta.RollupKey}
+			m.rollups[delta.RollupKey] = r
+		}
+		if r.Add(delta); r.Empty() {
+			delete(m.rollups, delta.RollupKey)
+		}
+	}
+	return nil
+}
+
+// ListRollups implements RollupStore.
+func (m *MemoryStore) ListRollups(ctx context.Context, query RollupQuery) ([]*Rollup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*Rollup
+	for key, r := range m.rollups {
+		if (query.Granularity == "" || key.Granularity == query.Granularity) &&
+			(query.Start.IsZero() || !key.Start.Before(query.Start)) &&
+			(query.End.IsZero() || key.Start.Before(query.End)) &&
+			(query.Subject == "" || key.Subject == query.Subject) {
+			result = append(result, r.clone())
+		}
+	}
+	slices.SortFunc(result, func(a, b *Rollup) int { return a.Start.Compare(b.Start) })
+	return result, nil
+}
+
+// ReplaceRollups implements RollupStore.
+func (m *MemoryStore) ReplaceRollups(ctx context.Context, rollups []*Rollup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollups = make(map[RollupKey]*Rollup, len(rollups))
+	for _, r := range rollups {
+		m.rollups[r.RollupKey] = r.clone()
+	}
+	return nil
+}
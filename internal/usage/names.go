@@ -0,0 +1,144 @@
+package usage
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Collection identifiers of resource names.
+const (
+	eventCollection  = "events"
+	sourceCollection = "sources"
+	actionCollection = "actions"
+	priceCollection  = "prices"
+)
+
+// subjectCollections are the collection identifiers of the resources that
+// can be the subject of an event.
+var subjectCollections = []string{"users", "serviceAccounts"}
+
+// resourceIDPattern matches resource IDs as recommended by AIP-122: RFC 1034
+// labels of up to 63 characters.
+var resourceIDPattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// resourceIDRules describes resourceIDPattern in error messages.
+const resourceIDRules = "1-63 characters of lowercase letters, digits and hyphens, " +
+	"start with a letter and not end with a hyphen"
+
+// ValidateEventID checks that a client-chosen event ID is a valid resource
+// ID.
+func ValidateEventID(id string) error {
+	if !resourceIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid event_id %q: must be %s", id, resourceIDRules)
+	}
+	return nil
+}
+
+// ValidateSourceID checks that a client-chosen source ID is a valid
+// resource ID.
+func ValidateSourceID(id string) error {
+	if !resourceIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid source_id %q: must be %s", id, resourceIDRules)
+	}
+	return nil
+}
+
+// ValidateAction checks that an action of a source follows the rules of
+// resource IDs.
+func ValidateAction(action string) error {
+	if !resourceIDPattern.MatchString(action) {
+		return fmt.Errorf("invalid action %q: must be %s", action, resourceIDRules)
+	}
+	return nil
+}
+
+// ValidatePriceID checks that a client-chosen price ID is a valid resource
+// ID.
+func ValidatePriceID(id string) error {
+	if !resourceIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid price_id %q: must be %s", id, resourceIDRules)
+	}
+	return nil
+}
+
+// ValidateSubject checks that a subject is the resource name of a user,
+// `users/{user}`, or of a service account, `serviceAccounts/{service_account}`.
+func ValidateSubject(subject string) error {
+	collection, id, ok := strings.Cut(subject, "/")
+	if !ok || !slices.Contains(subjectCollections, collection) {
+		return fmt.Errorf("invalid subject %q: must match users/{user} or serviceAccounts/{service_account}", subject)
+	}
+	if !resourceIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid subject %q: the ID must be %s", subject, resourceIDRules)
+	}
+	return nil
+}
+
+// EventName returns the resource name of the event with the given ID.
+func EventName(id string) string {
+	return eventCollection + "/" + id
+}
+
+// ParseEventName returns the event ID from a resource name of the form
+// `events/{event}`.
+func ParseEventName(name string) (string, error) {
+	id, ok := strings.CutPrefix(name, eventCollection+"/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid event name %q: must match %s/{event}", name, eventCollection)
+	}
+	return id, nil
+}
+
+// SourceName returns the resource name of the source with the given ID.
+func SourceName(id string) string {
+	return sourceCollection + "/" + id
+}
+
+// ParseSourceName returns the source ID from a resource name of the form
+// `sources/{source}`.
+func ParseSourceName(name string) (string, error) {
+	id, ok := strings.CutPrefix(name, sourceCollection+"/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid source name %q: must match %s/{source}", name, sourceCollection)
+	}
+	return id, nil
+}
+
+// ActionName returns the resource name of an action of a source, the parent
+// of its prices.
+func ActionName(source, action string) string {
+	return SourceName(source) + "/" + actionCollection + "/" + action
+}
+
+// ParseActionName returns the source and action from a resource name of the
+// form `sources/{source}/actions/{action}`.
+func ParseActionName(name string) (source, action string, err error) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 4 || parts[0] != sourceCollection || parts[1] == "" ||
+		parts[2] != actionCollection || parts[3] == "" {
+		return "", "", fmt.Errorf("invalid action name %q: must match %s/{source}/%s/{action}",
+			name, sourceCollection, actionCollection)
+	}
+	return parts[1], parts[3], nil
+}
+
+// PriceName returns the resource name of the price with the given ID of an
+// action of a source.
+func PriceName(source, action, id string) string {
+	return ActionName(source, action) + "/" + priceCollection + "/" + id
+}
+
+// ParsePriceName returns the source, action and price ID from a resource
+// name of the form `sources/{source}/actions/{action}/prices/{price}`.
+func ParsePriceName(name string) (source, action, id string, err error) {
+	parts := strings.Split(name, "/")
+	if len(parts) == 6 && parts[4] == priceCollection && parts[5] != "" {
+		if source, action, err = ParseActionName(strings.Join(parts[:4], "/")); err == nil {
+			return source, action, parts[5], nil
+		}
+	}
+	return "", "", "", fmt.Errorf("invalid price name %q: must match %s/{source}/%s/{action}/%s/{price}",
+		name, sourceCollection, actionCollection, priceCollection)
+}
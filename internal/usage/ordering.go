@@ -0,0 +1,160 @@
+package usage
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// Orderable event fields, named as in the API.
+const (
+	FieldName              = "name"
+	FieldSubject           = "subject"
+	FieldSource            = "source"
+	FieldAction            = "action"
+	FieldCreateTime        = "create_time"
+	FieldExecutionDuration = "execution_duration"
+)
+
+// FieldLabels is the labels field of events, which can be filtered by but
+// not ordered by.
+const FieldLabels = "labels"
+
+// OrderField is a single key of an Ordering.
+type OrderField struct {
+	Field string
+	Desc  bool
+}
+
+// Ordering is the sort order of an event listing. A valid Ordering ends with
+// the event name, which makes the order total and keyset pagination exact.
+type Ordering []OrderField
+
+// DefaultOrdering lists the newest events first.
+var DefaultOrdering = Ordering{
+	{Field: FieldCreateTime, Desc: true},
+	{Field: FieldName, Desc: true},
+}
+
+// ParseOrderBy parses an AIP-132 order_by value such as
+// `execution_duration desc, create_time` into an Ordering. An empty value
+// yields DefaultOrdering.
+func ParseOrderBy(orderBy string) (Ordering, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return DefaultOrdering, nil
+	}
+
+	var ordering Ordering
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("invalid order_by clause %q", strings.TrimSpace(part))
+		}
+
+		field := OrderField{Field: words[0]}
+		if !isOrderable(field.Field) {
+			return nil, fmt.Errorf("cannot order by %q; supported fields are %s", field.Field,
+				strings.Join([]string{FieldCreateTime, FieldExecutionDuration, FieldSubject, FieldSource, FieldAction, FieldName}, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("field %q appears more than once in order_by", field.Field)
+		}
+		seen[field.Field] = true
+
+		if len(words) == 2 {
+			if words[1] != "desc" {
+				return nil, fmt.Errorf("invalid order_by direction %q for %q; only \"desc\" is allowed", words[1], field.Field)
+			}
+			field.Desc = true
+		}
+		ordering = append(ordering, field)
+	}
+
+	// Break ties by name in the direction of the last key
+	if !seen[FieldName] {
+		ordering = append(ordering, OrderField{Field: FieldName, Desc: ordering[len(ordering)-1].Desc})
+	}
+	return ordering, nil
+}
+
+// String formats the ordering as an order_by value.
+func (o Ordering) String() string {
+	parts := make([]string, len(o))
+	for i, f := range o {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] += " desc"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func isOrderable(field string) bool {
+	switch field {
+	case FieldName, FieldSubject, FieldSource, FieldAction, FieldCreateTime, FieldExecutionDuration:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cursor identifies a position in an event listing: the sort key of the
+// event it is positioned at, for every orderable field.
+type Cursor struct {
+	Name              string
+	Subject           string
+	Source            string
+	Action            string
+	CreateTime        time.Time
+	ExecutionDuration time.Duration
+}
+
+// CursorOf returns the cursor positioned at the given event.
+func CursorOf(event *usagev1.Event) Cursor {
+	return Cursor{
+		Name:              event.GetName(),
+		Subject:           event.GetSubject(),
+		Source:            event.GetSource(),
+		Action:            event.GetAction(),
+		CreateTime:        event.GetCreateTime().AsTime(),
+		ExecutionDuration: event.GetExecutionDuration().AsDuration(),
+	}
+}
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, together
+// with or after b in the ordering.
+func (o Ordering) Compare(a, b Cursor) int {
+	for _, f := range o {
+		c := compareField(f.Field, a, b)
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareField(field string, a, b Cursor) int {
+	switch field {
+	case FieldName:
+		return strings.Compare(a.Name, b.Name)
+	case FieldSubject:
+		return strings.Compare(a.Subject, b.Subject)
+	case FieldSource:
+		return strings.Compare(a.Source, b.Source)
+	case FieldAction:
+		return strings.Compare(a.Action, b.Action)
+	case FieldCreateTime:
+		return a.CreateTime.Compare(b.CreateTime)
+	case FieldExecutionDuration:
+		return cmp.Compare(a.ExecutionDuration, b.ExecutionDuration)
+	default:
+		panic(fmt.Sprintf("usage: cannot order by %q", field))
+	}
+}
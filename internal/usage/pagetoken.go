@@ -0,0 +1,145 @@
+package usage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidPageToken  = errors.New("invalid page_token")
+	errPageTokenMismatch = errors.New("page_token was issued for a different filter, order_by or show_deleted")
+)
+
+var pageTokenEncoding = base64.RawURLEncoding
+
+const pageTokenSignatureLen = sha256.Size
+
+// pageToken is the content of a ListEvents page token: the position of the
+// last returned event and a fingerprint of the query it was issued for.
+type pageToken struct {
+	// Query is a hash of the filter, show_deleted and order_by of the
+	// request.
+	Query []byte `json:"q"`
+	// Keys are the sort key values of the last returned event, one per field
+	// of the ordering.
+	Keys []string `json:"k"`
+}
+
+// pageTokenCodec encodes page tokens as base64 of a JSON payload followed by
+// its HMAC-SHA256 signature, so that clients cannot forge cursors.
+type pageTokenCodec struct {
+	key []byte
+}
+
+// queryFingerprint identifies the listing a page token belongs to.
+func queryFingerprint(filter string, showDeleted bool, ordering Ordering) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s\x00%t\x00%s", len(filter), filter, showDeleted, ordering)
+	return h.Sum(nil)[:16]
+}
+
+// encode returns a page token positioned at cursor.
+func (c pageTokenCodec) encode(filter string, showDeleted bool, ordering Ordering, cursor Cursor) (string, error) {
+	token := pageToken{
+		Query: queryFingerprint(filter, showDeleted, ordering),
+		Keys:  make([]string, len(ordering)),
+	}
+	for i, f := range ordering {
+		token.Keys[i] = formatKey(cursor, f.Field)
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return pageTokenEncoding.EncodeToString(append(payload, c.sign(payload)...)), nil
+}
+
+// decode verifies a page token and returns its cursor. It fails if the
+// token was not issued by this server or for a different query.
+func (c pageTokenCodec) decode(s string, filter string, showDeleted bool, ordering Ordering) (Cursor, error) {
+	raw, err := pageTokenEncoding.DecodeString(s)
+	if err != nil || len(raw) < pageTokenSignatureLen {
+		return Cursor{}, errInvalidPageToken
+	}
+	payload, signature := raw[:len(raw)-pageTokenSignatureLen], raw[len(raw)-pageTokenSignatureLen:]
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return Cursor{}, errInvalidPageToken
+	}
+
+	var token pageToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return Cursor{}, errInvalidPageToken
+	}
+	if !bytes.Equal(token.Query, queryFingerprint(filter, showDeleted, ordering)) || len(token.Keys) != len(ordering) {
+		return Cursor{}, errPageTokenMismatch
+	}
+
+	var cursor Cursor
+	for i, f := range ordering {
+		if err := parseKey(&cursor, f.Field, token.Keys[i]); err != nil {
+			return Cursor{}, errInvalidPageToken
+		}
+	}
+	return cursor, nil
+}
+
+func (c pageTokenCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func formatKey(c Cursor, field string) string {
+	switch field {
+	case FieldName:
+		return c.Name
+	case FieldSubject:
+		return c.Subject
+	case FieldSource:
+		return c.Source
+	case FieldAction:
+		return c.Action
+	case FieldCreateTime:
+		return c.CreateTime.UTC().Format(time.RFC3339Nano)
+	case FieldExecutionDuration:
+		return strconv.FormatInt(int64(c.ExecutionDuration), 10)
+	default:
+		panic(fmt.Sprintf("usage: unknown cursor field %q", field))
+	}
+}
+
+func parseKey(c *Cursor, field, value string) error {
+	switch field {
+	case FieldName:
+		c.Name = value
+	case FieldSubject:
+		c.Subject = value
+	case FieldSource:
+		c.Source = value
+	case FieldAction:
+		c.Action = value
+	case FieldCreateTime:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		c.CreateTime = t
+	case FieldExecutionDuration:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		c.ExecutionDuration = time.Duration(n)
+	default:
+		return fmt.Errorf("unknown cursor field %q", field)
+	}
+	return nil
+}
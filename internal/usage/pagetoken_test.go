@@ -0,0 +1,226 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+var testCursor = Cursor{
+	Name:              "events/e-1",
+	Subject:           "users/zoë",
+	Source:            "animal-classifier",
+	Action:            "classify",
+	CreateTime:        time.Date(2025, 1, 2, 15, 4, 5, 123456789, time.UTC),
+	ExecutionDuration: 1500 * time.Millisecond,
+}
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	codec := pageTokenCodec{key: []byte("secret")}
+	for _, orderBy := range []string{
+		"",
+		"create_time",
+		"execution_duration desc, create_time",
+		"subject, source desc, action",
+		"name desc",
+	} {
+		t.Run(orderBy, func(t *testing.T) {
+			ordering, err := ParseOrderBy(orderBy)
+			if err != nil {
+				t.Fatalf("ParseOrderBy(%q) error = %v", orderBy, err)
+			}
+			token, err := codec.encode(`action = "classify"`, true, ordering, testCursor)
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			got, err := codec.decode(token, `action = "classify"`, true, ordering)
+			if err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			// Only the fields of the ordering are kept
+			for _, f := range ordering {
+				if formatKey(got, f.Field) != formatKey(testCursor, f.Field) {
+					t.Errorf("decode() %s = %s, want %s", f.Field, formatKey(got, f.Field), formatKey(testCursor, f.Field))
+				}
+			}
+			if ordering.Compare(got, testCursor) != 0 {
+				t.Errorf("decode() = %+v, want the position of %+v", got, testCursor)
+			}
+		})
+	}
+}
+
+func TestPageTokenTampering(t *testing.T) {
+	codec := pageTokenCodec{key: []byte("secret")}
+	token, err := codec.encode("", false, DefaultOrdering, testCursor)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+	raw, err := pageTokenEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+
+	// Any change to the payload or signature invalidates the token
+	for i := range raw {
+		tampered := append([]byte(nil), raw...)
+		tampered[i] ^= 1
+		_, err := codec.decode(pageTokenEncoding.EncodeToString(tampered), "", false, DefaultOrdering)
+		if !errors.Is(err, errInvalidPageToken) {
+			t.Fatalf("decode() with byte %d flipped error = %v, want %v", i, err, errInvalidPageToken)
+		}
+	}
+
+	for name, token := range map[string]string{
+		"not base64":    "not a token!",
+		"empty":         "",
+		"too short":     pageTokenEncoding.EncodeToString(raw[:pageTokenSignatureLen-1]),
+		"no signature":  pageTokenEncoding.EncodeToString(raw[:len(raw)-pageTokenSignatureLen]),
+		"only a suffix": pageTokenEncoding.EncodeToString(raw[1:]),
+	} {
+		if _, err := codec.decode(token, "", false, DefaultOrdering); !errors.Is(err, errInvalidPageToken) {
+			t.Errorf("decode(%s) error = %v, want %v", name, err, errInvalidPageToken)
+		}
+	}
+
+	// A signed payload that is not a page token is rejected too
+	forged := []byte(`{"q":"","k":["x"]}`)
+	token = pageTokenEncoding.EncodeToString(append(forged, codec.sign(forged)...))
+	if _, err := codec.decode(token, "", false, DefaultOrdering); err == nil {
+		t.Errorf("decode() of a signed foreign payload succeeded, want an error")
+	}
+	malformed := []byte(`{"q":null,"k":["not a time","events/a"]}`)
+	token = pageTokenEncoding.EncodeToString(append(malformed, codec.sign(malformed)...))
+	if _, err := codec.decode(token, "", false, DefaultOrdering); err == nil {
+		t.Errorf("decode() of a signed malformed payload succeeded, want an error")
+	}
+}
+
+func TestPageTokenMismatch(t *testing.T) {
+	codec := pageTokenCodec{key: []byte("secret")}
+	ordering, err := ParseOrderBy("execution_duration desc")
+	if err != nil {
+		t.Fatalf("ParseOrderBy() error = %v", err)
+	}
+	const filter = `subject = "users/alice"`
+	token, err := codec.encode(filter, false, ordering, testCursor)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		filter      string
+		showDeleted bool
+		orderBy     string
+	}{
+		{"filter", `subject = "users/bob"`, false, "execution_duration desc"},
+		{"empty filter", "", false, "execution_duration desc"},
+		{"equivalent filter", `subject="users/alice"`, false, "execution_duration desc"},
+		{"show_deleted", filter, true, "execution_duration desc"},
+		{"direction", filter, false, "execution_duration"},
+		{"field", filter, false, "create_time desc"},
+		{"tie-breaker", filter, false, "execution_duration desc, name"},
+		{"default order", filter, false, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ordering, err := ParseOrderBy(tc.orderBy)
+			if err != nil {
+				t.Fatalf("ParseOrderBy(%q) error = %v", tc.orderBy, err)
+			}
+			_, err = codec.decode(token, tc.filter, tc.showDeleted, ordering)
+			if !errors.Is(err, errPageTokenMismatch) {
+				t.Errorf("decode() error = %v, want %v", err, errPageTokenMismatch)
+			}
+		})
+	}
+
+	// The filter is length-prefixed, so it cannot be confused with the rest
+	// of the fingerprint
+	if string(queryFingerprint("a\x00true", false, ordering)) == string(queryFingerprint("a", true, ordering)) {
+		t.Errorf("queryFingerprint() collides for filters that embed the separator")
+	}
+}
+
+func TestPageTokenKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	first := newTestServiceWithStore(t, store, Config{PageTokenKey: []byte("first key")})
+	for range 3 {
+		createTestEvent(t, first, testEvent("users/alice", "classify", time.Second))
+	}
+
+	resp, err := first.ListEvents(ctx, &usagev1.ListEventsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	token := resp.GetNextPageToken()
+	if token == "" {
+		t.Fatalf("ListEvents() returned no next_page_token")
+	}
+
+	// A restarted server or another replica with the same key accepts the
+	// token
+	replica := newTestServiceWithStore(t, store, Config{PageTokenKey: []byte("first key")})
+	resp, err = replica.ListEvents(ctx, &usagev1.ListEventsRequest{PageSize: 2, PageToken: token})
+	if err != nil {
+		t.Fatalf("ListEvents() with the same key error = %v", err)
+	}
+	if len(resp.GetEvents()) != 1 || resp.GetNextPageToken() != "" {
+		t.Errorf("ListEvents() with the same key = %d events, next_page_token %q, want the last event", len(resp.GetEvents()), resp.GetNextPageToken())
+	}
+
+	// Tokens signed with a rotated key, or by a server with a random key,
+	// are rejected
+	for name, cfg := range map[string]Config{
+		"rotated key": {PageTokenKey: []byte("second key")},
+		"random key":  {},
+	} {
+		rotated := newTestServiceWithStore(t, store, cfg)
+		_, err := rotated.ListEvents(ctx, &usagev1.ListEventsRequest{PageSize: 2, PageToken: token})
+		if code, reason, _ := errorReason(err); code != codes.InvalidArgument || reason != ReasonInvalidPageToken {
+			t.Errorf("ListEvents() with a %s error = %v, want %v with reason %s", name, err, codes.InvalidArgument, ReasonInvalidPageToken)
+		}
+	}
+
+	// A server with a random key still pages through its own tokens
+	random := newTestServiceWithStore(t, store, Config{})
+	resp, err = random.ListEvents(ctx, &usagev1.ListEventsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if _, err := random.ListEvents(ctx, &usagev1.ListEventsRequest{PageSize: 2, PageToken: resp.GetNextPageToken()}); err != nil {
+		t.Errorf("ListEvents() with its own token error = %v", err)
+	}
+}
+
+func TestListEventsPageTokenMismatch(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, Config{PageTokenKey: []byte("key")})
+	for range 3 {
+		createTestEvent(t, s, testEvent("users/alice", "classify", time.Second))
+	}
+
+	req := &usagev1.ListEventsRequest{PageSize: 1, Filter: `action = "classify"`}
+	resp, err := s.ListEvents(ctx, req)
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+
+	for name, next := range map[string]*usagev1.ListEventsRequest{
+		"filter":       {PageSize: 1, Filter: `action = "detect"`},
+		"order_by":     {PageSize: 1, Filter: req.GetFilter(), OrderBy: "create_time"},
+		"show_deleted": {PageSize: 1, Filter: req.GetFilter(), ShowDeleted: true},
+	} {
+		next.PageToken = resp.GetNextPageToken()
+		_, err := s.ListEvents(ctx, next)
+		if code, reason, _ := errorReason(err); code != codes.InvalidArgument || reason != ReasonInvalidPageToken {
+			t.Errorf("ListEvents() with a different %s error = %v, want %v with reason %s", name, err, codes.InvalidArgument, ReasonInvalidPageToken)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+CREATE TABLE events (
+    name               TEXT        NOT NULL PRIMARY KEY,
+    subject            TEXT        NOT NULL,
+    source             TEXT        NOT NULL,
+    action             TEXT        NOT NULL,
+    execution_duration BIGINT      NOT NULL, -- nanoseconds
+    create_time        TIMESTAMPTZ NOT NULL,
+    data               BYTEA       NOT NULL  -- serialized ai.h2o.usage.v1.Event
+);
+
+-- Serves the default ListEvents order (create_time DESC, name DESC) and its
+-- keyset pagination.
+CREATE INDEX events_create_time_idx ON events (create_time, name);
@@ -0,0 +1,5 @@
+-- Serve ListEvents ordered by the other orderable fields without sorting the
+-- whole table.
+CREATE INDEX events_execution_duration_idx ON events (execution_duration, name);
+CREATE INDEX events_subject_idx ON events (subject, name);
+CREATE INDEX events_source_action_idx ON events (source, action, name);
@@ -0,0 +1,4 @@
+-- Soft-deleted events are purged once their purge time has passed. Only
+-- deleted events have a purge time, so the index stays small.
+ALTER TABLE events ADD COLUMN purge_time TIMESTAMPTZ;
+CREATE INDEX events_purge_time_idx ON events (purge_time) WHERE purge_time IS NOT NULL;
@@ -0,0 +1,4 @@
+CREATE TABLE sources (
+    name TEXT  NOT NULL PRIMARY KEY,
+    data BYTEA NOT NULL -- serialized ai.h2o.usage.v1.Source
+);
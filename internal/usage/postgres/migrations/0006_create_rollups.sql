@@ -0,0 +1,11 @@
+-- Rollups hold the totals of the live events of a subject, source and action
+-- per hour and day, which AggregateUsage reads instead of the events.
+CREATE TABLE rollups (
+    granularity TEXT        NOT NULL, -- "hour" or "day"
+    start_time  TIMESTAMPTZ NOT NULL,
+    subject     TEXT        NOT NULL,
+    source      TEXT        NOT NULL,
+    action      TEXT        NOT NULL,
+    data        BYTEA       NOT NULL, -- JSON-encoded usage.Rollup totals
+    PRIMARY KEY (granularity, start_time, subject, source, action)
+);
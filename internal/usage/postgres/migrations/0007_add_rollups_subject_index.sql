@@ -0,0 +1,2 @@
+-- Quotas read the rollups of a subject in a window of time.
+CREATE INDEX rollups_subject_idx ON rollups (subject, granularity, start_time);
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// querier is implemented by *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// lockSiblings serializes the changes of the prices of the action with the
+// given resource name until tx ends. An advisory lock also covers prices
+// that do not exist yet, which row locks cannot.
+func lockSiblings(ctx context.Context, tx pgx.Tx, parent string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, parent)
+	return err
+}
+
+// CreatePrice implements usage.PriceStore.
+func (s *Store) CreatePrice(ctx context.Context, price *usagev1.Price, check func(siblings []*usagev1.Price) error) error {
+	data, err := proto.Marshal(price)
+	if err != nil {
+		return err
+	}
+	parent := usage.PriceParent(price.GetName())
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := lockSiblings(ctx, tx, parent); err != nil {
+			return err
+		}
+		siblings, err := queryPrices(ctx, tx, `SELECT data FROM prices WHERE parent = $1 ORDER BY name`, parent)
+		if err != nil {
+			return err
+		}
+		if err := check(siblings); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO prices (name, parent, data) VALUES ($1, $2, $3)`, price.GetName(), parent, data)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return usage.ErrAlreadyExists
+		}
+		return err
+	})
+}
+
+// GetPrice implements usage.PriceStore.
+func (s *Store) GetPrice(ctx context.Context, name string) (*usagev1.Price, error) {
+	var data []byte
+	err := s.pool.QueryRow(ctx, `SELECT data FROM prices WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPrice(data)
+}
+
+// ListPrices implements usage.PriceStore.
+func (s *Store) ListPrices(ctx context.Context, parent, after string, limit int) ([]*usagev1.Price, error) {
+	q := `SELECT data FROM prices WHERE parent = $1 AND name > $2 ORDER BY name`
+	args := []any{parent, after}
+	if limit > 0 {
+		q += ` LIMIT $3`
+		args = append(args, limit)
+	}
+	return queryPrices(ctx, s.pool, q, args...)
+}
+
+// UpdatePrice implements usage.PriceStore.
+func (s *Store) UpdatePrice(ctx context.Context, name string, update func(*usagev1.Price, []*usagev1.Price) (*usagev1.Price, error)) (*usagev1.Price, error) {
+	var updated *usagev1.Price
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		parent := usage.PriceParent(name)
+		if err := lockSiblings(ctx, tx, parent); err != nil {
+			return err
+		}
+		var data []byte
+		err := tx.QueryRow(ctx, `SELECT data FROM prices WHERE name = $1`, name).Scan(&data)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usage.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		price, err := unmarshalPrice(data)
+		if err != nil {
+			return err
+		}
+		siblings, err := queryPrices(ctx, tx, `SELECT data FROM prices WHERE parent = $1 ORDER BY name`, parent)
+		if err != nil {
+			return err
+		}
+
+		if updated, err = update(price, siblings); err != nil {
+			return err
+		}
+		if data, err = proto.Marshal(updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE prices SET data = $1 WHERE name = $2`, data, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeletePrice implements usage.PriceStore.
+func (s *Store) DeletePrice(ctx context.Context, name string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM prices WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+// FindPrice implements usage.PriceStore. Actions have few prices, so their
+// effective times are compared in Go.
+func (s *Store) FindPrice(ctx context.Context, parent string, t time.Time) (*usagev1.Price, error) {
+	prices, err := queryPrices(ctx, s.pool, `SELECT data FROM prices WHERE parent = $1 ORDER BY name`, parent)
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		if usage.PriceInEffect(price, t) {
+			return price, nil
+		}
+	}
+	return nil, usage.ErrNotFound
+}
+
+// queryPrices runs a query selecting the data of prices.
+func queryPrices(ctx context.Context, q querier, sql string, args ...any) ([]*usagev1.Price, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*usagev1.Price
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		price, err := unmarshalPrice(data)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+func unmarshalPrice(data []byte) (*usagev1.Price, error) {
+	price := &usagev1.Price{}
+	if err := proto.Unmarshal(data, price); err != nil {
+		return nil, fmt.Errorf("decode stored price: %w", err)
+	}
+	return price, nil
+}
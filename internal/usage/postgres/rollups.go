@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// AddRollups implements usage.RollupStore. Rollups are locked in key order,
+// so that concurrent calls cannot deadlock.
+func (s *Store) AddRollups(ctx context.Context, deltas []*usage.Rollup) error {
+	deltas = slices.SortedFunc(slices.Values(deltas), func(a, b *usage.Rollup) int {
+		if c := strings.Compare(string(a.Granularity), string(b.Granularity)); c != 0 {
+			return c
+		}
+		if c := a.Start.Compare(b.Start); c != 0 {
+			return c
+		}
+		return slices.Compare([]string{a.Subject, a.Source, a.Action}, []string{b.Subject, b.Source, b.Action})
+	})
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, delta := range deltas {
+			if err := addRollup(ctx, tx, delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addRollup adds delta to the stored rollup with the same key.
+func addRollup(ctx context.Context, tx pgx.Tx, delta *usage.Rollup) error {
+	k := delta.RollupKey
+	for {
+		var data []byte
+		err := tx.QueryRow(ctx, `
+			SELECT data FROM rollups
+			WHERE granularity = $1 AND start_time = $2 AND subject = $3 AND source = $4 AND action = $5
+			FOR UPDATE`,
+			string(k.Granularity), k.Start, k.Subject, k.Source, k.Action).Scan(&data)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Unless another transaction created the rollup in the meantime,
+			// in which case it is locked and updated on the next attempt
+			inserted, err := insertRollup(ctx, tx, delta, `DO NOTHING`)
+			if err != nil || inserted {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		r := &usage.Rollup{RollupKey: k}
+		if err := json.Unmarshal(data, r); err != nil {
+			return fmt.Errorf("decode stored rollup: %w", err)
+		}
+		if r.Add(delta); r.Empty() {
+			_, err = tx.Exec(ctx, `
+				DELETE FROM rollups
+				WHERE granularity = $1 AND start_time = $2 AND subject = $3 AND source = $4 AND action = $5`,
+				string(k.Granularity), k.Start, k.Subject, k.Source, k.Action)
+			return err
+		}
+		_, err = insertRollup(ctx, tx, r, `DO UPDATE SET data = excluded.data`)
+		return err
+	}
+}
+
+// ListRollups implements usage.RollupStore.
+func (s *Store) ListRollups(ctx context.Context, query usage.RollupQuery) ([]*usage.Rollup, error) {
+	q := `SELECT granularity, start_time, subject, source, action, data FROM rollups WHERE true`
+	var args []any
+	if query.Granularity != "" {
+		args = append(args, string(query.Granularity))
+		q += fmt.Sprintf(` AND granularity = $%d`, len(args))
+	}
+	if !query.Start.IsZero() {
+		args = append(args, query.Start)
+		q += fmt.Sprintf(` AND start_time >= $%d`, len(args))
+	}
+	if !query.End.IsZero() {
+		args = append(args, query.End)
+		q += fmt.Sprintf(` AND start_time < $%d`, len(args))
+	}
+	if query.Subject != "" {
+		args = append(args, query.Subject)
+		q += fmt.Sprintf(` AND subject = $%d`, len(args))
+	}
+	rows, err := s.pool.Query(ctx, q+` ORDER BY start_time`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*usage.Rollup
+	for rows.Next() {
+		r := &usage.Rollup{}
+		var granularity string
+		var data []byte
+		if err := rows.Scan(&granularity, &r.Start, &r.Subject, &r.Source, &r.Action, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, fmt.Errorf("decode stored rollup: %w", err)
+		}
+		r.Granularity = usage.Granularity(granularity)
+		r.Start = r.Start.UTC()
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+// ReplaceRollups implements usage.RollupStore.
+func (s *Store) ReplaceRollups(ctx context.Context, rollups []*usage.Rollup) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM rollups`); err != nil {
+			return err
+		}
+		for _, r := range rollups {
+			if _, err := insertRollup(ctx, tx, r, `DO NOTHING`); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// insertRollup stores r, resolving a conflict with the stored rollup with
+// the same key by the given ON CONFLICT action. It reports whether r was
+// inserted.
+func insertRollup(ctx context.Context, db execer, r *usage.Rollup, onConflict string) (bool, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return false, err
+	}
+	tag, err := db.Exec(ctx, `
+		INSERT INTO rollups (granularity, start_time, subject, source, action, data)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (granularity, start_time, subject, source, action) `+onConflict,
+		string(r.Granularity), r.Start, r.Subject, r.Source, r.Action, data)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// CreateSource implements usage.SourceStore.
+func (s *Store) CreateSource(ctx context.Context, source *usagev1.Source) error {
+	data, err := proto.Marshal(source)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, `INSERT INTO sources (name, data) VALUES ($1, $2)`, source.GetName(), data)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return usage.ErrAlreadyExists
+	}
+	return err
+}
+
+// GetSource implements usage.SourceStore.
+func (s *Store) GetSource(ctx context.Context, name string) (*usagev1.Source, error) {
+	var data []byte
+	err := s.pool.QueryRow(ctx, `SELECT data FROM sources WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalSource(data)
+}
+
+// ListSources implements usage.SourceStore.
+func (s *Store) ListSources(ctx context.Context, after string, limit int) ([]*usagev1.Source, error) {
+	q := `SELECT data FROM sources WHERE name > $1 ORDER BY name`
+	args := []any{after}
+	if limit > 0 {
+		q += ` LIMIT $2`
+		args = append(args, limit)
+	}
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []*usagev1.Source
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		source, err := unmarshalSource(data)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// UpdateSource implements usage.SourceStore.
+func (s *Store) UpdateSource(ctx context.Context, name string, update func(*usagev1.Source) (*usagev1.Source, error)) (*usagev1.Source, error) {
+	var updated *usagev1.Source
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var data []byte
+		err := tx.QueryRow(ctx, `SELECT data FROM sources WHERE name = $1 FOR UPDATE`, name).Scan(&data)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usage.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		source, err := unmarshalSource(data)
+		if err != nil {
+			return err
+		}
+
+		if updated, err = update(source); err != nil {
+			return err
+		}
+		if data, err = proto.Marshal(updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE sources SET data = $1 WHERE name = $2`, data, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteSource implements usage.SourceStore.
+func (s *Store) DeleteSource(ctx context.Context, name string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM sources WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+func unmarshalSource(data []byte) (*usagev1.Source, error) {
+	source := &usagev1.Source{}
+	if err := proto.Unmarshal(data, source); err != nil {
+		return nil, fmt.Errorf("decode stored source: %w", err)
+	}
+	return source, nil
+}
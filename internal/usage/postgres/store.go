@@ -0,0 +1,281 @@
+// Package postgres implements a usage.Store on top of PostgreSQL.
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+	"github.com/jan-sykora/api-demo/internal/usage/sqlorder"
+)
+
+// uniqueViolation is the PostgreSQL error code for unique constraint
+// violations.
+const uniqueViolation = "23505"
+
+// Store is a usage.Store backed by a PostgreSQL connection pool.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+var _ usage.Store = (*Store)(nil)
+
+// Open connects to the PostgreSQL database identified by dsn and migrates it
+// to the latest schema. Pool settings such as pool_max_conns can be passed as
+// DSN parameters.
+func Open(ctx context.Context, dsn string) (*Store, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse DSN: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	if err := runMigrations(ctx, pool); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return &Store{pool: pool}, nil
+}
+
+// Close closes all connections in the pool.
+func (s *Store) Close() {
+	s.pool.Close()
+}
+
+// execer is implemented by *pgxpool.Pool and pgx.Tx.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// CreateEvent implements usage.EventStore.
+func (s *Store) CreateEvent(ctx context.Context, event *usagev1.Event) error {
+	return insertEvent(ctx, s.pool, event)
+}
+
+// BatchCreateEvents implements usage.EventStore.
+func (s *Store) BatchCreateEvents(ctx context.Context, events []*usagev1.Event) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		for i, event := range events {
+			err := insertEvent(ctx, tx, event)
+			if errors.Is(err, usage.ErrAlreadyExists) {
+				return &usage.BatchError{Index: i, Name: event.GetName(), Err: err}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func insertEvent(ctx context.Context, db execer, event *usagev1.Event) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO events (name, subject, source, action, execution_duration, create_time, purge_time, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		event.GetName(),
+		event.GetSubject(),
+		event.GetSource(),
+		event.GetAction(),
+		event.GetExecutionDuration().AsDuration().Nanoseconds(),
+		event.GetCreateTime().AsTime(),
+		purgeTime(event),
+		data,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return usage.ErrAlreadyExists
+	}
+	return err
+}
+
+// purgeTime returns the purge_time column value of an event.
+func purgeTime(event *usagev1.Event) *time.Time {
+	if event.GetPurgeTime() == nil {
+		return nil
+	}
+	t := event.GetPurgeTime().AsTime()
+	return &t
+}
+
+// GetEvent implements usage.EventStore.
+func (s *Store) GetEvent(ctx context.Context, name string) (*usagev1.Event, error) {
+	var data []byte
+	err := s.pool.QueryRow(ctx, `SELECT data FROM events WHERE name = $1`, name).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEvent(data)
+}
+
+// ListEvents implements usage.EventStore.
+func (s *Store) ListEvents(ctx context.Context, query usage.ListQuery) ([]*usagev1.Event, error) {
+	ordering := query.OrderBy
+	if ordering == nil {
+		ordering = usage.DefaultOrdering
+	}
+	return usage.ScanEvents(query, func(after *usage.Cursor, limit int) ([]*usagev1.Event, error) {
+		return s.listEvents(ctx, ordering, after, limit, query.ShowDeleted)
+	})
+}
+
+// listEvents reads up to limit events following after in the ordering,
+// including the soft-deleted ones if showDeleted is set.
+func (s *Store) listEvents(ctx context.Context, ordering usage.Ordering, after *usage.Cursor, limit int, showDeleted bool) ([]*usagev1.Event, error) {
+	q := `SELECT data FROM events`
+	var conds []string
+	var args []any
+	if !showDeleted {
+		// Only soft-deleted events have a purge time
+		conds = append(conds, `purge_time IS NULL`)
+	}
+	if after != nil {
+		cond, keysetArgs := sqlorder.Keyset(ordering, func(field string) any {
+			return cursorValue(*after, field)
+		}, func(n int) string { return fmt.Sprintf("$%d", n) })
+		conds = append(conds, "("+cond+")")
+		args = append(args, keysetArgs...)
+	}
+	if len(conds) > 0 {
+		q += ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+	q += ` ORDER BY ` + sqlorder.OrderBy(ordering)
+
+	// A NULL LIMIT means no limit in PostgreSQL
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+	args = append(args, limitArg)
+	q += fmt.Sprintf(` LIMIT $%d`, len(args))
+
+	rows, err := s.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*usagev1.Event, error) {
+		var data []byte
+		if err := row.Scan(&data); err != nil {
+			return nil, err
+		}
+		return unmarshalEvent(data)
+	})
+}
+
+// cursorValue returns the value of a cursor field as stored in the events
+// table.
+func cursorValue(c usage.Cursor, field string) any {
+	switch field {
+	case usage.FieldName:
+		return c.Name
+	case usage.FieldSubject:
+		return c.Subject
+	case usage.FieldSource:
+		return c.Source
+	case usage.FieldAction:
+		return c.Action
+	case usage.FieldCreateTime:
+		return c.CreateTime
+	case usage.FieldExecutionDuration:
+		return c.ExecutionDuration.Nanoseconds()
+	default:
+		panic(fmt.Sprintf("postgres: unknown cursor field %q", field))
+	}
+}
+
+// UpdateEvent implements usage.EventStore.
+func (s *Store) UpdateEvent(ctx context.Context, name string, update func(*usagev1.Event) (*usagev1.Event, error)) (*usagev1.Event, error) {
+	var updated *usagev1.Event
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var data []byte
+		err := tx.QueryRow(ctx, `SELECT data FROM events WHERE name = $1 FOR UPDATE`, name).Scan(&data)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usage.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		event, err := unmarshalEvent(data)
+		if err != nil {
+			return err
+		}
+
+		if updated, err = update(event); err != nil {
+			return err
+		}
+		if data, err = proto.Marshal(updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE events
+			SET subject = $1, source = $2, action = $3, execution_duration = $4, create_time = $5, purge_time = $6, data = $7
+			WHERE name = $8`,
+			updated.GetSubject(),
+			updated.GetSource(),
+			updated.GetAction(),
+			updated.GetExecutionDuration().AsDuration().Nanoseconds(),
+			updated.GetCreateTime().AsTime(),
+			purgeTime(updated),
+			data,
+			name,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteEvent implements usage.EventStore.
+func (s *Store) DeleteEvent(ctx context.Context, name string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM events WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeEvents implements usage.EventStore.
+func (s *Store) PurgeEvents(ctx context.Context, now time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM events WHERE purge_time <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func unmarshalEvent(data []byte) (*usagev1.Event, error) {
+	event := &usagev1.Event{}
+	if err := proto.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("decode stored event: %w", err)
+	}
+	return event, nil
+}
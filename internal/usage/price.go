@@ -0,0 +1,492 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// priceOrdering is the order of ListPrices. Page tokens are cursors in this
+// order.
+var priceOrdering = Ordering{{Field: FieldName}}
+
+// mutablePriceFields are the fields of Price that clients can update.
+var mutablePriceFields = mutableFields(&usagev1.Price{})
+
+// roundingModes maps the rounding modes of prices to those of rate cards.
+var roundingModes = map[usagev1.RoundingMode]RoundingMode{
+	usagev1.RoundingMode_ROUNDING_MODE_UNSPECIFIED: RoundHalfUp,
+	usagev1.RoundingMode_ROUNDING_MODE_HALF_UP:     RoundHalfUp,
+	usagev1.RoundingMode_ROUNDING_MODE_UP:          RoundUp,
+	usagev1.RoundingMode_ROUNDING_MODE_DOWN:        RoundDown,
+}
+
+// PriceCatalogService implements the PriceCatalogService gRPC handler, the
+// catalog of the prices of the actions of sources.
+type PriceCatalogService struct {
+	usagev1.UnimplementedPriceCatalogServiceServer
+	store        Store
+	pageTokens   pageTokenCodec
+	checkSources bool
+}
+
+// NewPriceCatalogService creates a new PriceCatalogService backed by the
+// given store. Only cfg.PageTokenKey and cfg.PermissiveSources are used.
+func NewPriceCatalogService(store Store, cfg Config) (*PriceCatalogService, error) {
+	key, err := pageTokenKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PriceCatalogService{
+		store:        store,
+		pageTokens:   pageTokenCodec{key: key},
+		checkSources: !cfg.PermissiveSources,
+	}, nil
+}
+
+// ValidateRequest returns the violations of a request other than those of
+// its field behaviors. It implements fieldbehavior.Validator.
+func (s *PriceCatalogService) ValidateRequest(req proto.Message) []apierror.FieldViolation {
+	switch req := req.(type) {
+	case *usagev1.CreatePriceRequest:
+		var violations []apierror.FieldViolation
+		if req.GetParent() != "" {
+			if err := validateActionName(req.GetParent()); err != nil {
+				violations = append(violations, invalidField("parent", ReasonInvalidParent, err))
+			}
+		}
+		if req.GetPriceId() != "" {
+			if err := ValidatePriceID(req.GetPriceId()); err != nil {
+				violations = append(violations, invalidField("price_id", ReasonInvalidPriceID, err))
+			}
+		}
+		return append(violations, validatePrice("price", req.GetPrice())...)
+	case *usagev1.UpdatePriceRequest:
+		paths, err := updatePaths(req.GetPrice(), mutablePriceFields, req.GetUpdateMask().GetPaths())
+		if err != nil {
+			// Reported by UpdatePrice
+			return nil
+		}
+		if updatesAny(paths, "effective_time") {
+			return validateEffectiveTime("price.effective_time", req.GetPrice().GetEffectiveTime())
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// validatePrice returns the violations of the fields of a new price, whose
+// path in the request is given by field.
+func validatePrice(field string, price *usagev1.Price) []apierror.FieldViolation {
+	violations := validateEffectiveTime(joinPath(field, "effective_time"), price.GetEffectiveTime())
+	if price.GetPerCall() == nil && price.GetPerSecond() == nil {
+		violations = append(violations, apierror.FieldViolation{
+			Field:       joinPath(field, "per_call"),
+			Reason:      fieldbehavior.ReasonRequired,
+			Description: "one of per_call and per_second is required",
+		})
+	}
+
+	amounts := []struct {
+		field string
+		money *money.Money
+	}{
+		{"per_call", price.GetPerCall()},
+		{"per_second", price.GetPerSecond()},
+		{"minimum", price.GetMinimum()},
+		{"rounding_increment", price.GetRoundingIncrement()},
+	}
+	var currency string
+	for _, amount := range amounts {
+		if amount.money == nil {
+			continue
+		}
+		path := joinPath(field, amount.field)
+		if err := validateAmount(amount.field, amount.money); err != nil {
+			violations = append(violations, invalidField(path, ReasonInvalidAmount, err))
+		} else if currency == "" {
+			currency = amount.money.GetCurrencyCode()
+		} else if amount.money.GetCurrencyCode() != currency {
+			violations = append(violations, invalidField(path, ReasonInvalidAmount,
+				fmt.Errorf("currency %q of %s differs from %q of the other amounts",
+					amount.money.GetCurrencyCode(), amount.field, currency)))
+		}
+	}
+
+	if d := price.GetBillingIncrement(); d != nil {
+		if err := d.CheckValid(); err != nil || d.AsDuration() < 0 {
+			violations = append(violations, invalidField(joinPath(field, "billing_increment"),
+				ReasonInvalidBillingIncrement, errors.New("billing_increment must be a non-negative duration")))
+		}
+	}
+	return violations
+}
+
+// validateEffectiveTime returns the violations of the effective time of a
+// price, whose path in the request is given by field.
+func validateEffectiveTime(field string, effective *interval.Interval) []apierror.FieldViolation {
+	for _, t := range []*timestamppb.Timestamp{effective.GetStartTime(), effective.GetEndTime()} {
+		if err := t.CheckValid(); t != nil && err != nil {
+			return []apierror.FieldViolation{invalidField(field, ReasonInvalidEffectiveTime,
+				fmt.Errorf("invalid effective_time: %w", err))}
+		}
+	}
+	if !before(effective.GetStartTime(), effective.GetEndTime()) {
+		return []apierror.FieldViolation{invalidField(field, ReasonInvalidEffectiveTime,
+			errors.New("effective_time must end after it starts"))}
+	}
+	return nil
+}
+
+// validateAmount checks that an amount of a price is a non-negative amount
+// of money in an ISO 4217 currency.
+func validateAmount(field string, m *money.Money) error {
+	if !currencyCodePattern.MatchString(m.GetCurrencyCode()) {
+		return fmt.Errorf("invalid %s: currency_code %q must be an ISO 4217 code such as USD", field, m.GetCurrencyCode())
+	}
+	if m.GetUnits() < 0 || m.GetNanos() < 0 || m.GetNanos() >= nanosPerUnit {
+		return fmt.Errorf("invalid %s: units must not be negative and nanos must be between 0 and 999,999,999", field)
+	}
+	if m.GetUnits() > math.MaxInt64/nanosPerUnit-1 {
+		return fmt.Errorf("invalid %s: too large", field)
+	}
+	return nil
+}
+
+// moneyNanos returns a validated amount of money in nanos.
+func moneyNanos(m *money.Money) int64 {
+	return m.GetUnits()*nanosPerUnit + int64(m.GetNanos())
+}
+
+// priceRateCard returns the rate card that prices events with price. Its
+// version is the name of the price.
+func priceRateCard(price *usagev1.Price) RateCard {
+	var currency string
+	for _, m := range []*money.Money{price.GetPerCall(), price.GetPerSecond()} {
+		if m != nil {
+			currency = m.GetCurrencyCode()
+			break
+		}
+	}
+	return RateCard{
+		Version:           price.GetName(),
+		CurrencyCode:      currency,
+		PerCall:           moneyNanos(price.GetPerCall()),
+		PerSecond:         moneyNanos(price.GetPerSecond()),
+		Minimum:           moneyNanos(price.GetMinimum()),
+		BillingIncrement:  price.GetBillingIncrement().AsDuration(),
+		RoundingIncrement: moneyNanos(price.GetRoundingIncrement()),
+		RoundingMode:      roundingModes[price.GetRoundingMode()],
+	}
+}
+
+// PriceParent returns the resource name of the action that the price with
+// the given resource name belongs to.
+func PriceParent(name string) string {
+	if i := strings.LastIndex(name, "/"+priceCollection+"/"); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// PriceInEffect reports whether the effective time of price contains t.
+func PriceInEffect(price *usagev1.Price, t time.Time) bool {
+	start, end := price.GetEffectiveTime().GetStartTime(), price.GetEffectiveTime().GetEndTime()
+	return (start == nil || !t.Before(start.AsTime())) && (end == nil || t.Before(end.AsTime()))
+}
+
+// before reports whether start is before end, where an unset start is the
+// beginning of time and an unset end the end of time.
+func before(start, end *timestamppb.Timestamp) bool {
+	return start == nil || end == nil || start.AsTime().Before(end.AsTime())
+}
+
+// checkOverlap returns a FAILED_PRECONDITION error if the effective time of
+// price overlaps with that of any of its siblings.
+func checkOverlap(price *usagev1.Price, siblings []*usagev1.Price) error {
+	a := price.GetEffectiveTime()
+	for _, other := range siblings {
+		b := other.GetEffectiveTime()
+		if other.GetName() != price.GetName() && before(a.GetStartTime(), b.GetEndTime()) && before(b.GetStartTime(), a.GetEndTime()) {
+			return apierror.New(codes.FailedPrecondition, ReasonPricesOverlap,
+				fmt.Sprintf("effective_time of %q overlaps with that of %q", price.GetName(), other.GetName()),
+				map[string]string{"name": price.GetName(), "overlappingPrice": other.GetName()})
+		}
+	}
+	return nil
+}
+
+// CreatePrice creates a price of an action.
+func (s *PriceCatalogService) CreatePrice(ctx context.Context, req *usagev1.CreatePriceRequest) (*usagev1.CreatePriceResponse, error) {
+	if violations := s.ValidateRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	source, action, err := ParseActionName(req.GetParent())
+	if err != nil {
+		return nil, invalidArgument("parent", ReasonInvalidParent, err)
+	}
+	if s.checkSources {
+		if err := s.checkAction(ctx, source, action); err != nil {
+			return nil, err
+		}
+	}
+
+	price := proto.Clone(req.GetPrice()).(*usagev1.Price)
+	price.Name = PriceName(source, action, req.GetPriceId())
+	price.CreateTime = timestamppb.Now()
+	price.UpdateTime = nil
+	price.Etag = resourceEtag(price)
+
+	err = s.store.CreatePrice(ctx, price, func(siblings []*usagev1.Price) error {
+		return checkOverlap(price, siblings)
+	})
+	if errors.Is(err, ErrAlreadyExists) {
+		return nil, apierror.New(codes.AlreadyExists, ReasonPriceAlreadyExists,
+			fmt.Sprintf("price %q already exists", price.GetName()), map[string]string{"name": price.GetName()})
+	}
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "failed to store price: %v", err)
+	}
+	return &usagev1.CreatePriceResponse{Price: price}, nil
+}
+
+// checkAction returns a NOT_FOUND error unless the action of the source is
+// registered.
+func (s *PriceCatalogService) checkAction(ctx context.Context, source, action string) error {
+	src, err := s.store.GetSource(ctx, SourceName(source))
+	if errors.Is(err, ErrNotFound) {
+		return sourceNotFound(SourceName(source))
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get source: %v", err)
+	}
+	if !slices.Contains(src.GetActions(), action) {
+		return apierror.New(codes.NotFound, ReasonActionNotFound,
+			fmt.Sprintf("action %q is not registered for source %q", action, source),
+			map[string]string{"name": ActionName(source, action)})
+	}
+	return nil
+}
+
+// GetPrice returns a single price by its resource name.
+func (s *PriceCatalogService) GetPrice(ctx context.Context, req *usagev1.GetPriceRequest) (*usagev1.GetPriceResponse, error) {
+	if err := validatePriceName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	price, err := s.store.GetPrice(ctx, req.GetName())
+	if errors.Is(err, ErrNotFound) {
+		return nil, priceNotFound(req.GetName())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get price: %v", err)
+	}
+	return &usagev1.GetPriceResponse{Price: price}, nil
+}
+
+// ListPrices lists the prices of an action with pagination.
+func (s *PriceCatalogService) ListPrices(ctx context.Context, req *usagev1.ListPricesRequest) (*usagev1.ListPricesResponse, error) {
+	if req.GetParent() == "" {
+		return nil, apierror.BadRequest(required("parent"))
+	}
+	if err := validateActionName(req.GetParent()); err != nil {
+		return nil, invalidArgument("parent", ReasonInvalidParent, err)
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	// Page tokens are bound to the parent like those of ListEvents to their
+	// filter
+	var after string
+	if req.GetPageToken() != "" {
+		cursor, err := s.pageTokens.decode(req.GetPageToken(), req.GetParent(), false, priceOrdering)
+		if errors.Is(err, errPageTokenMismatch) {
+			return nil, invalidArgument("page_token", ReasonInvalidPageToken, errors.New("page_token was issued for a different parent"))
+		}
+		if err != nil {
+			return nil, invalidArgument("page_token", ReasonInvalidPageToken, err)
+		}
+		after = cursor.Name
+	}
+
+	// Read one extra price to find out whether there is a next page
+	prices, err := s.store.ListPrices(ctx, req.GetParent(), after, pageSize+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list prices: %v", err)
+	}
+
+	var nextPageToken string
+	if len(prices) > pageSize {
+		prices = prices[:pageSize]
+		nextPageToken, err = s.pageTokens.encode(req.GetParent(), false, priceOrdering, Cursor{Name: prices[pageSize-1].GetName()})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+
+	return &usagev1.ListPricesResponse{
+		Prices:        prices,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// UpdatePrice updates the effective time of a price.
+func (s *PriceCatalogService) UpdatePrice(ctx context.Context, req *usagev1.UpdatePriceRequest) (*usagev1.UpdatePriceResponse, error) {
+	name := req.GetPrice().GetName()
+	if err := validatePriceName("price.name", name); err != nil {
+		return nil, err
+	}
+
+	paths, err := updatePaths(req.GetPrice(), mutablePriceFields, req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, invalidArgument("update_mask", ReasonInvalidUpdateMask, fmt.Errorf("invalid update_mask: %w", err))
+	}
+	if violations := s.ValidateRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	now := time.Now()
+	price, err := s.store.UpdatePrice(ctx, name, func(price *usagev1.Price, siblings []*usagev1.Price) (*usagev1.Price, error) {
+		if err := checkEtag(price, req.GetPrice().GetEtag()); err != nil {
+			return nil, err
+		}
+		applyUpdate(price, req.GetPrice(), paths)
+		violations := fieldbehavior.CheckRequired(price)
+		if updatesAny(paths, "effective_time") {
+			// The updated fields may be fine on their own but not together
+			// with the others, e.g. an end time before the stored start time
+			violations = append(violations, validateEffectiveTime("effective_time", price.GetEffectiveTime())...)
+		}
+		if len(violations) > 0 {
+			return nil, apierror.Prefix(apierror.BadRequest(violations...), "price")
+		}
+		if err := checkOverlap(price, siblings); err != nil {
+			return nil, err
+		}
+
+		price.UpdateTime = timestamppb.New(now)
+		price.Etag = resourceEtag(price)
+		return price, nil
+	})
+	if err != nil {
+		return nil, updatePriceError(err, name)
+	}
+
+	return &usagev1.UpdatePriceResponse{Price: price}, nil
+}
+
+// DeletePrice deletes a price. If an etag is given, it is checked against
+// the stored price first; a concurrent update between the check and the
+// deletion is not detected.
+func (s *PriceCatalogService) DeletePrice(ctx context.Context, req *usagev1.DeletePriceRequest) (*usagev1.DeletePriceResponse, error) {
+	if err := validatePriceName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	if req.GetEtag() != "" {
+		price, err := s.store.GetPrice(ctx, req.GetName())
+		if err != nil {
+			return nil, updatePriceError(err, req.GetName())
+		}
+		if err := checkEtag(price, req.GetEtag()); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.store.DeletePrice(ctx, req.GetName())
+	if errors.Is(err, ErrNotFound) {
+		return nil, priceNotFound(req.GetName())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete price: %v", err)
+	}
+	return &usagev1.DeletePriceResponse{}, nil
+}
+
+// validateActionName checks the resource name of an action, the parent of
+// its prices.
+func validateActionName(name string) error {
+	source, action, err := ParseActionName(name)
+	if err != nil {
+		return err
+	}
+	if err := ValidateSourceID(source); err != nil {
+		return fmt.Errorf("invalid parent %q: %w", name, err)
+	}
+	if err := ValidateAction(action); err != nil {
+		return fmt.Errorf("invalid parent %q: %w", name, err)
+	}
+	return nil
+}
+
+// validatePriceName checks the price name in the given field of a request.
+func validatePriceName(field, name string) error {
+	if name == "" {
+		return apierror.BadRequest(required(field))
+	}
+	if _, _, _, err := ParsePriceName(name); err != nil {
+		return invalidArgument(field, ReasonInvalidPriceName, err)
+	}
+	return nil
+}
+
+// updatePriceError converts an error of PriceStore.UpdatePrice to a status
+// error. Status errors returned by the update function are passed through.
+func updatePriceError(err error, name string) error {
+	if errors.Is(err, ErrNotFound) {
+		return priceNotFound(name)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Internal, "failed to update price: %v", err)
+}
+
+func priceNotFound(name string) error {
+	return apierror.New(codes.NotFound, ReasonPriceNotFound,
+		fmt.Sprintf("price %q not found", name), map[string]string{"name": name})
+}
+
+// rateCard returns the rate card of events of the given source and action
+// recorded at t: the price of the catalog in effect at t, or else the
+// configured rate card. It returns nil if neither exists.
+func (s *Service) rateCard(ctx context.Context, source, action string, t time.Time) (*RateCard, error) {
+	price, err := s.store.FindPrice(ctx, ActionName(source, action), t)
+	switch {
+	case err == nil:
+		card := priceRateCard(price)
+		return &card, nil
+	case !errors.Is(err, ErrNotFound):
+		return nil, status.Errorf(codes.Internal, "failed to look up price: %v", err)
+	}
+	if card, ok := s.rateCards.find(source, action); ok {
+		return &card, nil
+	}
+	return nil, nil
+}
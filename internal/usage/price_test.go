@@ -0,0 +1,216 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+const testAction = "sources/animal-classifier/actions/classify"
+
+// newTestPriceCatalog returns a price catalog on store that accepts prices
+// of any source.
+func newTestPriceCatalog(t *testing.T, store Store) *PriceCatalogService {
+	t.Helper()
+	s, err := NewPriceCatalogService(store, Config{PermissiveSources: true})
+	if err != nil {
+		t.Fatalf("NewPriceCatalogService() error = %v", err)
+	}
+	return s
+}
+
+// testPrice returns a price of one cent per call in effect from start until
+// end, either of which may be zero for an open period.
+func testPrice(start, end time.Time) *usagev1.Price {
+	effective := &interval.Interval{}
+	if !start.IsZero() {
+		effective.StartTime = timestamppb.New(start)
+	}
+	if !end.IsZero() {
+		effective.EndTime = timestamppb.New(end)
+	}
+	return &usagev1.Price{
+		EffectiveTime: effective,
+		PerCall:       &money.Money{CurrencyCode: "USD", Nanos: 10_000_000},
+	}
+}
+
+// createTestPrice creates a price of testAction with the given ID and
+// returns the created price.
+func createTestPrice(t *testing.T, s *PriceCatalogService, id string, price *usagev1.Price) *usagev1.Price {
+	t.Helper()
+	resp, err := s.CreatePrice(context.Background(), &usagev1.CreatePriceRequest{
+		Parent:  testAction,
+		PriceId: id,
+		Price:   price,
+	})
+	if err != nil {
+		t.Fatalf("CreatePrice(%s) error = %v", id, err)
+	}
+	return resp.GetPrice()
+}
+
+func TestBatchCreateEventsPricesEachEvent(t *testing.T) {
+	store := NewMemoryStore()
+	s := newTestServiceWithStore(t, store, Config{})
+	catalog := newTestPriceCatalog(t, store)
+
+	// The events of the batch are created one resolution step after
+	// another from just after last, and the price changes after the first
+	last := time.Now().Add(time.Hour).Truncate(createTimeResolution)
+	s.watchers.last = last
+	change := last.Add(2 * createTimeResolution)
+	old := createTestPrice(t, catalog, "old", testPrice(time.Time{}, change))
+	current := createTestPrice(t, catalog, "current", testPrice(change, time.Time{}))
+
+	resp, err := s.BatchCreateEvents(context.Background(), &usagev1.BatchCreateEventsRequest{
+		Requests: []*usagev1.CreateEventRequest{
+			{Event: testEvent("users/alice", "classify", time.Second)},
+			{Event: testEvent("users/alice", "classify", time.Second)},
+			{Event: testEvent("users/alice", "classify", time.Second)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchCreateEvents() error = %v", err)
+	}
+	for i, want := range []string{old.GetName(), current.GetName(), current.GetName()} {
+		event := resp.GetEvents()[i]
+		if event.GetPriceVersion() != want {
+			t.Errorf("event %d created at %v priced with %q, want %q",
+				i, event.GetCreateTime().AsTime(), event.GetPriceVersion(), want)
+		}
+	}
+}
+
+func TestCreatePriceRejectsOverlaps(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	var open time.Time
+
+	// Every price is created next to one in effect in January
+	for _, tc := range []struct {
+		name       string
+		start, end time.Time
+		code       codes.Code
+		reason     string
+	}{
+		{"adjacent before", day(12, 1).AddDate(-1, 0, 0), day(1, 1), codes.OK, ""},
+		{"adjacent after", day(2, 1), day(3, 1), codes.OK, ""},
+		{"open start adjacent", open, day(1, 1), codes.OK, ""},
+		{"open end adjacent", day(2, 1), open, codes.OK, ""},
+		{"overlapping start", day(12, 15).AddDate(-1, 0, 0), day(1, 15), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"overlapping end", day(1, 31), day(2, 15), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"contained", day(1, 10), day(1, 20), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"containing", day(12, 1).AddDate(-1, 0, 0), day(3, 1), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"same", day(1, 1), day(2, 1), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"open start overlapping", open, day(1, 2), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"open end overlapping", day(1, 31), open, codes.FailedPrecondition, ReasonPricesOverlap},
+		{"open", open, open, codes.FailedPrecondition, ReasonPricesOverlap},
+		{"ending before it starts", day(3, 1), day(2, 1), codes.InvalidArgument, ReasonInvalidEffectiveTime},
+		{"empty", day(3, 1), day(3, 1), codes.InvalidArgument, ReasonInvalidEffectiveTime},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestPriceCatalog(t, NewMemoryStore())
+			january := createTestPrice(t, s, "january", testPrice(day(1, 1), day(2, 1)))
+
+			_, err := s.CreatePrice(context.Background(), &usagev1.CreatePriceRequest{
+				Parent:  testAction,
+				PriceId: "other",
+				Price:   testPrice(tc.start, tc.end),
+			})
+			if code, reason, _ := errorReason(err); code != tc.code || reason != tc.reason {
+				t.Errorf("CreatePrice() error = %v, want %v %s", err, tc.code, tc.reason)
+			}
+			if tc.reason == ReasonPricesOverlap {
+				for _, d := range status.Convert(err).Details() {
+					if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetMetadata()["overlappingPrice"] != january.GetName() {
+						t.Errorf("CreatePrice() error metadata = %v, want the overlapping price %s", info.GetMetadata(), january.GetName())
+					}
+				}
+			}
+
+			// The prices of other actions do not overlap
+			_, err = s.CreatePrice(context.Background(), &usagev1.CreatePriceRequest{
+				Parent:  "sources/animal-classifier/actions/detect",
+				PriceId: "other",
+				Price:   testPrice(day(1, 1), day(2, 1)),
+			})
+			if err != nil {
+				t.Errorf("CreatePrice() of another action error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdatePriceRejectsOverlaps(t *testing.T) {
+	day := func(month time.Month, d int) *timestamppb.Timestamp {
+		return timestamppb.New(time.Date(2025, month, d, 0, 0, 0, 0, time.UTC))
+	}
+
+	// A price in effect in January is followed by one from February on
+	for _, tc := range []struct {
+		name   string
+		price  string
+		path   string
+		value  *timestamppb.Timestamp
+		code   codes.Code
+		reason string
+	}{
+		{"end earlier", "january", "effective_time.end_time", day(1, 20), codes.OK, ""},
+		{"start later", "february", "effective_time.start_time", day(2, 10), codes.OK, ""},
+		{"start earlier", "january", "effective_time.start_time", timestamppb.New(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)), codes.OK, ""},
+		{"end later", "january", "effective_time.end_time", day(2, 2), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"end opened", "january", "effective_time.end_time", nil, codes.FailedPrecondition, ReasonPricesOverlap},
+		{"start earlier into the other", "february", "effective_time.start_time", day(1, 31), codes.FailedPrecondition, ReasonPricesOverlap},
+		{"start opened", "february", "effective_time.start_time", nil, codes.FailedPrecondition, ReasonPricesOverlap},
+		{"end at the stored start", "january", "effective_time.end_time", day(1, 1), codes.InvalidArgument, ReasonInvalidEffectiveTime},
+		{"start after the stored end", "january", "effective_time.start_time", day(2, 1), codes.InvalidArgument, ReasonInvalidEffectiveTime},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestPriceCatalog(t, NewMemoryStore())
+			prices := map[string]*usagev1.Price{
+				"january":  createTestPrice(t, s, "january", testPrice(day(1, 1).AsTime(), day(2, 1).AsTime())),
+				"february": createTestPrice(t, s, "february", testPrice(day(2, 1).AsTime(), time.Time{})),
+			}
+			price := prices[tc.price]
+
+			update := &usagev1.Price{Name: price.GetName(), EffectiveTime: &interval.Interval{}}
+			if tc.path == "effective_time.start_time" {
+				update.EffectiveTime.StartTime = tc.value
+			} else {
+				update.EffectiveTime.EndTime = tc.value
+			}
+			resp, err := s.UpdatePrice(ctx, &usagev1.UpdatePriceRequest{
+				Price:      update,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{tc.path}},
+			})
+			if code, reason, _ := errorReason(err); code != tc.code || reason != tc.reason {
+				t.Fatalf("UpdatePrice() error = %v, want %v %s", err, tc.code, tc.reason)
+			}
+
+			// A rejected update leaves the price as it was
+			want := price
+			if err == nil {
+				want = resp.GetPrice()
+			}
+			got, err := s.GetPrice(ctx, &usagev1.GetPriceRequest{Name: price.GetName()})
+			if err != nil {
+				t.Fatalf("GetPrice() error = %v", err)
+			}
+			if !proto.Equal(got.GetPrice(), want) {
+				t.Errorf("GetPrice() after update = %v, want %v", got.GetPrice(), want)
+			}
+		})
+	}
+}
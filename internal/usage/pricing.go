@@ -0,0 +1,259 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/money"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// nanosPerUnit is the number of nanos, the smallest amounts of money
+// representable by google.type.Money, in a unit of a currency.
+const nanosPerUnit = 1_000_000_000
+
+// RoundingMode is how charges are rounded to the rounding increment of a
+// RateCard.
+type RoundingMode string
+
+// Supported rounding modes.
+const (
+	// RoundHalfUp rounds to the nearest increment, and halves up.
+	RoundHalfUp RoundingMode = "HALF_UP"
+	// RoundUp rounds up to the next increment.
+	RoundUp RoundingMode = "UP"
+	// RoundDown rounds down to the previous increment.
+	RoundDown RoundingMode = "DOWN"
+)
+
+// RateCard prices the events of a source, or of one of its actions. Amounts
+// are in nanos of the currency.
+type RateCard struct {
+	// Version identifies the rate card in the price_version of the events
+	// priced with it, e.g. "2025-01".
+	Version string
+	// CurrencyCode is the ISO 4217 code of the currency, e.g. "USD".
+	CurrencyCode string
+	// PerCall is charged for every event.
+	PerCall int64
+	// PerSecond is charged per second of execution duration.
+	PerSecond int64
+	// Minimum is the least an event is charged.
+	Minimum int64
+	// BillingIncrement is the granularity of billed execution durations,
+	// which are rounded up to a multiple of it. Zero bills exact durations.
+	BillingIncrement time.Duration
+	// RoundingIncrement is the precision of charges, e.g. 10000000 for
+	// cents. Zero rounds to whole nanos.
+	RoundingIncrement int64
+	// RoundingMode rounds charges to the rounding increment. Empty means
+	// RoundHalfUp.
+	RoundingMode RoundingMode
+}
+
+// Charge returns the amount charged for an event with the given execution
+// duration, in nanos.
+func (c RateCard) Charge(d time.Duration) (int64, error) {
+	if inc := c.BillingIncrement; inc > 0 && d%inc != 0 {
+		d += inc - d%inc
+	}
+
+	// Nanos per second times nanoseconds are billionths of nanos
+	amount := new(big.Int).Mul(big.NewInt(c.PerSecond), big.NewInt(int64(d)))
+	amount.Add(amount, new(big.Int).Mul(big.NewInt(c.PerCall), big.NewInt(nanosPerUnit)))
+
+	increment := max(c.RoundingIncrement, 1)
+	divisor := new(big.Int).Mul(big.NewInt(increment), big.NewInt(nanosPerUnit))
+	q, r := new(big.Int).QuoRem(amount, divisor, new(big.Int))
+	switch c.RoundingMode {
+	case RoundUp:
+		if r.Sign() > 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	case RoundDown:
+	default:
+		if r.Lsh(r, 1).Cmp(divisor) >= 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	q.Mul(q, big.NewInt(increment))
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("charge for %v overflows", d)
+	}
+	return max(q.Int64(), c.Minimum), nil
+}
+
+// Cost returns the cost of an event with the given execution duration.
+func (c RateCard) Cost(d time.Duration) (*money.Money, error) {
+	nanos, err := c.Charge(d)
+	if err != nil {
+		return nil, err
+	}
+	return &money.Money{
+		CurrencyCode: c.CurrencyCode,
+		Units:        nanos / nanosPerUnit,
+		Nanos:        int32(nanos % nanosPerUnit),
+	}, nil
+}
+
+// RateCards are the rate cards of sources and actions, keyed by
+// "source/action", or by "source" for all actions of a source. The more
+// specific key wins. They price the events of actions without a price in
+// the price catalog.
+type RateCards map[string]RateCard
+
+// find returns the rate card of events with the given source and action.
+func (r RateCards) find(source, action string) (RateCard, bool) {
+	if c, ok := r[source+"/"+action]; ok {
+		return c, true
+	}
+	c, ok := r[source]
+	return c, ok
+}
+
+// setCost sets the cost and price version of event using card, or clears
+// them if card is nil.
+func setCost(event *usagev1.Event, card *RateCard) error {
+	event.Cost, event.PriceVersion = nil, ""
+	if card == nil {
+		return nil
+	}
+	cost, err := card.Cost(event.GetExecutionDuration().AsDuration())
+	if err != nil {
+		return err
+	}
+	event.Cost, event.PriceVersion = cost, card.Version
+	return nil
+}
+
+// rateCardJSON is the JSON format of a rate card, see ParseRateCards.
+type rateCardJSON struct {
+	Source            string `json:"source"`
+	Action            string `json:"action"`
+	Version           string `json:"version"`
+	CurrencyCode      string `json:"currency_code"`
+	PerCall           string `json:"per_call"`
+	PerSecond         string `json:"per_second"`
+	Minimum           string `json:"minimum"`
+	BillingIncrement  string `json:"billing_increment"`
+	RoundingIncrement string `json:"rounding_increment"`
+	RoundingMode      string `json:"rounding_mode"`
+}
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ParseRateCards parses rate cards from JSON such as
+//
+//	{"rate_cards": [{
+//	  "source": "animal-classifier", "action": "classify",
+//	  "version": "2025-01", "currency_code": "USD",
+//	  "per_call": "0.001", "per_second": "0.0005", "minimum": "0.001",
+//	  "billing_increment": "100ms",
+//	  "rounding_increment": "0.0001", "rounding_mode": "UP"
+//	}]}
+//
+// Amounts are decimal strings with up to 9 fractional digits. A rate card
+// without an action applies to all actions of its source. Only source,
+// version and currency_code are required.
+func ParseRateCards(data []byte) (RateCards, error) {
+	var file struct {
+		RateCards []rateCardJSON `json:"rate_cards"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	cards := make(RateCards, len(file.RateCards))
+	for i, f := range file.RateCards {
+		key := f.Source
+		if f.Action != "" {
+			key += "/" + f.Action
+		}
+		if _, ok := cards[key]; ok {
+			return nil, fmt.Errorf("rate card %d: duplicate rate card for %q", i, key)
+		}
+		card, err := f.rateCard()
+		if err != nil {
+			return nil, fmt.Errorf("rate card %d (%s): %w", i, key, err)
+		}
+		cards[key] = card
+	}
+	return cards, nil
+}
+
+func (f rateCardJSON) rateCard() (RateCard, error) {
+	if err := ValidateSourceID(f.Source); err != nil {
+		return RateCard{}, err
+	}
+	if f.Action != "" {
+		if err := ValidateAction(f.Action); err != nil {
+			return RateCard{}, err
+		}
+	}
+	if f.Version == "" || len(f.Version) > 63 {
+		return RateCard{}, errors.New("version must be 1-63 characters")
+	}
+	if !currencyCodePattern.MatchString(f.CurrencyCode) {
+		return RateCard{}, fmt.Errorf("invalid currency_code %q: must be an ISO 4217 code such as USD", f.CurrencyCode)
+	}
+
+	card := RateCard{
+		Version:      f.Version,
+		CurrencyCode: f.CurrencyCode,
+		RoundingMode: RoundingMode(f.RoundingMode),
+	}
+	var err error
+	if card.PerCall, err = parseAmount("per_call", f.PerCall); err != nil {
+		return RateCard{}, err
+	}
+	if card.PerSecond, err = parseAmount("per_second", f.PerSecond); err != nil {
+		return RateCard{}, err
+	}
+	if card.Minimum, err = parseAmount("minimum", f.Minimum); err != nil {
+		return RateCard{}, err
+	}
+	if card.RoundingIncrement, err = parseAmount("rounding_increment", f.RoundingIncrement); err != nil {
+		return RateCard{}, err
+	}
+	if f.BillingIncrement != "" {
+		if card.BillingIncrement, err = time.ParseDuration(f.BillingIncrement); err != nil {
+			return RateCard{}, fmt.Errorf("invalid billing_increment: %w", err)
+		}
+		if card.BillingIncrement < 0 {
+			return RateCard{}, errors.New("billing_increment must not be negative")
+		}
+	}
+	switch card.RoundingMode {
+	case "", RoundHalfUp, RoundUp, RoundDown:
+	default:
+		return RateCard{}, fmt.Errorf("unknown rounding_mode %q, expected %s, %s or %s",
+			f.RoundingMode, RoundHalfUp, RoundUp, RoundDown)
+	}
+	return card, nil
+}
+
+// parseAmount parses a non-negative decimal amount of money, e.g. "0.0005",
+// into nanos. An empty string is zero.
+func parseAmount(field, s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	units, frac, _ := strings.Cut(s, ".")
+	if units == "" || len(frac) > 9 || strings.Trim(units+frac, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative decimal with up to 9 fractional digits", field, s)
+	}
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || u > math.MaxInt64/nanosPerUnit-1 {
+		return 0, fmt.Errorf("invalid %s %q: too large", field, s)
+	}
+	n, _ := strconv.ParseInt((frac + "000000000")[:9], 10, 64)
+	return u*nanosPerUnit + n, nil
+}
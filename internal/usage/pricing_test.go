@@ -0,0 +1,202 @@
+package usage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRateCardCharge(t *testing.T) {
+	const cent = 10_000_000
+	for _, tc := range []struct {
+		name string
+		card RateCard
+		d    time.Duration
+		want int64
+	}{
+		{"per second", RateCard{PerSecond: 500_000}, 1500 * time.Millisecond, 750_000},
+		{"per call and per second", RateCard{PerCall: 1_000_000, PerSecond: 500_000}, 2 * time.Second, 2_000_000},
+		{"zero duration", RateCard{PerCall: 1_000_000, PerSecond: 500_000}, 0, 1_000_000},
+		{"zero duration with billing increment", RateCard{PerSecond: 500_000, BillingIncrement: time.Second}, 0, 0},
+		{"free", RateCard{}, time.Hour, 0},
+
+		// Durations are rounded up to the billing increment
+		{"billing increment below", RateCard{PerSecond: nanosPerUnit, BillingIncrement: 100 * time.Millisecond}, time.Nanosecond, 100_000_000},
+		{"billing increment exact", RateCard{PerSecond: nanosPerUnit, BillingIncrement: 100 * time.Millisecond}, 100 * time.Millisecond, 100_000_000},
+		{"billing increment above", RateCard{PerSecond: nanosPerUnit, BillingIncrement: 100 * time.Millisecond}, 101 * time.Millisecond, 200_000_000},
+
+		// Fractions of nanos are rounded half up by default: 1 nano per
+		// second bills half a nano for 500ms
+		{"half up below half", RateCard{PerSecond: 1}, 499_999_999, 0},
+		{"half up at half", RateCard{PerSecond: 1}, 500 * time.Millisecond, 1},
+		{"half up above half", RateCard{PerSecond: 1}, 1500 * time.Millisecond, 2},
+		{"explicit half up", RateCard{PerSecond: 1, RoundingMode: RoundHalfUp}, 500 * time.Millisecond, 1},
+
+		// Rounding to cents
+		{"cents half up below", RateCard{PerCall: cent/2 - 1, RoundingIncrement: cent}, 0, 0},
+		{"cents half up at half", RateCard{PerCall: cent / 2, RoundingIncrement: cent}, 0, cent},
+		{"cents half up exact", RateCard{PerCall: 3 * cent, RoundingIncrement: cent}, 0, 3 * cent},
+		{"cents up", RateCard{PerCall: cent + 1, RoundingIncrement: cent, RoundingMode: RoundUp}, 0, 2 * cent},
+		{"cents up exact", RateCard{PerCall: cent, RoundingIncrement: cent, RoundingMode: RoundUp}, 0, cent},
+		{"cents up sub-nano remainder", RateCard{PerCall: cent, PerSecond: 1, RoundingIncrement: cent, RoundingMode: RoundUp}, time.Nanosecond, 2 * cent},
+		{"cents down", RateCard{PerCall: 2*cent - 1, RoundingIncrement: cent, RoundingMode: RoundDown}, 0, cent},
+		{"nanos up", RateCard{PerSecond: 1, RoundingMode: RoundUp}, time.Nanosecond, 1},
+		{"nanos down", RateCard{PerSecond: 1, RoundingMode: RoundDown}, 1999 * time.Millisecond, 1},
+
+		// The minimum applies after rounding
+		{"minimum", RateCard{PerSecond: 1_000, Minimum: 1_000_000}, time.Second, 1_000_000},
+		{"above minimum", RateCard{PerSecond: 1_000_000, Minimum: 1_000}, time.Second, 1_000_000},
+		{"minimum not rounded", RateCard{PerCall: 1, Minimum: 123, RoundingIncrement: cent}, 0, 123},
+
+		// Large amounts are computed exactly
+		{"large", RateCard{PerSecond: 1_000 * nanosPerUnit}, 1000 * time.Hour, 3_600_000 * 1_000 * nanosPerUnit},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.card.Charge(tc.d)
+			if err != nil {
+				t.Fatalf("Charge(%v) error = %v", tc.d, err)
+			}
+			if got != tc.want {
+				t.Errorf("Charge(%v) = %d, want %d", tc.d, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRateCardChargeOverflow(t *testing.T) {
+	card := RateCard{PerSecond: 1_000_000 * nanosPerUnit}
+	if got, err := card.Charge(100_000 * time.Hour); err == nil {
+		t.Errorf("Charge() = %d, want an overflow error", got)
+	}
+}
+
+func TestRateCardCost(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		card RateCard
+		d    time.Duration
+		want *money.Money
+	}{
+		{"nanos", RateCard{CurrencyCode: "USD", PerSecond: 500_000}, 1500 * time.Millisecond, &money.Money{CurrencyCode: "USD", Nanos: 750_000}},
+		{"units and nanos", RateCard{CurrencyCode: "EUR", PerCall: 1_250_000_000}, 0, &money.Money{CurrencyCode: "EUR", Units: 1, Nanos: 250_000_000}},
+		// Nanos that add up to a unit carry over
+		{"carry", RateCard{CurrencyCode: "USD", PerCall: 999_999_999, PerSecond: 1}, time.Second, &money.Money{CurrencyCode: "USD", Units: 1}},
+		{"rounded carry", RateCard{CurrencyCode: "USD", PerCall: 995_000_000, RoundingIncrement: 10_000_000}, 0, &money.Money{CurrencyCode: "USD", Units: 1}},
+		{"zero", RateCard{CurrencyCode: "USD"}, time.Second, &money.Money{CurrencyCode: "USD"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.card.Cost(tc.d)
+			if err != nil {
+				t.Fatalf("Cost(%v) error = %v", tc.d, err)
+			}
+			if !proto.Equal(got, tc.want) {
+				t.Errorf("Cost(%v) = %v, want %v", tc.d, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"1", nanosPerUnit},
+		{"1.", nanosPerUnit},
+		{"1.5", 1_500_000_000},
+		{"0.0005", 500_000},
+		{"0.000000001", 1},
+		{"007.10", 7_100_000_000},
+		{"12.345678901", 12_345_678_901},
+		{"9223372035.999999999", 9_223_372_035_999_999_999},
+	} {
+		got, err := parseAmount("per_call", tc.s)
+		if err != nil {
+			t.Errorf("parseAmount(%q) error = %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseAmount(%q) = %d, want %d", tc.s, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		s   string
+		msg string
+	}{
+		{".5", "must be a non-negative decimal"},
+		{"-1", "must be a non-negative decimal"},
+		{"+1", "must be a non-negative decimal"},
+		{"1.0000000001", "must be a non-negative decimal"},
+		{"1e3", "must be a non-negative decimal"},
+		{"1,5", "must be a non-negative decimal"},
+		{"1.2.3", "must be a non-negative decimal"},
+		{" 1", "must be a non-negative decimal"},
+		{"one", "must be a non-negative decimal"},
+		{"9223372036", "too large"},
+		{"99999999999999999999", "too large"},
+	} {
+		_, err := parseAmount("per_call", tc.s)
+		if err == nil || !strings.Contains(err.Error(), tc.msg) || !strings.Contains(err.Error(), "per_call") {
+			t.Errorf("parseAmount(%q) error = %v, want an error about per_call containing %q", tc.s, err, tc.msg)
+		}
+	}
+}
+
+func TestParseRateCards(t *testing.T) {
+	cards, err := ParseRateCards([]byte(`{"rate_cards": [{
+		"source": "animal-classifier", "action": "classify",
+		"version": "2025-01", "currency_code": "USD",
+		"per_call": "0.001", "per_second": "0.0005", "minimum": "0.001",
+		"billing_increment": "100ms",
+		"rounding_increment": "0.0001", "rounding_mode": "UP"
+	}, {
+		"source": "animal-classifier", "version": "2025-01", "currency_code": "USD"
+	}]}`))
+	if err != nil {
+		t.Fatalf("ParseRateCards() error = %v", err)
+	}
+	want := RateCard{
+		Version:           "2025-01",
+		CurrencyCode:      "USD",
+		PerCall:           1_000_000,
+		PerSecond:         500_000,
+		Minimum:           1_000_000,
+		BillingIncrement:  100 * time.Millisecond,
+		RoundingIncrement: 100_000,
+		RoundingMode:      RoundUp,
+	}
+	if got, ok := cards.find("animal-classifier", "classify"); !ok || got != want {
+		t.Errorf("find(classify) = %+v, %v, want %+v", got, ok, want)
+	}
+	if got, ok := cards.find("animal-classifier", "detect"); !ok || got != (RateCard{Version: "2025-01", CurrencyCode: "USD"}) {
+		t.Errorf("find(detect) = %+v, %v, want the rate card of the source", got, ok)
+	}
+	if _, ok := cards.find("other", "classify"); ok {
+		t.Errorf("find() of another source found a rate card")
+	}
+
+	for _, tc := range []struct {
+		json string
+		msg  string
+	}{
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "USD"}, {"source": "s", "version": "w", "currency_code": "USD"}]}`, "duplicate rate card"},
+		{`{"rate_cards": [{"source": "s", "currency_code": "USD"}]}`, "version must be"},
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "usd"}]}`, "invalid currency_code"},
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "USD", "per_second": "-1"}]}`, "invalid per_second"},
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "USD", "billing_increment": "-1s"}]}`, "must not be negative"},
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "USD", "billing_increment": "1 second"}]}`, "invalid billing_increment"},
+		{`{"rate_cards": [{"source": "s", "version": "v", "currency_code": "USD", "rounding_mode": "NEAREST"}]}`, "unknown rounding_mode"},
+		{`{"rate_cards": [{"source": "S!", "version": "v", "currency_code": "USD"}]}`, "rate card 0"},
+		{`{"rate_cards": {}}`, "cannot unmarshal"},
+	} {
+		if _, err := ParseRateCards([]byte(tc.json)); err == nil || !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("ParseRateCards(%s) error = %v, want an error containing %q", tc.json, err, tc.msg)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// purgeInterval is how often RunPurger purges deleted events.
+const purgeInterval = 5 * time.Minute
+
+// RunPurger permanently removes deleted events once their purge time has
+// passed, until ctx is canceled.
+func (s *Service) RunPurger(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		n, err := s.store.PurgeEvents(ctx, time.Now())
+		if err != nil {
+			log.Printf("Failed to purge deleted events: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d deleted events", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
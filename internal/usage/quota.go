@@ -0,0 +1,433 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// QuotaWindow is the window of time in which usage counts against a quota.
+// Windows are hours, days or months of UTC.
+type QuotaWindow string
+
+// Supported quota windows.
+const (
+	QuotaHour  QuotaWindow = "HOUR"
+	QuotaDay   QuotaWindow = "DAY"
+	QuotaMonth QuotaWindow = "MONTH"
+)
+
+// bounds returns the window containing t.
+func (w QuotaWindow) bounds(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	switch w {
+	case QuotaHour:
+		start = t.Truncate(time.Hour)
+		return start, start.Add(time.Hour)
+	case QuotaDay:
+		start = t.Truncate(24 * time.Hour)
+		return start, start.AddDate(0, 0, 1)
+	default:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// granularity returns the granularity of the rollups windows are made of.
+func (w QuotaWindow) granularity() Granularity {
+	if w == QuotaHour {
+		return RollupHour
+	}
+	return RollupDay
+}
+
+// Quota limits the usage of each subject it applies to in a window of time.
+// Usage is read from the rollups of the subject, see Rollup.
+type Quota struct {
+	// ID identifies the quota in CheckQuota responses and QuotaFailure
+	// details, e.g. "free-daily-classifications".
+	ID string
+	// Subject is a pattern of the subjects the quota applies to, in which `*`
+	// stands for any sequence of characters, e.g. "users/*". Empty applies
+	// to all subjects. Each subject has a quota of its own.
+	Subject string
+	// Source and Action, if set, restrict the quota to the events of the
+	// source and action, which are then the only ones counted.
+	Source string
+	Action string
+	Window QuotaWindow
+	// MaxEvents is the number of events allowed in a window. Zero means no
+	// limit.
+	MaxEvents int64
+	// MaxExecutionDuration is the total execution duration allowed in a
+	// window. Zero means no limit.
+	MaxExecutionDuration time.Duration
+}
+
+// appliesTo reports whether q applies to the events of a subject, source and
+// action.
+func (q *Quota) appliesTo(subject, source, action string) bool {
+	return (q.Subject == "" || wildcardMatcher(q.Subject)(subject)) &&
+		(q.Source == "" || q.Source == source) &&
+		(q.Action == "" || q.Action == action)
+}
+
+// Quotas are the quotas enforced by the server. All quotas that apply to an
+// event must have room for it.
+type Quotas []Quota
+
+// quotaJSON is the JSON format of a quota, see ParseQuotas.
+type quotaJSON struct {
+	ID                   string `json:"id"`
+	Subject              string `json:"subject"`
+	Source               string `json:"source"`
+	Action               string `json:"action"`
+	Window               string `json:"window"`
+	MaxEvents            int64  `json:"max_events"`
+	MaxExecutionDuration string `json:"max_execution_duration"`
+}
+
+// ParseQuotas parses quotas from JSON such as
+//
+//	{"quotas": [{
+//	  "id": "free-daily-classifications", "subject": "users/*",
+//	  "source": "animal-classifier", "action": "classify",
+//	  "window": "DAY", "max_events": 100, "max_execution_duration": "10m"
+//	}]}
+//
+// The window is HOUR, DAY or MONTH. Only id, window and at least one of the
+// limits are required.
+func ParseQuotas(data []byte) (Quotas, error) {
+	var file struct {
+		Quotas []quotaJSON `json:"quotas"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	quotas := make(Quotas, 0, len(file.Quotas))
+	ids := make(map[string]bool)
+	for i, f := range file.Quotas {
+		if ids[f.ID] {
+			return nil, fmt.Errorf("quota %d: duplicate quota %q", i, f.ID)
+		}
+		ids[f.ID] = true
+		quota, err := f.quota()
+		if err != nil {
+			return nil, fmt.Errorf("quota %d (%s): %w", i, f.ID, err)
+		}
+		quotas = append(quotas, quota)
+	}
+	return quotas, nil
+}
+
+func (f quotaJSON) quota() (Quota, error) {
+	if !resourceIDPattern.MatchString(f.ID) {
+		return Quota{}, fmt.Errorf("invalid id %q: must be %s", f.ID, resourceIDRules)
+	}
+	if f.Subject != "" && !strings.Contains(f.Subject, "*") {
+		if err := ValidateSubject(f.Subject); err != nil {
+			return Quota{}, err
+		}
+	}
+	if f.Source != "" {
+		if err := ValidateSourceID(f.Source); err != nil {
+			return Quota{}, err
+		}
+	}
+	if f.Action != "" {
+		if err := ValidateAction(f.Action); err != nil {
+			return Quota{}, err
+		}
+	}
+
+	quota := Quota{
+		ID:        f.ID,
+		Subject:   f.Subject,
+		Source:    f.Source,
+		Action:    f.Action,
+		Window:    QuotaWindow(f.Window),
+		MaxEvents: f.MaxEvents,
+	}
+	switch quota.Window {
+	case QuotaHour, QuotaDay, QuotaMonth:
+	default:
+		return Quota{}, fmt.Errorf("unknown window %q, expected %s, %s or %s", f.Window, QuotaHour, QuotaDay, QuotaMonth)
+	}
+	if f.MaxExecutionDuration != "" {
+		var err error
+		if quota.MaxExecutionDuration, err = time.ParseDuration(f.MaxExecutionDuration); err != nil {
+			return Quota{}, fmt.Errorf("invalid max_execution_duration: %w", err)
+		}
+	}
+	if quota.MaxEvents < 0 || quota.MaxExecutionDuration < 0 {
+		return Quota{}, errors.New("limits must not be negative")
+	}
+	if quota.MaxEvents == 0 && quota.MaxExecutionDuration == 0 {
+		return Quota{}, errors.New("max_events or max_execution_duration is required")
+	}
+	return quota, nil
+}
+
+// quotaUsage is the usage of a quota by a subject in a window.
+type quotaUsage struct {
+	quota      *Quota
+	start, end time.Time
+	events     int64
+	duration   time.Duration
+}
+
+// exhausted reports whether the usage has reached a limit of the quota.
+func (u *quotaUsage) exhausted() bool {
+	return u.quota.MaxEvents > 0 && u.events >= u.quota.MaxEvents ||
+		u.quota.MaxExecutionDuration > 0 && u.duration >= u.quota.MaxExecutionDuration
+}
+
+// violations returns the limits of the quota that subject has reached.
+func (u *quotaUsage) violations(subject string) []apierror.QuotaViolation {
+	q := u.quota
+	dimensions := map[string]string{"window": string(q.Window)}
+	if q.Source != "" {
+		dimensions["source"] = q.Source
+	}
+	if q.Action != "" {
+		dimensions["action"] = q.Action
+	}
+
+	var violations []apierror.QuotaViolation
+	if q.MaxEvents > 0 && u.events >= q.MaxEvents {
+		violations = append(violations, apierror.QuotaViolation{
+			Subject: subject,
+			Description: fmt.Sprintf("quota %q of %d events per %s is exhausted until %s",
+				q.ID, q.MaxEvents, strings.ToLower(string(q.Window)), u.end.Format(time.RFC3339)),
+			QuotaID:         q.ID,
+			QuotaMetric:     "events",
+			QuotaDimensions: dimensions,
+			QuotaValue:      q.MaxEvents,
+		})
+	}
+	if q.MaxExecutionDuration > 0 && u.duration >= q.MaxExecutionDuration {
+		violations = append(violations, apierror.QuotaViolation{
+			Subject: subject,
+			Description: fmt.Sprintf("quota %q of %v of execution duration per %s is exhausted until %s",
+				q.ID, q.MaxExecutionDuration, strings.ToLower(string(q.Window)), u.end.Format(time.RFC3339)),
+			QuotaID:         q.ID,
+			QuotaMetric:     "execution_duration_ms",
+			QuotaDimensions: dimensions,
+			QuotaValue:      q.MaxExecutionDuration.Milliseconds(),
+		})
+	}
+	return violations
+}
+
+// proto returns the usage as reported by CheckQuota.
+func (u *quotaUsage) proto() *usagev1.QuotaUsage {
+	usage := &usagev1.QuotaUsage{
+		QuotaId: u.quota.ID,
+		Window: &interval.Interval{
+			StartTime: timestamppb.New(u.start),
+			EndTime:   timestamppb.New(u.end),
+		},
+		EventLimit:             u.quota.MaxEvents,
+		EventCount:             u.events,
+		TotalExecutionDuration: durationpb.New(u.duration),
+		Exhausted:              u.exhausted(),
+	}
+	if u.quota.MaxExecutionDuration > 0 {
+		usage.ExecutionDurationLimit = durationpb.New(u.quota.MaxExecutionDuration)
+	}
+	return usage
+}
+
+// quotaUsage reads the usage of quota by subject in the window containing t
+// from the rollups.
+func (s *Service) quotaUsage(ctx context.Context, quota *Quota, subject string, t time.Time) (*quotaUsage, error) {
+	start, end := quota.Window.bounds(t)
+	rollups, err := s.store.ListRollups(ctx, RollupQuery{
+		Granularity: quota.Window.granularity(),
+		Start:       start,
+		End:         end,
+		Subject:     subject,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read quota usage: %v", err)
+	}
+	usage := &quotaUsage{quota: quota, start: start, end: end}
+	for _, r := range rollups {
+		if quota.appliesTo(r.Subject, r.Source, r.Action) {
+			usage.events += r.EventCount
+			usage.duration += r.TotalDuration
+		}
+	}
+	return usage, nil
+}
+
+// CheckQuota reports the usage of the quotas that apply to the events of a
+// subject, source and action.
+func (s *Service) CheckQuota(ctx context.Context, req *usagev1.CheckQuotaRequest) (*usagev1.CheckQuotaResponse, error) {
+	now := time.Now()
+	resp := &usagev1.CheckQuotaResponse{Allowed: true}
+	for i := range s.quotas {
+		quota := &s.quotas[i]
+		if !quota.appliesTo(req.GetSubject(), req.GetSource(), req.GetAction()) {
+			continue
+		}
+		usage, err := s.quotaUsage(ctx, quota, req.GetSubject(), now)
+		if err != nil {
+			return nil, err
+		}
+		resp.Quotas = append(resp.Quotas, usage.proto())
+		if usage.exhausted() {
+			resp.Allowed = false
+		}
+	}
+	return resp, nil
+}
+
+// validateCheckQuotaRequest returns the violations of the fields of a
+// CheckQuota request. Field behaviors are enforced separately, see package
+// fieldbehavior.
+func validateCheckQuotaRequest(req *usagev1.CheckQuotaRequest) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+	if req.GetSubject() != "" {
+		if err := ValidateSubject(req.GetSubject()); err != nil {
+			violations = append(violations, invalidField("subject", ReasonInvalidSubject, err))
+		}
+	}
+	if req.GetSource() != "" {
+		if err := ValidateSourceID(req.GetSource()); err != nil {
+			violations = append(violations, invalidField("source", ReasonInvalidSourceID, err))
+		}
+	}
+	if req.GetAction() != "" {
+		if err := ValidateAction(req.GetAction()); err != nil {
+			violations = append(violations, invalidField("action", ReasonInvalidAction, err))
+		}
+	}
+	return violations
+}
+
+// subjectLocks serialize the admission of the events of each subject. An
+// admitted event counts against the quotas once the rollups include it, so
+// the lock of its subject is held until then; otherwise concurrent requests
+// would all be checked against the same usage and could exceed a quota
+// together.
+type subjectLocks struct {
+	mu    sync.Mutex
+	locks map[string]*subjectLock
+}
+
+type subjectLock struct {
+	mu   sync.Mutex
+	refs int // number of holders and waiters, guarded by subjectLocks.mu
+}
+
+func newSubjectLocks() *subjectLocks {
+	return &subjectLocks{locks: make(map[string]*subjectLock)}
+}
+
+// lock locks the given subjects and returns a function that unlocks them.
+// Subjects are locked in sorted order so that concurrent batches cannot
+// deadlock.
+func (l *subjectLocks) lock(subjects ...string) (unlock func()) {
+	subjects = slices.Clone(subjects)
+	slices.Sort(subjects)
+	subjects = slices.Compact(subjects)
+
+	locks := make([]*subjectLock, len(subjects))
+	l.mu.Lock()
+	for i, subject := range subjects {
+		lock, ok := l.locks[subject]
+		if !ok {
+			lock = &subjectLock{}
+			l.locks[subject] = lock
+		}
+		lock.refs++
+		locks[i] = lock
+	}
+	l.mu.Unlock()
+
+	for _, lock := range locks {
+		lock.mu.Lock()
+	}
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, lock := range locks {
+			lock.mu.Unlock()
+			if lock.refs--; lock.refs == 0 {
+				delete(l.locks, subjects[i])
+			}
+		}
+	}
+}
+
+// quotaKey identifies the usage of a quota by a subject in a window.
+type quotaKey struct {
+	quota   int // index in Service.quotas
+	subject string
+	start   time.Time
+}
+
+// quotaTracker checks events against the quotas, and counts the events it
+// admits, so that the events of a batch cannot exceed a quota together.
+// Usage is read once per quota, subject and window.
+type quotaTracker struct {
+	s     *Service
+	usage map[quotaKey]*quotaUsage
+}
+
+func (s *Service) newQuotaTracker() *quotaTracker {
+	return &quotaTracker{s: s, usage: make(map[quotaKey]*quotaUsage)}
+}
+
+// admit returns a RESOURCE_EXHAUSTED error if a quota that applies to event
+// is exhausted, and counts event against its quotas otherwise.
+func (t *quotaTracker) admit(ctx context.Context, event *usagev1.Event) error {
+	var (
+		usages     []*quotaUsage
+		violations []apierror.QuotaViolation
+	)
+	for i := range t.s.quotas {
+		quota := &t.s.quotas[i]
+		if !quota.appliesTo(event.GetSubject(), event.GetSource(), event.GetAction()) {
+			continue
+		}
+		createTime := event.GetCreateTime().AsTime()
+		start, _ := quota.Window.bounds(createTime)
+		key := quotaKey{quota: i, subject: event.GetSubject(), start: start}
+		usage, ok := t.usage[key]
+		if !ok {
+			var err error
+			if usage, err = t.s.quotaUsage(ctx, quota, event.GetSubject(), createTime); err != nil {
+				return err
+			}
+			t.usage[key] = usage
+		}
+		violations = append(violations, usage.violations(event.GetSubject())...)
+		usages = append(usages, usage)
+	}
+	if len(violations) > 0 {
+		return quotaExceeded(event.GetSubject(), violations)
+	}
+
+	for _, usage := range usages {
+		usage.events++
+		usage.duration += event.GetExecutionDuration().AsDuration()
+	}
+	return nil
+}
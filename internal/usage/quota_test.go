@@ -0,0 +1,317 @@
+package usage
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+func TestQuotaWindowBounds(t *testing.T) {
+	prague, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	for _, tc := range []struct {
+		window     QuotaWindow
+		t          time.Time
+		start, end string
+	}{
+		{QuotaHour, time.Date(2025, 3, 31, 18, 59, 59, 999999999, time.UTC), "2025-03-31T18:00:00Z", "2025-03-31T19:00:00Z"},
+		{QuotaHour, time.Date(2025, 3, 31, 19, 0, 0, 0, time.UTC), "2025-03-31T19:00:00Z", "2025-03-31T20:00:00Z"},
+		{QuotaDay, time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC), "2025-03-31T00:00:00Z", "2025-04-01T00:00:00Z"},
+		{QuotaMonth, time.Date(2025, 2, 28, 23, 59, 59, 0, time.UTC), "2025-02-01T00:00:00Z", "2025-03-01T00:00:00Z"},
+		{QuotaMonth, time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), "2024-12-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+
+		// Windows are of UTC whatever the zone of t
+		{QuotaDay, time.Date(2025, 4, 1, 1, 0, 0, 0, prague), "2025-03-31T00:00:00Z", "2025-04-01T00:00:00Z"},
+		{QuotaMonth, time.Date(2025, 4, 1, 1, 0, 0, 0, prague), "2025-03-01T00:00:00Z", "2025-04-01T00:00:00Z"},
+	} {
+		start, end := tc.window.bounds(tc.t)
+		if got := [2]string{start.Format(time.RFC3339), end.Format(time.RFC3339)}; got != [2]string{tc.start, tc.end} {
+			t.Errorf("%s.bounds(%v) = [%s, %s), want [%s, %s)", tc.window, tc.t, got[0], got[1], tc.start, tc.end)
+		}
+	}
+}
+
+func TestQuotaAppliesTo(t *testing.T) {
+	for _, tc := range []struct {
+		quota                   Quota
+		subject, source, action string
+		want                    bool
+	}{
+		{Quota{}, "users/alice", "animal-classifier", "classify", true},
+		{Quota{Subject: "users/*"}, "users/alice", "animal-classifier", "classify", true},
+		{Quota{Subject: "users/*"}, "service-accounts/etl", "animal-classifier", "classify", false},
+		{Quota{Subject: "users/alice"}, "users/alice2", "animal-classifier", "classify", false},
+		{Quota{Source: "animal-classifier"}, "users/alice", "animal-classifier", "detect", true},
+		{Quota{Source: "animal-classifier"}, "users/alice", "object-detector", "detect", false},
+		{Quota{Source: "animal-classifier", Action: "classify"}, "users/alice", "animal-classifier", "detect", false},
+	} {
+		if got := tc.quota.appliesTo(tc.subject, tc.source, tc.action); got != tc.want {
+			t.Errorf("%+v.appliesTo(%s, %s, %s) = %v, want %v", tc.quota, tc.subject, tc.source, tc.action, got, tc.want)
+		}
+	}
+}
+
+// quotaIDs returns the IDs of the quotas in the QuotaFailure detail of err.
+func quotaIDs(err error) []string {
+	var ids []string
+	for _, d := range status.Convert(err).Details() {
+		if failure, ok := d.(*errdetails.QuotaFailure); ok {
+			for _, v := range failure.GetViolations() {
+				ids = append(ids, v.GetQuotaId())
+			}
+		}
+	}
+	return ids
+}
+
+func TestQuotaTrackerAdmit(t *testing.T) {
+	ctx := context.Background()
+	hour := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	// One event of alice counts against the quotas of the hour already
+	if err := store.AddRollups(ctx, eventRollups(testEventAt("users/alice", "classify", time.Second, hour.Add(10*time.Minute)), 1)); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+	s := newTestServiceWithStore(t, store, Config{Quotas: Quotas{
+		{ID: "hourly-events", Subject: "users/*", Window: QuotaHour, MaxEvents: 3},
+		{ID: "hourly-detections", Action: "detect", Window: QuotaHour, MaxExecutionDuration: 10 * time.Second},
+	}})
+	tracker := s.newQuotaTracker()
+
+	for i, tc := range []struct {
+		event *usagev1.Event
+		want  []string // IDs of the exhausted quotas
+	}{
+		// The events of a batch count against the quotas as they are
+		// admitted
+		{testEventAt("users/alice", "classify", time.Second, hour.Add(20*time.Minute)), nil},
+		{testEventAt("users/alice", "classify", time.Second, hour.Add(30*time.Minute)), nil},
+		{testEventAt("users/alice", "classify", time.Second, hour.Add(40*time.Minute)), []string{"hourly-events"}},
+		// Rejected events do not count
+		{testEventAt("users/alice", "classify", time.Second, hour.Add(50*time.Minute)), []string{"hourly-events"}},
+
+		// Each subject and window has a quota of its own
+		{testEventAt("users/bob", "classify", time.Second, hour.Add(50*time.Minute)), nil},
+		{testEventAt("users/alice", "classify", time.Second, hour.Add(time.Hour)), nil},
+
+		// A duration limit admits events until it is reached, so the last
+		// admitted one may exceed it
+		{testEventAt("service-accounts/etl", "detect", 6*time.Second, hour), nil},
+		{testEventAt("service-accounts/etl", "detect", 6*time.Second, hour), nil},
+		{testEventAt("service-accounts/etl", "detect", time.Second, hour), []string{"hourly-detections"}},
+		{testEventAt("service-accounts/etl", "classify", time.Hour, hour), nil},
+
+		// All exhausted quotas are reported
+		{testEventAt("users/bob", "detect", 10*time.Second, hour), nil},
+		{testEventAt("users/bob", "classify", time.Second, hour), nil},
+		{testEventAt("users/bob", "detect", time.Second, hour), []string{"hourly-events", "hourly-detections"}},
+	} {
+		err := tracker.admit(ctx, tc.event)
+		if tc.want == nil {
+			if err != nil {
+				t.Errorf("admit(%d) error = %v, want nil", i, err)
+			}
+			continue
+		}
+		if code, reason, _ := errorReason(err); code != codes.ResourceExhausted || reason != ReasonQuotaExceeded {
+			t.Errorf("admit(%d) error = %v, want %v with reason %s", i, err, codes.ResourceExhausted, ReasonQuotaExceeded)
+		}
+		if got := quotaIDs(err); !slices.Equal(got, tc.want) {
+			t.Errorf("admit(%d) exhausted quotas = %v, want %v", i, got, tc.want)
+		}
+	}
+
+	// A tracker reads the usage once; a new one reads it from the rollups,
+	// which do not include the events admitted by the first
+	if err := s.newQuotaTracker().admit(ctx, testEventAt("users/alice", "classify", time.Second, hour)); err != nil {
+		t.Errorf("admit() of a new tracker error = %v, want nil", err)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	s := newTestServiceWithStore(t, store, Config{Quotas: Quotas{
+		{ID: "hourly", Subject: "users/*", Window: QuotaHour, MaxExecutionDuration: time.Hour},
+		{ID: "monthly", Subject: "users/*", Window: QuotaMonth, MaxEvents: 2},
+		{ID: "detections", Action: "detect", Window: QuotaDay, MaxEvents: 1},
+	}})
+
+	// Usage of past windows does not count
+	past := testEventAt("users/alice", "classify", time.Second, time.Now().AddDate(0, -1, 0))
+	if err := store.AddRollups(ctx, eventRollups(past, 1)); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+
+	check := func() *usagev1.CheckQuotaResponse {
+		t.Helper()
+		resp, err := s.CheckQuota(ctx, &usagev1.CheckQuotaRequest{Subject: "users/alice", Source: "animal-classifier", Action: "classify"})
+		if err != nil {
+			t.Fatalf("CheckQuota() error = %v", err)
+		}
+		return resp
+	}
+
+	before := time.Now()
+	resp := check()
+	after := time.Now()
+	if !resp.GetAllowed() || len(resp.GetQuotas()) != 2 {
+		t.Fatalf("CheckQuota() = %v, want the hourly and monthly quotas allowed", resp)
+	}
+	for i, window := range []QuotaWindow{QuotaHour, QuotaMonth} {
+		usage := resp.GetQuotas()[i]
+		start, end := usage.GetWindow().GetStartTime().AsTime(), usage.GetWindow().GetEndTime().AsTime()
+		// The window contains the time of the check
+		wantStart, wantEnd := window.bounds(before)
+		if afterStart, afterEnd := window.bounds(after); start.Equal(afterStart) {
+			wantStart, wantEnd = afterStart, afterEnd
+		}
+		if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+			t.Errorf("CheckQuota() %s window = [%v, %v), want [%v, %v)", usage.GetQuotaId(), start, end, wantStart, wantEnd)
+		}
+		if usage.GetEventCount() != 0 || usage.GetExhausted() {
+			t.Errorf("CheckQuota() %s = %v, want no usage", usage.GetQuotaId(), usage)
+		}
+	}
+	if got := resp.GetQuotas()[0]; got.GetExecutionDurationLimit().AsDuration() != time.Hour || got.GetEventLimit() != 0 {
+		t.Errorf("CheckQuota() hourly limits = %v, %d, want 1h and no event limit", got.GetExecutionDurationLimit().AsDuration(), got.GetEventLimit())
+	}
+
+	createTestEvent(t, s, testEvent("users/alice", "classify", time.Second))
+	createTestEvent(t, s, testEvent("users/alice", "detect", 2*time.Second))
+	resp = check()
+	monthly := resp.GetQuotas()[1]
+	if resp.GetAllowed() || monthly.GetEventCount() != 2 || monthly.GetTotalExecutionDuration().AsDuration() != 3*time.Second || !monthly.GetExhausted() {
+		t.Errorf("CheckQuota() = %v, want the monthly quota exhausted by 2 events of 3s", resp)
+	}
+
+	// Quotas of other subjects, sources and actions are not reported
+	resp, err := s.CheckQuota(ctx, &usagev1.CheckQuotaRequest{Subject: "service-accounts/etl", Action: "classify"})
+	if err != nil {
+		t.Fatalf("CheckQuota() error = %v", err)
+	}
+	if !resp.GetAllowed() || len(resp.GetQuotas()) != 0 {
+		t.Errorf("CheckQuota() of a subject without quotas = %v, want allowed and no quotas", resp)
+	}
+}
+
+func TestSubjectLocks(t *testing.T) {
+	locks := newSubjectLocks()
+	var (
+		wg      sync.WaitGroup
+		holders [3]atomic.Int32
+	)
+	subjects := []string{"users/alice", "users/bob", "users/carol"}
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Batches lock overlapping subjects in any order, and with
+			// duplicates
+			held := []int{i % 3, (i + 1) % 3}
+			if i%2 == 0 {
+				held = []int{(i + 1) % 3, i % 3, (i + 1) % 3}
+			}
+			lockSubjects := make([]string, len(held))
+			for j, h := range held {
+				lockSubjects[j] = subjects[h]
+			}
+			unlock := locks.lock(lockSubjects...)
+			defer unlock()
+
+			slices.Sort(held)
+			held = slices.Compact(held)
+			for _, h := range held {
+				if n := holders[h].Add(1); n != 1 {
+					t.Errorf("%d holders of the lock of %s, want 1", n, subjects[h])
+				}
+			}
+			time.Sleep(time.Microsecond)
+			for _, h := range held {
+				holders[h].Add(-1)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("lock() deadlocked")
+	}
+
+	if len(locks.locks) != 0 {
+		t.Errorf("locks of %d subjects are left after unlocking, want none", len(locks.locks))
+	}
+}
+
+// slowRollupStore returns rollups a while after reading them, so that
+// concurrent requests read the usage of quotas before any of them updates
+// it.
+type slowRollupStore struct {
+	Store
+}
+
+func (s slowRollupStore) ListRollups(ctx context.Context, query RollupQuery) ([]*Rollup, error) {
+	rollups, err := s.Store.ListRollups(ctx, query)
+	time.Sleep(time.Millisecond)
+	return rollups, err
+}
+
+func TestEnforceQuotasConcurrently(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServiceWithStore(t, slowRollupStore{NewMemoryStore()}, Config{
+		Quotas:        Quotas{{ID: "daily", Subject: "users/*", Window: QuotaDay, MaxEvents: 5}},
+		EnforceQuotas: true,
+	})
+
+	// Concurrent single and batch requests of a subject admit as many
+	// events as the quota allows, and no more
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = s.CreateEvent(ctx, &usagev1.CreateEventRequest{Event: testEvent("users/alice", "classify", time.Second)})
+			} else {
+				_, err = s.BatchCreateEvents(ctx, &usagev1.BatchCreateEventsRequest{Requests: []*usagev1.CreateEventRequest{
+					{Event: testEvent("users/bob", "classify", time.Second)},
+					{Event: testEvent("users/alice", "classify", time.Second)},
+				}})
+			}
+			if code, _, _ := errorReason(err); err != nil && code != codes.ResourceExhausted {
+				t.Errorf("create error = %v, want nil or %v", err, codes.ResourceExhausted)
+			}
+		}()
+	}
+	wg.Wait()
+
+	resp, err := s.ListEvents(ctx, &usagev1.ListEventsRequest{Filter: `subject = "users/alice"`})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	quota, err := s.CheckQuota(ctx, &usagev1.CheckQuotaRequest{Subject: "users/alice"})
+	if err != nil {
+		t.Fatalf("CheckQuota() error = %v", err)
+	}
+	if created, counted := len(resp.GetEvents()), quota.GetQuotas()[0].GetEventCount(); created != 5 || counted != 5 {
+		t.Errorf("created %d events of users/alice, %d counted by CheckQuota, want 5", created, counted)
+	}
+}
@@ -0,0 +1,303 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// Granularity is the length of the time buckets of rollups.
+type Granularity string
+
+// Supported granularities.
+const (
+	// RollupHour rolls up events by hours of UTC.
+	RollupHour Granularity = "hour"
+	// RollupDay rolls up events by days of UTC.
+	RollupDay Granularity = "day"
+)
+
+// Duration returns the length of the time buckets of g.
+func (g Granularity) Duration() time.Duration {
+	if g == RollupDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// RollupKey identifies a rollup: the live events of a subject, source and
+// action created in a time bucket.
+type RollupKey struct {
+	Granularity Granularity
+	// Start is the start of the time bucket, in UTC.
+	Start   time.Time
+	Subject string
+	Source  string
+	Action  string
+}
+
+// Rollup holds the totals of the events of its key, which AggregateUsage
+// reads instead of the events themselves. Rollups are additive: the rollup
+// of a set of events is the sum of those of its parts, and rollups with
+// negative counts subtract events.
+type Rollup struct {
+	RollupKey `json:"-"`
+
+	EventCount    int64            `json:"event_count"`
+	TotalDuration time.Duration    `json:"total_duration"`
+	Costs         map[string]int64 `json:"costs,omitempty"` // nanos per currency code
+	Durations     DurationSketch   `json:"durations,omitempty"`
+}
+
+// Add adds the totals of delta to r.
+func (r *Rollup) Add(delta *Rollup) {
+	r.EventCount += delta.EventCount
+	r.TotalDuration += delta.TotalDuration
+	for currency, nanos := range delta.Costs {
+		if r.Costs == nil {
+			r.Costs = make(map[string]int64)
+		}
+		if r.Costs[currency] += nanos; r.Costs[currency] == 0 {
+			delete(r.Costs, currency)
+		}
+	}
+	for i, n := range delta.Durations {
+		if r.Durations == nil {
+			r.Durations = make(DurationSketch)
+		}
+		if r.Durations[i] += n; r.Durations[i] == 0 {
+			delete(r.Durations, i)
+		}
+	}
+}
+
+// clone returns a deep copy of r.
+func (r *Rollup) clone() *Rollup {
+	c := &Rollup{RollupKey: r.RollupKey}
+	c.Add(r)
+	return c
+}
+
+// Empty reports whether r has no events. Stores remove empty rollups.
+func (r *Rollup) Empty() bool {
+	return r.EventCount == 0 && r.TotalDuration == 0 && len(r.Costs) == 0 && len(r.Durations) == 0
+}
+
+// Equal reports whether r and other have the same totals.
+func (r *Rollup) Equal(other *Rollup) bool {
+	return r.EventCount == other.EventCount && r.TotalDuration == other.TotalDuration &&
+		maps.Equal(r.Costs, other.Costs) && maps.Equal(r.Durations, other.Durations)
+}
+
+// sketchAccuracy is the relative error of the durations estimated by a
+// DurationSketch.
+const sketchAccuracy = 0.01
+
+var (
+	sketchGamma    = (1 + sketchAccuracy) / (1 - sketchAccuracy)
+	sketchLogGamma = math.Log(sketchGamma)
+)
+
+// zeroBucket is the bucket of zero durations in a DurationSketch.
+const zeroBucket = -1
+
+// DurationSketch counts durations in logarithmic buckets, as in DDSketch,
+// so that quantiles can be estimated with a relative error of at most 1%
+// from a size that does not grow with the number of durations. Sketches can
+// be added to and subtracted from each other, unlike exact quantiles.
+type DurationSketch map[int32]int64
+
+// Add counts d n times; a negative n removes it.
+func (s DurationSketch) Add(d time.Duration, n int64) {
+	i := int32(zeroBucket)
+	if d > 0 {
+		i = int32(math.Ceil(math.Log(float64(d)) / sketchLogGamma))
+	}
+	if s[i] += n; s[i] == 0 {
+		delete(s, i)
+	}
+}
+
+// Quantile estimates the p-th percentile of the durations by the
+// nearest-rank method.
+func (s DurationSketch) Quantile(p int) time.Duration {
+	var total int64
+	for _, n := range s {
+		total += n
+	}
+	rank := max((int64(p)*total+99)/100, 1)
+	buckets := slices.Sorted(maps.Keys(s))
+	for _, i := range buckets {
+		if rank -= s[i]; rank <= 0 {
+			return sketchValue(i)
+		}
+	}
+	if len(buckets) == 0 {
+		return 0
+	}
+	return sketchValue(buckets[len(buckets)-1])
+}
+
+// sketchValue returns the estimate of the durations in bucket i, which is
+// within sketchAccuracy of all of them.
+func sketchValue(i int32) time.Duration {
+	if i == zeroBucket {
+		return 0
+	}
+	return time.Duration(math.Round(2 * math.Pow(sketchGamma, float64(i)) / (sketchGamma + 1)))
+}
+
+// eventRollups returns the rollups of event, or with a sign of -1 the
+// deltas removing it from them.
+func eventRollups(event *usagev1.Event, sign int64) []*Rollup {
+	t := event.GetCreateTime().AsTime().UTC()
+	d := event.GetExecutionDuration().AsDuration()
+	rollups := make([]*Rollup, 0, 2)
+	for _, g := range []Granularity{RollupHour, RollupDay} {
+		r := &Rollup{
+			RollupKey: RollupKey{
+				Granularity: g,
+				Start:       t.Truncate(g.Duration()),
+				Subject:     event.GetSubject(),
+				Source:      event.GetSource(),
+				Action:      event.GetAction(),
+			},
+			EventCount:    sign,
+			TotalDuration: time.Duration(sign) * d,
+			Durations:     DurationSketch{},
+		}
+		r.Durations.Add(d, sign)
+		if cost := event.GetCost(); cost != nil {
+			r.Costs = map[string]int64{cost.GetCurrencyCode(): sign * moneyNanos(cost)}
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups
+}
+
+// sumRollups adds up rollups by key, dropping the empty sums.
+func sumRollups(rollups []*Rollup) map[RollupKey]*Rollup {
+	sums := make(map[RollupKey]*Rollup)
+	for _, r := range rollups {
+		sum, ok := sums[r.RollupKey]
+		if !ok {
+			sum = &Rollup{RollupKey: r.RollupKey}
+			sums[r.RollupKey] = sum
+		}
+		sum.Add(r)
+	}
+	maps.DeleteFunc(sums, func(_ RollupKey, r *Rollup) bool { return r.Empty() })
+	return sums
+}
+
+// updateRollups adds the rollups of created events, and the deltas of
+// changed ones, to the stored rollups. Rollups are updated after the events
+// are written, so a failure leaves them behind the events: it is logged
+// rather than failing a request whose events were written, and repaired by
+// rebuilding the rollups, see RebuildRollups.
+func (s *Service) updateRollups(ctx context.Context, deltas []*Rollup) {
+	sums := sumRollups(deltas)
+	if len(sums) == 0 {
+		return
+	}
+	// The events are written, so the rollups must follow even if the client
+	// goes away
+	ctx = context.WithoutCancel(ctx)
+	if err := s.store.AddRollups(ctx, slices.Collect(maps.Values(sums))); err != nil {
+		log.Printf("Failed to update rollups, rebuild them to repair: %v", err)
+	}
+}
+
+// computeRollups computes the rollups of the live events in store.
+func computeRollups(ctx context.Context, store Store) (map[RollupKey]*Rollup, error) {
+	var rollups []*Rollup
+	query := ListQuery{Limit: aggregateBatchSize}
+	for {
+		events, err := store.ListEvents(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			rollups = append(rollups, eventRollups(event, 1)...)
+		}
+		if len(events) < aggregateBatchSize {
+			break
+		}
+		cursor := CursorOf(events[len(events)-1])
+		query.After = &cursor
+		// Keep the memory bounded by the number of rollups
+		rollups = slices.Collect(maps.Values(sumRollups(rollups)))
+	}
+	return sumRollups(rollups), nil
+}
+
+// RebuildRollups recomputes the rollups in store from its events, and
+// returns how many there are. Events written meanwhile may be missed or
+// counted twice, so no server should write to store while it runs.
+func RebuildRollups(ctx context.Context, store Store) (int, error) {
+	rollups, err := computeRollups(ctx, store)
+	if err != nil {
+		return 0, err
+	}
+	if err := store.ReplaceRollups(ctx, slices.Collect(maps.Values(rollups))); err != nil {
+		return 0, err
+	}
+	return len(rollups), nil
+}
+
+// RollupMismatch is a rollup whose stored totals differ from those computed
+// from the events.
+type RollupMismatch struct {
+	RollupKey
+	// Stored is the stored rollup, or nil if it is missing.
+	Stored *Rollup
+	// Computed is the rollup computed from the events, or nil if there are
+	// no such events.
+	Computed *Rollup
+}
+
+// CheckRollups compares the rollups in store with those computed from its
+// events, and returns the mismatches ordered by key. Like RebuildRollups,
+// it reports false mismatches if events are written meanwhile.
+func CheckRollups(ctx context.Context, store Store) ([]RollupMismatch, error) {
+	computed, err := computeRollups(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.ListRollups(ctx, RollupQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []RollupMismatch
+	for _, r := range stored {
+		c := computed[r.RollupKey]
+		delete(computed, r.RollupKey)
+		if c == nil || !c.Equal(r) {
+			mismatches = append(mismatches, RollupMismatch{RollupKey: r.RollupKey, Stored: r, Computed: c})
+		}
+	}
+	for key, c := range computed {
+		mismatches = append(mismatches, RollupMismatch{RollupKey: key, Computed: c})
+	}
+	slices.SortFunc(mismatches, func(a, b RollupMismatch) int { return compareRollupKeys(a.RollupKey, b.RollupKey) })
+	return mismatches, nil
+}
+
+// compareRollupKeys orders rollup keys by granularity, start, subject,
+// source and action.
+func compareRollupKeys(a, b RollupKey) int {
+	if c := strings.Compare(string(a.Granularity), string(b.Granularity)); c != 0 {
+		return c
+	}
+	if c := a.Start.Compare(b.Start); c != 0 {
+		return c
+	}
+	return slices.Compare([]string{a.Subject, a.Source, a.Action}, []string{b.Subject, b.Source, b.Action})
+}
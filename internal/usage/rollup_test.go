@@ -0,0 +1,230 @@
+package usage
+
+import (
+	"context"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+func TestDurationSketchQuantile(t *testing.T) {
+	var uniform, spread, skewed []time.Duration
+	for i := range 1000 {
+		uniform = append(uniform, time.Duration(i+1)*time.Millisecond)
+		skewed = append(skewed, 10*time.Millisecond)
+	}
+	for i := range 10 {
+		skewed[i*100] = time.Hour
+	}
+	for d := 1.0; d < float64(24*time.Hour); d *= 1.37 {
+		spread = append(spread, time.Duration(d))
+	}
+
+	for name, durations := range map[string][]time.Duration{
+		"single":  {1500 * time.Millisecond},
+		"zeros":   {0, 0, 0, time.Second},
+		"uniform": uniform,
+		"spread":  spread,
+		"skewed":  skewed,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sketch := make(DurationSketch)
+			for _, d := range durations {
+				sketch.Add(d, 1)
+			}
+			sorted := slices.Sorted(slices.Values(durations))
+			for _, p := range []int{1, 25, 50, 90, 95, 99, 100} {
+				got, want := sketch.Quantile(p), percentile(sorted, p)
+				if diff := math.Abs(float64(got - want)); diff > sketchAccuracy*float64(want)+1 {
+					t.Errorf("Quantile(%d) = %v, want %v within %v%%", p, got, want, 100*sketchAccuracy)
+				}
+			}
+		})
+	}
+
+	if got := make(DurationSketch).Quantile(50); got != 0 {
+		t.Errorf("Quantile() of an empty sketch = %v, want 0", got)
+	}
+}
+
+func TestDurationSketchSubtract(t *testing.T) {
+	all, kept := make(DurationSketch), make(DurationSketch)
+	for i := range 100 {
+		d := time.Duration(i*i) * time.Millisecond
+		all.Add(d, 1)
+		if i%3 != 0 {
+			kept.Add(d, 1)
+		}
+	}
+	for i := 0; i < 100; i += 3 {
+		all.Add(time.Duration(i*i)*time.Millisecond, -1)
+	}
+	if !maps.Equal(all, kept) {
+		t.Errorf("sketch with durations removed = %v, want %v", all, kept)
+	}
+	for i := range 100 {
+		if i%3 != 0 {
+			all.Add(time.Duration(i*i)*time.Millisecond, -1)
+		}
+	}
+	if len(all) != 0 {
+		t.Errorf("sketch with all durations removed = %v, want empty", all)
+	}
+}
+
+func TestRollupAdd(t *testing.T) {
+	event := testEventAt("users/alice", "classify", 1500*time.Millisecond, time.Date(2025, 3, 31, 18, 29, 59, 0, time.UTC))
+	event.Cost = &money.Money{CurrencyCode: "USD", Nanos: 1_250_000}
+
+	rollups := eventRollups(event, 1)
+	if len(rollups) != 2 {
+		t.Fatalf("eventRollups() = %d rollups, want 2", len(rollups))
+	}
+	for _, r := range rollups {
+		want := time.Date(2025, 3, 31, 18, 0, 0, 0, time.UTC)
+		if r.Granularity == RollupDay {
+			want = time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+		}
+		if !r.Start.Equal(want) {
+			t.Errorf("eventRollups() %s start = %v, want %v", r.Granularity, r.Start, want)
+		}
+		if r.EventCount != 1 || r.TotalDuration != 1500*time.Millisecond || r.Costs["USD"] != 1_250_000 {
+			t.Errorf("eventRollups() %s = %+v, want the totals of the event", r.Granularity, r)
+		}
+	}
+
+	// The rollups of a second event and the deltas removing the first one
+	// leave the totals of the second
+	second := testEventAt("users/alice", "classify", 3*time.Second, time.Date(2025, 3, 31, 18, 59, 59, 0, time.UTC))
+	sum := rollups[0].clone()
+	sum.Add(eventRollups(second, 1)[0])
+	sum.Add(eventRollups(event, -1)[0])
+	if want := eventRollups(second, 1)[0]; !sum.Equal(want) {
+		t.Errorf("Add() = %+v, want %+v", sum, want)
+	}
+	sum.Add(eventRollups(second, -1)[0])
+	if !sum.Empty() {
+		t.Errorf("Add() of all deltas = %+v, want an empty rollup", sum)
+	}
+}
+
+func TestRollupsFollowEvents(t *testing.T) {
+	ctx := context.Background()
+	cards, err := ParseRateCards([]byte(`{"rate_cards": [{
+		"source": "animal-classifier", "action": "classify", "version": "1",
+		"currency_code": "USD", "per_call": "0.01", "per_second": "0.001"
+	}]}`))
+	if err != nil {
+		t.Fatalf("ParseRateCards() error = %v", err)
+	}
+	store := NewMemoryStore()
+	s := newTestServiceWithStore(t, store, Config{RateCards: cards})
+
+	checkRollups := func(step string) {
+		t.Helper()
+		mismatches, err := CheckRollups(ctx, store)
+		if err != nil {
+			t.Fatalf("CheckRollups() error = %v", err)
+		}
+		for _, m := range mismatches {
+			t.Errorf("after %s, rollup %+v = %+v, want %+v", step, m.RollupKey, m.Stored, m.Computed)
+		}
+	}
+
+	alice := createTestEvent(t, s, testEvent("users/alice", "classify", time.Second))
+	createTestEvent(t, s, testEvent("users/bob", "classify", 2*time.Second))
+	_, err = s.BatchCreateEvents(ctx, &usagev1.BatchCreateEventsRequest{Requests: []*usagev1.CreateEventRequest{
+		{Event: testEvent("users/alice", "detect", 3*time.Second)},
+		{Event: testEvent("users/alice", "classify", 500*time.Millisecond)},
+	}})
+	if err != nil {
+		t.Fatalf("BatchCreateEvents() error = %v", err)
+	}
+	checkRollups("creating events")
+
+	// Updates move the event between rollups and reprice it
+	for _, update := range []struct {
+		path  string
+		event *usagev1.Event
+	}{
+		{"execution_duration", &usagev1.Event{ExecutionDuration: durationpb.New(4 * time.Second)}},
+		{"subject", &usagev1.Event{Subject: "users/carol"}},
+		{"action", &usagev1.Event{Action: "detect"}},
+		{"labels", &usagev1.Event{Labels: map[string]string{"model": "v2"}}},
+	} {
+		update.event.Name = alice.GetName()
+		_, err := s.UpdateEvent(ctx, &usagev1.UpdateEventRequest{
+			Event:      update.event,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{update.path}},
+		})
+		if err != nil {
+			t.Fatalf("UpdateEvent(%s) error = %v", update.path, err)
+		}
+		checkRollups("updating " + update.path)
+	}
+
+	// Deleting or undeleting an event twice changes the rollups once
+	for range 2 {
+		_, err := s.DeleteEvent(ctx, &usagev1.DeleteEventRequest{Name: alice.GetName()})
+		if code, reason, _ := errorReason(err); err != nil && (code != codes.FailedPrecondition || reason != ReasonEventDeleted) {
+			t.Fatalf("DeleteEvent() error = %v", err)
+		}
+		checkRollups("deleting an event")
+	}
+	rollups, err := store.ListRollups(ctx, RollupQuery{Subject: "users/carol"})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(rollups) != 0 {
+		t.Errorf("ListRollups() of the deleted event = %d rollups, want none", len(rollups))
+	}
+
+	// A deleted event cannot be updated, so its rollups cannot change
+	_, err = s.UpdateEvent(ctx, &usagev1.UpdateEventRequest{
+		Event:      &usagev1.Event{Name: alice.GetName(), Subject: "users/dave"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"subject"}},
+	})
+	if code, reason, _ := errorReason(err); code != codes.FailedPrecondition || reason != ReasonEventDeleted {
+		t.Errorf("UpdateEvent() of a deleted event error = %v, want %v with reason %s", err, codes.FailedPrecondition, ReasonEventDeleted)
+	}
+	checkRollups("updating a deleted event")
+
+	for range 2 {
+		_, err := s.UndeleteEvent(ctx, &usagev1.UndeleteEventRequest{Name: alice.GetName()})
+		if code, reason, _ := errorReason(err); err != nil && (code != codes.FailedPrecondition || reason != ReasonEventNotDeleted) {
+			t.Fatalf("UndeleteEvent() error = %v", err)
+		}
+		checkRollups("undeleting an event")
+	}
+	rollups, err = store.ListRollups(ctx, RollupQuery{Subject: "users/carol", Granularity: RollupHour})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(rollups) != 1 || rollups[0].EventCount != 1 || rollups[0].TotalDuration != 4*time.Second {
+		t.Errorf("ListRollups() of the undeleted event = %+v, want its totals", rollups)
+	}
+
+	// Rollups of deleted events are removed rather than left empty
+	events, err := store.ListEvents(ctx, ListQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	for _, event := range events {
+		if _, err := s.DeleteEvent(ctx, &usagev1.DeleteEventRequest{Name: event.GetName()}); err != nil {
+			t.Fatalf("DeleteEvent() error = %v", err)
+		}
+	}
+	checkRollups("deleting all events")
+	if rollups, err := store.ListRollups(ctx, RollupQuery{}); err != nil || len(rollups) != 0 {
+		t.Errorf("ListRollups() with all events deleted = %d rollups, %v, want none", len(rollups), err)
+	}
+}
@@ -2,85 +2,365 @@ package usage
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
-	"sort"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
 )
 
 const (
 	defaultPageSize = 20
 	maxPageSize     = 100
+
+	// DefaultRequestIDWindow is how long request IDs are remembered when
+	// Config.RequestIDWindow is not set.
+	DefaultRequestIDWindow = 10 * time.Minute
+
+	// DefaultPurgeGracePeriod is how long deleted events can be restored when
+	// Config.PurgeGracePeriod is not set.
+	DefaultPurgeGracePeriod = 30 * 24 * time.Hour
 )
 
-// storedEvent holds the event data in memory.
-type storedEvent struct {
-	event      *usagev1.Event
-	createTime time.Time
+// Config configures the usage services.
+type Config struct {
+	// PageTokenKey signs ListEvents page tokens. If empty, a random key is
+	// generated, so tokens do not survive a restart.
+	PageTokenKey []byte
+	// RequestIDWindow is how long CreateEvent remembers a request_id, within
+	// which a retried request returns the originally created event. Request
+	// IDs are remembered in memory, not in the store: a retry is only
+	// deduplicated by the same service, and not after it restarts.
+	RequestIDWindow time.Duration
+	// WatchBufferSize is the number of events buffered for each WatchEvents
+	// stream. A client that falls further behind is disconnected.
+	WatchBufferSize int
+	// PurgeGracePeriod is how long a deleted event can be restored before it
+	// is permanently purged.
+	PurgeGracePeriod time.Duration
+	// DurationLimits limits the execution durations of events. If nil,
+	// DefaultDurationBounds apply to all events.
+	DurationLimits *DurationLimits
+	// PermissiveSources disables the source registry: events are accepted
+	// even if their source or action is not registered.
+	PermissiveSources bool
+	// RateCards price events. If nil, events are not priced.
+	RateCards RateCards
+	// Quotas limit the usage of subjects, as reported by CheckQuota.
+	Quotas Quotas
+	// EnforceQuotas rejects the events of exhausted quotas in CreateEvent
+	// and BatchCreateEvents. The events of a subject are admitted one at a
+	// time, which only holds within a service: services sharing a store may
+	// together exceed a quota by a few events.
+	EnforceQuotas bool
 }
 
 // Service implements the EventService gRPC handler.
 type Service struct {
 	usagev1.UnimplementedEventServiceServer
-	mu     sync.RWMutex
-	events map[string]*storedEvent // keyed by event ID
+	store      Store
+	pageTokens pageTokenCodec
+	requests   *idempotencyCache
+	watchers   *watchHub
+
+	watchBufferSize  int
+	purgeGracePeriod time.Duration
+	durationLimits   DurationLimits
+	checkSources     bool
+	rateCards        RateCards
+	quotas           Quotas
+	enforceQuotas    bool
+	quotaLocks       *subjectLocks
 }
 
-// NewService creates a new EventService.
-func NewService() *Service {
+// NewService creates a new EventService backed by the given store.
+func NewService(store Store, cfg Config) (*Service, error) {
+	key, err := pageTokenKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	window := cfg.RequestIDWindow
+	if window <= 0 {
+		window = DefaultRequestIDWindow
+	}
+
+	watchBufferSize := cfg.WatchBufferSize
+	if watchBufferSize <= 0 {
+		watchBufferSize = DefaultWatchBufferSize
+	}
+
+	purgeGracePeriod := cfg.PurgeGracePeriod
+	if purgeGracePeriod <= 0 {
+		purgeGracePeriod = DefaultPurgeGracePeriod
+	}
+
+	durationLimits := DurationLimits{Default: DefaultDurationBounds}
+	if cfg.DurationLimits != nil {
+		durationLimits = *cfg.DurationLimits
+	}
+
 	return &Service{
-		events: make(map[string]*storedEvent),
+		store:            store,
+		pageTokens:       pageTokenCodec{key: key},
+		requests:         newIdempotencyCache(window),
+		watchers:         newWatchHub(),
+		watchBufferSize:  watchBufferSize,
+		purgeGracePeriod: purgeGracePeriod,
+		durationLimits:   durationLimits,
+		checkSources:     !cfg.PermissiveSources,
+		rateCards:        cfg.RateCards,
+		quotas:           cfg.Quotas,
+		enforceQuotas:    cfg.EnforceQuotas,
+		quotaLocks:       newSubjectLocks(),
+	}, nil
+}
+
+// pageTokenKey returns the key that signs page tokens: the configured one,
+// or a random key if there is none.
+func pageTokenKey(cfg Config) ([]byte, error) {
+	if len(cfg.PageTokenKey) > 0 {
+		return cfg.PageTokenKey, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate page token key: %w", err)
 	}
+	return key, nil
 }
 
 // CreateEvent creates a new usage event.
 func (s *Service) CreateEvent(ctx context.Context, req *usagev1.CreateEventRequest) (*usagev1.CreateEventResponse, error) {
-	if req.GetEvent() == nil {
-		return nil, status.Error(codes.InvalidArgument, "event is required")
+	violations := s.ValidateRequest(req)
+	registryViolations, err := s.sourceViolations(ctx, "event", req.GetEvent(), nil)
+	if err != nil {
+		return nil, err
 	}
-	if req.GetEvent().GetSubject() == "" {
-		return nil, status.Error(codes.InvalidArgument, "subject is required")
+	if violations = append(violations, registryViolations...); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
 	}
-	if req.GetEvent().GetSource() == "" {
-		return nil, status.Error(codes.InvalidArgument, "source is required")
+
+	if req.GetRequestId() == "" {
+		return s.createEvent(ctx, req)
 	}
-	if req.GetEvent().GetAction() == "" {
-		return nil, status.Error(codes.InvalidArgument, "action is required")
+	resp, err := s.requests.do(ctx, req.GetRequestId(), createEventFingerprint(req), func() (proto.Message, error) {
+		return s.createEvent(ctx, req)
+	})
+	if errors.Is(err, errRequestIDReused) {
+		return nil, requestIDReused(req.GetRequestId())
 	}
-	if req.GetEvent().GetExecutionDuration() == nil {
-		return nil, status.Error(codes.InvalidArgument, "execution_duration is required")
+	if err != nil {
+		return nil, err
 	}
+	return resp.(*usagev1.CreateEventResponse), nil
+}
 
-	id := uuid.New().String()
-	name := fmt.Sprintf("events/%s", id)
-	now := time.Now()
+// ValidateRequest returns the violations of a request other than those of
+// its field behaviors, which are enforced separately. It implements
+// fieldbehavior.Validator, so that all violations of a request are reported
+// at once.
+func (s *Service) ValidateRequest(req proto.Message) []apierror.FieldViolation {
+	switch req := req.(type) {
+	case *usagev1.CreateEventRequest:
+		violations := s.validateCreateEventRequest(req)
+		if req.GetRequestId() != "" {
+			if err := validateRequestID(req.GetRequestId()); err != nil {
+				violations = append(violations, invalidField("request_id", ReasonInvalidRequestID, err))
+			}
+		}
+		return violations
+	case *usagev1.BatchCreateEventsRequest:
+		return validateBatchCreateEventsRequest(req)
+	case *usagev1.UpdateEventRequest:
+		return validateUpdateEventRequest(req)
+	case *usagev1.CheckQuotaRequest:
+		return validateCheckQuotaRequest(req)
+	default:
+		return nil
+	}
+}
+
+// validateCreateEventRequest returns the violations of the fields of a
+// CreateEvent request other than its request_id. Field behaviors are
+// enforced separately, see package fieldbehavior.
+func (s *Service) validateCreateEventRequest(req *usagev1.CreateEventRequest) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+	if req.GetEventId() != "" {
+		if err := ValidateEventID(req.GetEventId()); err != nil {
+			violations = append(violations, invalidField("event_id", ReasonInvalidEventID, err))
+		}
+	}
+	if req.GetEvent().GetSubject() != "" {
+		if err := ValidateSubject(req.GetEvent().GetSubject()); err != nil {
+			violations = append(violations, invalidField("event.subject", ReasonInvalidSubject, err))
+		}
+	}
+	violations = append(violations, validateLabels("event.labels", req.GetEvent().GetLabels())...)
+	violations = append(violations, s.durationLimits.validate("event.execution_duration", req.GetEvent())...)
+	return violations
+}
+
+// newEvent returns the event to store for a validated CreateEvent request,
+// priced with the rate card in effect at now.
+func (s *Service) newEvent(ctx context.Context, req *usagev1.CreateEventRequest, now time.Time) (*usagev1.Event, error) {
+	id := req.GetEventId()
+	if id == "" {
+		id = uuid.New().String()
+	}
 
 	event := &usagev1.Event{
-		Name:              name,
+		Name:              EventName(id),
 		Subject:           req.GetEvent().GetSubject(),
 		Source:            req.GetEvent().GetSource(),
 		Action:            req.GetEvent().GetAction(),
 		ExecutionDuration: req.GetEvent().GetExecutionDuration(),
+		Labels:            req.GetEvent().GetLabels(),
 		CreateTime:        timestamppb.New(now),
 	}
+	card, err := s.rateCard(ctx, event.GetSource(), event.GetAction(), now)
+	if err != nil {
+		return nil, err
+	}
+	if err := setCost(event, card); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to price event: %v", err)
+	}
+	event.Etag = resourceEtag(event)
+	return event, nil
+}
+
+// createEvent stores the event of a validated CreateEvent request.
+func (s *Service) createEvent(ctx context.Context, req *usagev1.CreateEventRequest) (*usagev1.CreateEventResponse, error) {
+	reservation := s.watchers.reserve(1)
+	var created []*usagev1.Event
+	defer func() { reservation.publish(created...) }()
+
+	event, err := s.newEvent(ctx, req, reservation.createTime(0))
+	if err != nil {
+		return nil, err
+	}
+	if s.enforceQuotas {
+		defer s.quotaLocks.lock(event.GetSubject())()
+		if err := s.newQuotaTracker().admit(ctx, event); err != nil {
+			return nil, err
+		}
+	}
 
-	s.mu.Lock()
-	s.events[id] = &storedEvent{
-		event:      event,
-		createTime: now,
+	err = s.store.CreateEvent(ctx, event)
+	if errors.Is(err, ErrAlreadyExists) {
+		return nil, eventAlreadyExists(event.GetName())
 	}
-	s.mu.Unlock()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store event: %v", err)
+	}
+	created = append(created, event)
+	s.updateRollups(ctx, eventRollups(event, 1))
 
 	return &usagev1.CreateEventResponse{Event: event}, nil
 }
 
+// GetEvent returns a single usage event by its resource name.
+func (s *Service) GetEvent(ctx context.Context, req *usagev1.GetEventRequest) (*usagev1.GetEventResponse, error) {
+	if err := validateEventName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	event, err := s.store.GetEvent(ctx, req.GetName())
+	if errors.Is(err, ErrNotFound) {
+		return nil, eventNotFound(req.GetName())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get event: %v", err)
+	}
+
+	return &usagev1.GetEventResponse{Event: event}, nil
+}
+
+// DeleteEvent soft-deletes a usage event.
+func (s *Service) DeleteEvent(ctx context.Context, req *usagev1.DeleteEventRequest) (*usagev1.DeleteEventResponse, error) {
+	if err := validateEventName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event, err := s.store.UpdateEvent(ctx, req.GetName(), func(event *usagev1.Event) (*usagev1.Event, error) {
+		if err := checkEtag(event, req.GetEtag()); err != nil {
+			return nil, err
+		}
+		if event.GetDeleteTime() != nil {
+			return nil, apierror.New(codes.FailedPrecondition, ReasonEventDeleted,
+				fmt.Sprintf("event %q is already deleted", req.GetName()), map[string]string{"name": req.GetName()})
+		}
+		event.DeleteTime = timestamppb.New(now)
+		event.PurgeTime = timestamppb.New(now.Add(s.purgeGracePeriod))
+		event.Etag = resourceEtag(event)
+		return event, nil
+	})
+	if err != nil {
+		return nil, updateError(err, req.GetName())
+	}
+	s.updateRollups(ctx, eventRollups(event, -1))
+
+	return &usagev1.DeleteEventResponse{Event: event}, nil
+}
+
+// UndeleteEvent restores a soft-deleted usage event.
+func (s *Service) UndeleteEvent(ctx context.Context, req *usagev1.UndeleteEventRequest) (*usagev1.UndeleteEventResponse, error) {
+	if err := validateEventName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	event, err := s.store.UpdateEvent(ctx, req.GetName(), func(event *usagev1.Event) (*usagev1.Event, error) {
+		if err := checkEtag(event, req.GetEtag()); err != nil {
+			return nil, err
+		}
+		if event.GetDeleteTime() == nil {
+			return nil, apierror.New(codes.FailedPrecondition, ReasonEventNotDeleted,
+				fmt.Sprintf("event %q is not deleted", req.GetName()), map[string]string{"name": req.GetName()})
+		}
+		event.DeleteTime = nil
+		event.PurgeTime = nil
+		event.Etag = resourceEtag(event)
+		return event, nil
+	})
+	if err != nil {
+		return nil, updateError(err, req.GetName())
+	}
+	s.updateRollups(ctx, eventRollups(event, 1))
+
+	return &usagev1.UndeleteEventResponse{Event: event}, nil
+}
+
+// validateEventName checks the event name in the given field of a request.
+func validateEventName(field, name string) error {
+	if name == "" {
+		return apierror.BadRequest(required(field))
+	}
+	if _, err := ParseEventName(name); err != nil {
+		return invalidArgument(field, ReasonInvalidEventName, err)
+	}
+	return nil
+}
+
+// updateError converts an error of EventStore.UpdateEvent to a status error.
+// Status errors returned by the update function are passed through.
+func updateError(err error, name string) error {
+	if errors.Is(err, ErrNotFound) {
+		return eventNotFound(name)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Internal, "failed to update event: %v", err)
+}
+
 // ListEvents lists usage events with pagination.
 func (s *Service) ListEvents(ctx context.Context, req *usagev1.ListEventsRequest) (*usagev1.ListEventsResponse, error) {
 	pageSize := int(req.GetPageSize())
@@ -91,50 +371,52 @@ func (s *Service) ListEvents(ctx context.Context, req *usagev1.ListEventsRequest
 		pageSize = maxPageSize
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Collect all events
-	allEvents := make([]*storedEvent, 0, len(s.events))
-	for _, e := range s.events {
-		allEvents = append(allEvents, e)
+	var violations []apierror.FieldViolation
+	predicate, err := compileFilter(req.GetFilter())
+	if err != nil {
+		violations = append(violations, invalidField("filter", ReasonInvalidFilter, fmt.Errorf("invalid filter: %w", err)))
 	}
-
-	// Sort by create time descending (newest first)
-	sort.Slice(allEvents, func(i, j int) bool {
-		return allEvents[i].createTime.After(allEvents[j].createTime)
-	})
-
-	// Handle pagination
-	startIdx := 0
-	if req.GetPageToken() != "" {
-		for i, e := range allEvents {
-			if e.event.GetName() == req.GetPageToken() {
-				startIdx = i + 1
-				break
-			}
+	ordering, err := ParseOrderBy(req.GetOrderBy())
+	if err != nil {
+		violations = append(violations, invalidField("order_by", ReasonInvalidOrderBy, fmt.Errorf("invalid order_by: %w", err)))
+	}
+	var after *Cursor
+	if req.GetPageToken() != "" && ordering != nil {
+		cursor, err := s.pageTokens.decode(req.GetPageToken(), req.GetFilter(), req.GetShowDeleted(), ordering)
+		if err != nil {
+			violations = append(violations, invalidField("page_token", ReasonInvalidPageToken, err))
 		}
+		after = &cursor
+	}
+	if len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
 	}
 
-	// Get page of results
-	endIdx := startIdx + pageSize
-	if endIdx > len(allEvents) {
-		endIdx = len(allEvents)
+	query := ListQuery{
+		// Read one extra event to find out whether there is a next page
+		Limit:       pageSize + 1,
+		Filter:      predicate,
+		OrderBy:     ordering,
+		After:       after,
+		ShowDeleted: req.GetShowDeleted(),
 	}
 
-	pageEvents := allEvents[startIdx:endIdx]
-	result := make([]*usagev1.Event, len(pageEvents))
-	for i, stored := range pageEvents {
-		result[i] = stored.event
+	events, err := s.store.ListEvents(ctx, query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list events: %v", err)
 	}
 
 	var nextPageToken string
-	if endIdx < len(allEvents) {
-		nextPageToken = allEvents[endIdx-1].event.GetName()
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		nextPageToken, err = s.pageTokens.encode(req.GetFilter(), req.GetShowDeleted(), ordering, CursorOf(events[pageSize-1]))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
 	}
 
 	return &usagev1.ListEventsResponse{
-		Events:        result,
+		Events:        events,
 		NextPageToken: nextPageToken,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,224 @@
+package usage
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+func TestCreateEvent(t *testing.T) {
+	runServiceTest(t, Config{}, func(t *testing.T, s *Service) {
+		ctx := context.Background()
+		event := testEvent("users/alice", "classify", 1500*time.Millisecond)
+		event.Labels = map[string]string{"model": "v2"}
+		resp, err := s.CreateEvent(ctx, &usagev1.CreateEventRequest{Event: event})
+		if err != nil {
+			t.Fatalf("CreateEvent() error = %v", err)
+		}
+		created := resp.GetEvent()
+		if _, err := ParseEventName(created.GetName()); err != nil {
+			t.Errorf("CreateEvent() name = %q, want a valid event name: %v", created.GetName(), err)
+		}
+		if created.GetCreateTime() == nil || created.GetEtag() == "" || created.GetLabels()["model"] != "v2" {
+			t.Errorf("CreateEvent() = %v, want the event with its create_time and etag", created)
+		}
+
+		got, err := s.GetEvent(ctx, &usagev1.GetEventRequest{Name: created.GetName()})
+		if err != nil {
+			t.Fatalf("GetEvent() error = %v", err)
+		}
+		if !proto.Equal(got.GetEvent(), created) {
+			t.Errorf("GetEvent() = %v, want %v", got.GetEvent(), created)
+		}
+
+		// Events can be given IDs of their own, once
+		req := &usagev1.CreateEventRequest{EventId: "run-42", Event: testEvent("users/alice", "classify", time.Second)}
+		resp, err = s.CreateEvent(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateEvent() with an event_id error = %v", err)
+		}
+		if resp.GetEvent().GetName() != "events/run-42" {
+			t.Errorf("CreateEvent() with an event_id name = %q, want events/run-42", resp.GetEvent().GetName())
+		}
+		_, err = s.CreateEvent(ctx, req)
+		if code, reason, _ := errorReason(err); code != codes.AlreadyExists || reason != ReasonEventAlreadyExists {
+			t.Errorf("CreateEvent() with a used event_id error = %v, want %v with reason %s", err, codes.AlreadyExists, ReasonEventAlreadyExists)
+		}
+
+		_, err = s.CreateEvent(ctx, &usagev1.CreateEventRequest{Event: testEvent("alice", "classify", time.Second)})
+		if code, _, fields := errorReason(err); code != codes.InvalidArgument || !slices.Contains(fields, "event.subject") {
+			t.Errorf("CreateEvent() with an invalid subject error = %v, want %v of event.subject", err, codes.InvalidArgument)
+		}
+	})
+}
+
+func TestCreateEventRequestID(t *testing.T) {
+	runServiceTest(t, Config{}, func(t *testing.T, s *Service) {
+		ctx := context.Background()
+		req := &usagev1.CreateEventRequest{RequestId: "4f1a2b3c-0000-4000-8000-000000000001", Event: testEvent("users/alice", "classify", time.Second)}
+		first, err := s.CreateEvent(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateEvent() error = %v", err)
+		}
+		retry, err := s.CreateEvent(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateEvent() retry error = %v", err)
+		}
+		if !proto.Equal(retry.GetEvent(), first.GetEvent()) {
+			t.Errorf("CreateEvent() retry = %v, want the first event %v", retry.GetEvent(), first.GetEvent())
+		}
+		list, err := s.ListEvents(ctx, &usagev1.ListEventsRequest{})
+		if err != nil {
+			t.Fatalf("ListEvents() error = %v", err)
+		}
+		if len(list.GetEvents()) != 1 {
+			t.Errorf("ListEvents() after a retry = %d events, want 1", len(list.GetEvents()))
+		}
+
+		other := proto.Clone(req).(*usagev1.CreateEventRequest)
+		other.Event.Action = "detect"
+		_, err = s.CreateEvent(ctx, other)
+		if code, reason, _ := errorReason(err); code != codes.AlreadyExists || reason != ReasonRequestIDReused {
+			t.Errorf("CreateEvent() reusing a request_id error = %v, want %v with reason %s", err, codes.AlreadyExists, ReasonRequestIDReused)
+		}
+	})
+}
+
+func TestGetEventNotFound(t *testing.T) {
+	runServiceTest(t, Config{}, func(t *testing.T, s *Service) {
+		_, err := s.GetEvent(context.Background(), &usagev1.GetEventRequest{Name: "events/missing"})
+		if code, reason, _ := errorReason(err); code != codes.NotFound || reason != ReasonEventNotFound {
+			t.Errorf("GetEvent() error = %v, want %v with reason %s", err, codes.NotFound, ReasonEventNotFound)
+		}
+	})
+}
+
+func TestListEvents(t *testing.T) {
+	runServiceTest(t, Config{}, func(t *testing.T, s *Service) {
+		ctx := context.Background()
+		var created []*usagev1.Event
+		for i, subject := range []string{"users/alice", "users/bob", "users/alice", "users/carol", "users/alice"} {
+			resp, err := s.CreateEvent(ctx, &usagev1.CreateEventRequest{
+				Event: testEvent(subject, "classify", time.Duration(5-i)*time.Second),
+			})
+			if err != nil {
+				t.Fatalf("CreateEvent() error = %v", err)
+			}
+			created = append(created, resp.GetEvent())
+		}
+		names := func(indexes ...int) []string {
+			var names []string
+			for _, i := range indexes {
+				names = append(names, created[i].GetName())
+			}
+			return names
+		}
+
+		for _, tc := range []struct {
+			name string
+			req  *usagev1.ListEventsRequest
+			want []string
+		}{
+			{"newest first", &usagev1.ListEventsRequest{PageSize: 2}, names(4, 3, 2, 1, 0)},
+			{"order_by", &usagev1.ListEventsRequest{PageSize: 2, OrderBy: "execution_duration"}, names(4, 3, 2, 1, 0)},
+			{"order_by desc", &usagev1.ListEventsRequest{PageSize: 3, OrderBy: "subject desc, create_time"}, names(3, 1, 0, 2, 4)},
+			{"filter", &usagev1.ListEventsRequest{PageSize: 1, Filter: `subject = "users/alice"`}, names(4, 2, 0)},
+			{"filter and order_by", &usagev1.ListEventsRequest{PageSize: 2, Filter: `execution_duration >= 3s`, OrderBy: "create_time"}, names(0, 1, 2)},
+			{"one page", &usagev1.ListEventsRequest{}, names(4, 3, 2, 1, 0)},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				var got []string
+				req := proto.Clone(tc.req).(*usagev1.ListEventsRequest)
+				for pages := 1; ; pages++ {
+					resp, err := s.ListEvents(ctx, req)
+					if err != nil {
+						t.Fatalf("ListEvents() error = %v", err)
+					}
+					if size := tc.req.GetPageSize(); size > 0 && len(resp.GetEvents()) > int(size) {
+						t.Errorf("ListEvents() = %d events, more than page_size %d", len(resp.GetEvents()), tc.req.GetPageSize())
+					}
+					for _, event := range resp.GetEvents() {
+						got = append(got, event.GetName())
+					}
+					if resp.GetNextPageToken() == "" {
+						break
+					}
+					if pages > len(created) {
+						t.Fatalf("ListEvents() returned more pages than events")
+					}
+					req.PageToken = resp.GetNextPageToken()
+				}
+				if !slices.Equal(got, tc.want) {
+					t.Errorf("ListEvents() = %v, want %v", got, tc.want)
+				}
+			})
+		}
+
+		_, err := s.ListEvents(ctx, &usagev1.ListEventsRequest{Filter: `color = "red"`, OrderBy: "labels"})
+		if code, _, fields := errorReason(err); code != codes.InvalidArgument || !slices.Contains(fields, "filter") || !slices.Contains(fields, "order_by") {
+			t.Errorf("ListEvents() with an invalid filter and order_by error = %v, want %v of both", err, codes.InvalidArgument)
+		}
+	})
+}
+
+func TestDeleteAndUndeleteEvent(t *testing.T) {
+	runServiceTest(t, Config{}, func(t *testing.T, s *Service) {
+		ctx := context.Background()
+		resp, err := s.CreateEvent(ctx, &usagev1.CreateEventRequest{Event: testEvent("users/alice", "classify", time.Second)})
+		if err != nil {
+			t.Fatalf("CreateEvent() error = %v", err)
+		}
+		event := resp.GetEvent()
+		listed := func(showDeleted bool) int {
+			t.Helper()
+			resp, err := s.ListEvents(ctx, &usagev1.ListEventsRequest{ShowDeleted: showDeleted})
+			if err != nil {
+				t.Fatalf("ListEvents() error = %v", err)
+			}
+			return len(resp.GetEvents())
+		}
+
+		_, err = s.DeleteEvent(ctx, &usagev1.DeleteEventRequest{Name: event.GetName(), Etag: `"stale"`})
+		if code, reason, _ := errorReason(err); code != codes.Aborted || reason != ReasonEtagMismatch {
+			t.Errorf("DeleteEvent() with a stale etag error = %v, want %v with reason %s", err, codes.Aborted, ReasonEtagMismatch)
+		}
+		deleted, err := s.DeleteEvent(ctx, &usagev1.DeleteEventRequest{Name: event.GetName(), Etag: event.GetEtag()})
+		if err != nil {
+			t.Fatalf("DeleteEvent() error = %v", err)
+		}
+		if deleted.GetEvent().GetDeleteTime() == nil || deleted.GetEvent().GetPurgeTime() == nil {
+			t.Errorf("DeleteEvent() = %v, want the event with its delete_time and purge_time", deleted.GetEvent())
+		}
+		if got, err := s.GetEvent(ctx, &usagev1.GetEventRequest{Name: event.GetName()}); err != nil || got.GetEvent().GetDeleteTime() == nil {
+			t.Errorf("GetEvent() of a deleted event = %v, %v, want the deleted event", got.GetEvent(), err)
+		}
+		if n, all := listed(false), listed(true); n != 0 || all != 1 {
+			t.Errorf("ListEvents() after delete = %d events, %d with show_deleted, want 0 and 1", n, all)
+		}
+		_, err = s.DeleteEvent(ctx, &usagev1.DeleteEventRequest{Name: event.GetName()})
+		if code, reason, _ := errorReason(err); code != codes.FailedPrecondition || reason != ReasonEventDeleted {
+			t.Errorf("DeleteEvent() twice error = %v, want %v with reason %s", err, codes.FailedPrecondition, ReasonEventDeleted)
+		}
+
+		undeleted, err := s.UndeleteEvent(ctx, &usagev1.UndeleteEventRequest{Name: event.GetName()})
+		if err != nil {
+			t.Fatalf("UndeleteEvent() error = %v", err)
+		}
+		if undeleted.GetEvent().GetDeleteTime() != nil || undeleted.GetEvent().GetPurgeTime() != nil || undeleted.GetEvent().GetEtag() != event.GetEtag() {
+			t.Errorf("UndeleteEvent() = %v, want the event as created", undeleted.GetEvent())
+		}
+		if n := listed(false); n != 1 {
+			t.Errorf("ListEvents() after undelete = %d events, want 1", n)
+		}
+		_, err = s.UndeleteEvent(ctx, &usagev1.UndeleteEventRequest{Name: event.GetName()})
+		if code, reason, _ := errorReason(err); code != codes.FailedPrecondition || reason != ReasonEventNotDeleted {
+			t.Errorf("UndeleteEvent() twice error = %v, want %v with reason %s", err, codes.FailedPrecondition, ReasonEventNotDeleted)
+		}
+	})
+}
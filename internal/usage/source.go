@@ -0,0 +1,304 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// sourceOrdering is the order of ListSources. Page tokens are cursors in
+// this order.
+var sourceOrdering = Ordering{{Field: FieldName}}
+
+// mutableSourceFields are the fields of Source that clients can update.
+var mutableSourceFields = mutableFields(&usagev1.Source{})
+
+// SourceService implements the SourceService gRPC handler, the registry of
+// the sources and actions that events may be recorded for.
+type SourceService struct {
+	usagev1.UnimplementedSourceServiceServer
+	store      SourceStore
+	pageTokens pageTokenCodec
+}
+
+// NewSourceService creates a new SourceService backed by the given store.
+// Only cfg.PageTokenKey is used.
+func NewSourceService(store SourceStore, cfg Config) (*SourceService, error) {
+	key, err := pageTokenKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SourceService{
+		store:      store,
+		pageTokens: pageTokenCodec{key: key},
+	}, nil
+}
+
+// ValidateRequest returns the violations of a request other than those of
+// its field behaviors. It implements fieldbehavior.Validator.
+func (s *SourceService) ValidateRequest(req proto.Message) []apierror.FieldViolation {
+	switch req := req.(type) {
+	case *usagev1.CreateSourceRequest:
+		var violations []apierror.FieldViolation
+		if req.GetSourceId() != "" {
+			if err := ValidateSourceID(req.GetSourceId()); err != nil {
+				violations = append(violations, invalidField("source_id", ReasonInvalidSourceID, err))
+			}
+		}
+		return append(violations, validateActions("source.actions", req.GetSource().GetActions())...)
+	case *usagev1.UpdateSourceRequest:
+		paths, err := updatePaths(req.GetSource(), mutableSourceFields, req.GetUpdateMask().GetPaths())
+		if err != nil {
+			// Reported by UpdateSource
+			return nil
+		}
+		if updatesAny(paths, "actions") {
+			return validateActions("source.actions", req.GetSource().GetActions())
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// validateActions returns the violations of the actions of a source, whose
+// path in the request is given by field.
+func validateActions(field string, actions []string) []apierror.FieldViolation {
+	var violations []apierror.FieldViolation
+	for i, action := range actions {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		if err := ValidateAction(action); err != nil {
+			violations = append(violations, invalidField(path, ReasonInvalidAction, err))
+		} else if slices.Index(actions, action) < i {
+			violations = append(violations, invalidField(path, ReasonInvalidAction,
+				fmt.Errorf("action %q is listed more than once", action)))
+		}
+	}
+	return violations
+}
+
+// CreateSource registers a new source.
+func (s *SourceService) CreateSource(ctx context.Context, req *usagev1.CreateSourceRequest) (*usagev1.CreateSourceResponse, error) {
+	if violations := s.ValidateRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	source := &usagev1.Source{
+		Name:        SourceName(req.GetSourceId()),
+		DisplayName: req.GetSource().GetDisplayName(),
+		Actions:     req.GetSource().GetActions(),
+		CreateTime:  timestamppb.Now(),
+	}
+	source.Etag = resourceEtag(source)
+
+	err := s.store.CreateSource(ctx, source)
+	if errors.Is(err, ErrAlreadyExists) {
+		return nil, apierror.New(codes.AlreadyExists, ReasonSourceAlreadyExists,
+			fmt.Sprintf("source %q already exists", source.GetName()), map[string]string{"name": source.GetName()})
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store source: %v", err)
+	}
+	return &usagev1.CreateSourceResponse{Source: source}, nil
+}
+
+// GetSource returns a single source by its resource name.
+func (s *SourceService) GetSource(ctx context.Context, req *usagev1.GetSourceRequest) (*usagev1.GetSourceResponse, error) {
+	if err := validateSourceName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	source, err := s.store.GetSource(ctx, req.GetName())
+	if errors.Is(err, ErrNotFound) {
+		return nil, sourceNotFound(req.GetName())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get source: %v", err)
+	}
+	return &usagev1.GetSourceResponse{Source: source}, nil
+}
+
+// ListSources lists sources with pagination.
+func (s *SourceService) ListSources(ctx context.Context, req *usagev1.ListSourcesRequest) (*usagev1.ListSourcesResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	var after string
+	if req.GetPageToken() != "" {
+		cursor, err := s.pageTokens.decode(req.GetPageToken(), "", false, sourceOrdering)
+		if err != nil {
+			return nil, invalidArgument("page_token", ReasonInvalidPageToken, err)
+		}
+		after = cursor.Name
+	}
+
+	// Read one extra source to find out whether there is a next page
+	sources, err := s.store.ListSources(ctx, after, pageSize+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sources: %v", err)
+	}
+
+	var nextPageToken string
+	if len(sources) > pageSize {
+		sources = sources[:pageSize]
+		nextPageToken, err = s.pageTokens.encode("", false, sourceOrdering, Cursor{Name: sources[pageSize-1].GetName()})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create page token: %v", err)
+		}
+	}
+
+	return &usagev1.ListSourcesResponse{
+		Sources:       sources,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// UpdateSource updates fields of a source.
+func (s *SourceService) UpdateSource(ctx context.Context, req *usagev1.UpdateSourceRequest) (*usagev1.UpdateSourceResponse, error) {
+	name := req.GetSource().GetName()
+	if err := validateSourceName("source.name", name); err != nil {
+		return nil, err
+	}
+
+	paths, err := updatePaths(req.GetSource(), mutableSourceFields, req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, invalidArgument("update_mask", ReasonInvalidUpdateMask, fmt.Errorf("invalid update_mask: %w", err))
+	}
+	if violations := s.ValidateRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	now := time.Now()
+	source, err := s.store.UpdateSource(ctx, name, func(source *usagev1.Source) (*usagev1.Source, error) {
+		if err := checkEtag(source, req.GetSource().GetEtag()); err != nil {
+			return nil, err
+		}
+		applyUpdate(source, req.GetSource(), paths)
+		if violations := fieldbehavior.CheckRequired(source); len(violations) > 0 {
+			return nil, apierror.Prefix(apierror.BadRequest(violations...), "source")
+		}
+
+		source.UpdateTime = timestamppb.New(now)
+		source.Etag = resourceEtag(source)
+		return source, nil
+	})
+	if err != nil {
+		return nil, updateSourceError(err, name)
+	}
+
+	return &usagev1.UpdateSourceResponse{Source: source}, nil
+}
+
+// DeleteSource deletes a source. If an etag is given, it is checked against
+// the stored source first; a concurrent update between the check and the
+// deletion is not detected.
+func (s *SourceService) DeleteSource(ctx context.Context, req *usagev1.DeleteSourceRequest) (*usagev1.DeleteSourceResponse, error) {
+	if err := validateSourceName("name", req.GetName()); err != nil {
+		return nil, err
+	}
+
+	if req.GetEtag() != "" {
+		source, err := s.store.GetSource(ctx, req.GetName())
+		if err != nil {
+			return nil, updateSourceError(err, req.GetName())
+		}
+		if err := checkEtag(source, req.GetEtag()); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.store.DeleteSource(ctx, req.GetName())
+	if errors.Is(err, ErrNotFound) {
+		return nil, sourceNotFound(req.GetName())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete source: %v", err)
+	}
+	return &usagev1.DeleteSourceResponse{}, nil
+}
+
+// validateSourceName checks the source name in the given field of a request.
+func validateSourceName(field, name string) error {
+	if name == "" {
+		return apierror.BadRequest(required(field))
+	}
+	if _, err := ParseSourceName(name); err != nil {
+		return invalidArgument(field, ReasonInvalidSourceName, err)
+	}
+	return nil
+}
+
+// updateSourceError converts an error of SourceStore.UpdateSource to a
+// status error. Status errors returned by the update function are passed
+// through.
+func updateSourceError(err error, name string) error {
+	if errors.Is(err, ErrNotFound) {
+		return sourceNotFound(name)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Errorf(codes.Internal, "failed to update source: %v", err)
+}
+
+func sourceNotFound(name string) error {
+	return apierror.New(codes.NotFound, ReasonSourceNotFound,
+		fmt.Sprintf("source %q not found", name), map[string]string{"name": name})
+}
+
+// sourceViolations returns the violations of the registry by the source and
+// action of event, whose path in the request is given by field, e.g.
+// "event", or empty if event is the request itself. Sources are looked up in
+// cache first, if not nil, and added to it.
+func (s *Service) sourceViolations(ctx context.Context, field string, event *usagev1.Event, cache map[string]*usagev1.Source) ([]apierror.FieldViolation, error) {
+	if !s.checkSources || event.GetSource() == "" {
+		return nil, nil
+	}
+
+	name := SourceName(event.GetSource())
+	source, ok := cache[name]
+	if !ok {
+		var err error
+		source, err = s.store.GetSource(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			source = nil
+		} else if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to look up source: %v", err)
+		}
+		if cache != nil {
+			cache[name] = source
+		}
+	}
+
+	switch {
+	case source == nil:
+		return []apierror.FieldViolation{{
+			Field:       joinPath(field, "source"),
+			Reason:      ReasonSourceNotRegistered,
+			Description: fmt.Sprintf("source %q is not registered", event.GetSource()),
+		}}, nil
+	case event.GetAction() != "" && !slices.Contains(source.GetActions(), event.GetAction()):
+		return []apierror.FieldViolation{{
+			Field:       joinPath(field, "action"),
+			Reason:      ReasonActionNotRegistered,
+			Description: fmt.Sprintf("action %q is not registered for source %q", event.GetAction(), event.GetSource()),
+		}}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,7 @@
+CREATE TABLE prices (
+    name   TEXT NOT NULL PRIMARY KEY,
+    parent TEXT NOT NULL, -- resource name of the action
+    data   BLOB NOT NULL  -- serialized ai.h2o.usage.v1.Price
+);
+
+CREATE INDEX prices_parent_idx ON prices (parent, name);
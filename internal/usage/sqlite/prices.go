@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// CreatePrice implements usage.PriceStore. The store has a single
+// connection, so the transaction serializes the changes of siblings.
+func (s *Store) CreatePrice(ctx context.Context, price *usagev1.Price, check func(siblings []*usagev1.Price) error) error {
+	data, err := proto.Marshal(price)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	parent := usage.PriceParent(price.GetName())
+	siblings, err := queryPrices(ctx, tx, `SELECT data FROM prices WHERE parent = ? ORDER BY name`, parent)
+	if err != nil {
+		return err
+	}
+	if err := check(siblings); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO prices (name, parent, data) VALUES (?, ?, ?)`, price.GetName(), parent, data)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return usage.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetPrice implements usage.PriceStore.
+func (s *Store) GetPrice(ctx context.Context, name string) (*usagev1.Price, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM prices WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPrice(data)
+}
+
+// ListPrices implements usage.PriceStore.
+func (s *Store) ListPrices(ctx context.Context, parent, after string, limit int) ([]*usagev1.Price, error) {
+	// A negative LIMIT means no limit in SQLite
+	if limit <= 0 {
+		limit = -1
+	}
+	return queryPrices(ctx, s.db, `SELECT data FROM prices WHERE parent = ? AND name > ? ORDER BY name LIMIT ?`, parent, after, limit)
+}
+
+// UpdatePrice implements usage.PriceStore.
+func (s *Store) UpdatePrice(ctx context.Context, name string, update func(*usagev1.Price, []*usagev1.Price) (*usagev1.Price, error)) (*usagev1.Price, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var data []byte
+	err = tx.QueryRowContext(ctx, `SELECT data FROM prices WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	price, err := unmarshalPrice(data)
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := queryPrices(ctx, tx, `SELECT data FROM prices WHERE parent = ? ORDER BY name`, usage.PriceParent(name))
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := update(price, siblings)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = proto.Marshal(updated); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE prices SET data = ? WHERE name = ?`, data, name); err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// DeletePrice implements usage.PriceStore.
+func (s *Store) DeletePrice(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM prices WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+// FindPrice implements usage.PriceStore. Actions have few prices, so their
+// effective times are compared in Go.
+func (s *Store) FindPrice(ctx context.Context, parent string, t time.Time) (*usagev1.Price, error) {
+	prices, err := queryPrices(ctx, s.db, `SELECT data FROM prices WHERE parent = ? ORDER BY name`, parent)
+	if err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		if usage.PriceInEffect(price, t) {
+			return price, nil
+		}
+	}
+	return nil, usage.ErrNotFound
+}
+
+// queryPrices runs a query selecting the data of prices.
+func queryPrices(ctx context.Context, q queryer, query string, args ...any) ([]*usagev1.Price, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*usagev1.Price
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		price, err := unmarshalPrice(data)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+func unmarshalPrice(data []byte) (*usagev1.Price, error) {
+	price := &usagev1.Price{}
+	if err := proto.Unmarshal(data, price); err != nil {
+		return nil, fmt.Errorf("decode stored price: %w", err)
+	}
+	return price, nil
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// AddRollups implements usage.RollupStore.
+func (s *Store) AddRollups(ctx context.Context, deltas []*usage.Rollup) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, delta := range deltas {
+		k := delta.RollupKey
+		r := &usage.Rollup{RollupKey: k}
+		var data []byte
+		err := tx.QueryRowContext(ctx, `
+			SELECT data FROM rollups
+			WHERE granularity = ? AND start_time = ? AND subject = ? AND source = ? AND action = ?`,
+			k.Granularity, k.Start.UnixNano(), k.Subject, k.Source, k.Action).Scan(&data)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, r); err != nil {
+				return fmt.Errorf("decode stored rollup: %w", err)
+			}
+		}
+
+		if r.Add(delta); r.Empty() {
+			_, err = tx.ExecContext(ctx, `
+				DELETE FROM rollups
+				WHERE granularity = ? AND start_time = ? AND subject = ? AND source = ? AND action = ?`,
+				k.Granularity, k.Start.UnixNano(), k.Subject, k.Source, k.Action)
+		} else {
+			err = insertRollup(ctx, tx, r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListRollups implements usage.RollupStore.
+func (s *Store) ListRollups(ctx context.Context, query usage.RollupQuery) ([]*usage.Rollup, error) {
+	q := `SELECT granularity, start_time, subject, source, action, data FROM rollups WHERE 1 = 1`
+	var args []any
+	if query.Granularity != "" {
+		q += ` AND granularity = ?`
+		args = append(args, query.Granularity)
+	}
+	if !query.Start.IsZero() {
+		q += ` AND start_time >= ?`
+		args = append(args, query.Start.UnixNano())
+	}
+	if !query.End.IsZero() {
+		q += ` AND start_time < ?`
+		args = append(args, query.End.UnixNano())
+	}
+	if query.Subject != "" {
+		q += ` AND subject = ?`
+		args = append(args, query.Subject)
+	}
+	rows, err := s.db.QueryContext(ctx, q+` ORDER BY start_time`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*usage.Rollup
+	for rows.Next() {
+		r := &usage.Rollup{}
+		var start int64
+		var data []byte
+		if err := rows.Scan(&r.Granularity, &start, &r.Subject, &r.Source, &r.Action, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, r); err != nil {
+			return nil, fmt.Errorf("decode stored rollup: %w", err)
+		}
+		r.Start = time.Unix(0, start).UTC()
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+// ReplaceRollups implements usage.RollupStore.
+func (s *Store) ReplaceRollups(ctx context.Context, rollups []*usage.Rollup) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM rollups`); err != nil {
+		return err
+	}
+	for _, r := range rollups {
+		if err := insertRollup(ctx, tx, r); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// insertRollup stores r, replacing the stored rollup with the same key.
+func insertRollup(ctx context.Context, db execer, r *usage.Rollup) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO rollups (granularity, start_time, subject, source, action, data)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (granularity, start_time, subject, source, action) DO UPDATE SET data = excluded.data`,
+		r.Granularity, r.Start.UnixNano(), r.Subject, r.Source, r.Action, data)
+	return err
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// CreateSource implements usage.SourceStore.
+func (s *Store) CreateSource(ctx context.Context, source *usagev1.Source) error {
+	data, err := proto.Marshal(source)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO sources (name, data) VALUES (?, ?)`, source.GetName(), data)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return usage.ErrAlreadyExists
+	}
+	return err
+}
+
+// GetSource implements usage.SourceStore.
+func (s *Store) GetSource(ctx context.Context, name string) (*usagev1.Source, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM sources WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalSource(data)
+}
+
+// ListSources implements usage.SourceStore.
+func (s *Store) ListSources(ctx context.Context, after string, limit int) ([]*usagev1.Source, error) {
+	// A negative LIMIT means no limit in SQLite
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM sources WHERE name > ? ORDER BY name LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []*usagev1.Source
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		source, err := unmarshalSource(data)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
+
+// UpdateSource implements usage.SourceStore.
+func (s *Store) UpdateSource(ctx context.Context, name string, update func(*usagev1.Source) (*usagev1.Source, error)) (*usagev1.Source, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var data []byte
+	err = tx.QueryRowContext(ctx, `SELECT data FROM sources WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	source, err := unmarshalSource(data)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := update(source)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = proto.Marshal(updated); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE sources SET data = ? WHERE name = ?`, data, name); err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// DeleteSource implements usage.SourceStore.
+func (s *Store) DeleteSource(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sources WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+func unmarshalSource(data []byte) (*usagev1.Source, error) {
+	source := &usagev1.Source{}
+	if err := proto.Unmarshal(data, source); err != nil {
+		return nil, fmt.Errorf("decode stored source: %w", err)
+	}
+	return source, nil
+}
@@ -0,0 +1,288 @@
+// Package sqlite implements a durable usage.Store on top of SQLite.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+	"github.com/jan-sykora/api-demo/internal/usage/sqlorder"
+)
+
+// Store is a usage.Store backed by a SQLite database file.
+type Store struct {
+	db *sql.DB
+}
+
+var _ usage.Store = (*Store)(nil)
+
+// Open opens (creating if needed) the SQLite database at path and migrates it
+// to the latest schema.
+func Open(ctx context.Context, path string) (*Store, error) {
+	// WAL lets readers proceed while a write is in progress; the busy timeout
+	// makes concurrent writers wait for the lock instead of failing.
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err := runMigrations(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// CreateEvent implements usage.EventStore.
+func (s *Store) CreateEvent(ctx context.Context, event *usagev1.Event) error {
+	return insertEvent(ctx, s.db, event)
+}
+
+// BatchCreateEvents implements usage.EventStore.
+func (s *Store) BatchCreateEvents(ctx context.Context, events []*usagev1.Event) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, event := range events {
+		err := insertEvent(ctx, tx, event)
+		if errors.Is(err, usage.ErrAlreadyExists) {
+			return &usage.BatchError{Index: i, Name: event.GetName(), Err: err}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertEvent(ctx context.Context, db execer, event *usagev1.Event) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO events (name, subject, source, action, execution_duration, create_time, purge_time, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.GetName(),
+		event.GetSubject(),
+		event.GetSource(),
+		event.GetAction(),
+		event.GetExecutionDuration().AsDuration().Nanoseconds(),
+		event.GetCreateTime().AsTime().UnixNano(),
+		purgeTime(event),
+		data,
+	)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return usage.ErrAlreadyExists
+	}
+	return err
+}
+
+// GetEvent implements usage.EventStore.
+func (s *Store) GetEvent(ctx context.Context, name string) (*usagev1.Event, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM events WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEvent(data)
+}
+
+// purgeTime returns the purge_time column value of an event.
+func purgeTime(event *usagev1.Event) any {
+	if event.GetPurgeTime() == nil {
+		return nil
+	}
+	return event.GetPurgeTime().AsTime().UnixNano()
+}
+
+// ListEvents implements usage.EventStore.
+func (s *Store) ListEvents(ctx context.Context, query usage.ListQuery) ([]*usagev1.Event, error) {
+	ordering := query.OrderBy
+	if ordering == nil {
+		ordering = usage.DefaultOrdering
+	}
+	return usage.ScanEvents(query, func(after *usage.Cursor, limit int) ([]*usagev1.Event, error) {
+		return s.listEvents(ctx, ordering, after, limit, query.ShowDeleted)
+	})
+}
+
+// listEvents reads up to limit events following after in the ordering,
+// including the soft-deleted ones if showDeleted is set.
+func (s *Store) listEvents(ctx context.Context, ordering usage.Ordering, after *usage.Cursor, limit int, showDeleted bool) ([]*usagev1.Event, error) {
+	// A negative LIMIT means no limit in SQLite
+	if limit <= 0 {
+		limit = -1
+	}
+
+	q := `SELECT data FROM events`
+	var conds []string
+	var args []any
+	if !showDeleted {
+		// Only soft-deleted events have a purge time
+		conds = append(conds, `purge_time IS NULL`)
+	}
+	if after != nil {
+		cond, keysetArgs := sqlorder.Keyset(ordering, func(field string) any {
+			return cursorValue(*after, field)
+		}, func(int) string { return "?" })
+		conds = append(conds, "("+cond+")")
+		args = append(args, keysetArgs...)
+	}
+	if len(conds) > 0 {
+		q += ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+	q += ` ORDER BY ` + sqlorder.OrderBy(ordering) + ` LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*usagev1.Event
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		event, err := unmarshalEvent(data)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// cursorValue returns the value of a cursor field as stored in the events
+// table.
+func cursorValue(c usage.Cursor, field string) any {
+	switch field {
+	case usage.FieldName:
+		return c.Name
+	case usage.FieldSubject:
+		return c.Subject
+	case usage.FieldSource:
+		return c.Source
+	case usage.FieldAction:
+		return c.Action
+	case usage.FieldCreateTime:
+		return c.CreateTime.UnixNano()
+	case usage.FieldExecutionDuration:
+		return c.ExecutionDuration.Nanoseconds()
+	default:
+		panic(fmt.Sprintf("sqlite: unknown cursor field %q", field))
+	}
+}
+
+// UpdateEvent implements usage.EventStore.
+func (s *Store) UpdateEvent(ctx context.Context, name string, update func(*usagev1.Event) (*usagev1.Event, error)) (*usagev1.Event, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var data []byte
+	err = tx.QueryRowContext(ctx, `SELECT data FROM events WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	event, err := unmarshalEvent(data)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := update(event)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = proto.Marshal(updated); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`UPDATE events
+		SET subject = ?, source = ?, action = ?, execution_duration = ?, create_time = ?, purge_time = ?, data = ?
+		WHERE name = ?`,
+		updated.GetSubject(),
+		updated.GetSource(),
+		updated.GetAction(),
+		updated.GetExecutionDuration().AsDuration().Nanoseconds(),
+		updated.GetCreateTime().AsTime().UnixNano(),
+		purgeTime(updated),
+		data,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return updated, tx.Commit()
+}
+
+// DeleteEvent implements usage.EventStore.
+func (s *Store) DeleteEvent(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM events WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return usage.ErrNotFound
+	}
+	return nil
+}
+
+func unmarshalEvent(data []byte) (*usagev1.Event, error) {
+	event := &usagev1.Event{}
+	if err := proto.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("decode stored event: %w", err)
+	}
+	return event, nil
+}
+
+// PurgeEvents implements usage.EventStore.
+func (s *Store) PurgeEvents(ctx context.Context, now time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM events WHERE purge_time <= ?`, now.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jan-sykora/api-demo/internal/usage"
+	"github.com/jan-sykora/api-demo/internal/usage/storetest"
+)
+
+// openTestStore opens a store on a new database file.
+func openTestStore(t *testing.T, path string) *Store {
+	t.Helper()
+	store, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) usage.Store {
+		return openTestStore(t, filepath.Join(t.TempDir(), "usage.db"))
+	})
+}
+
+func TestMigrationsAreIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.db")
+	for i := 0; i < 2; i++ {
+		store, err := Open(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Open() #%d error = %v", i+1, err)
+		}
+		store.Close()
+	}
+}
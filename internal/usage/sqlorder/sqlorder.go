@@ -0,0 +1,96 @@
+// Package sqlorder builds the ORDER BY and keyset pagination clauses shared
+// by the SQL event stores.
+package sqlorder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// columns maps the orderable event fields to columns of the events table.
+var columns = map[string]string{
+	usage.FieldName:              "name",
+	usage.FieldSubject:           "subject",
+	usage.FieldSource:            "source",
+	usage.FieldAction:            "action",
+	usage.FieldCreateTime:        "create_time",
+	usage.FieldExecutionDuration: "execution_duration",
+}
+
+func column(field string) string {
+	col, ok := columns[field]
+	if !ok {
+		panic(fmt.Sprintf("sqlorder: cannot order by %q", field))
+	}
+	return col
+}
+
+// OrderBy returns the ORDER BY expression list for the ordering.
+func OrderBy(ordering usage.Ordering) string {
+	parts := make([]string, len(ordering))
+	for i, f := range ordering {
+		parts[i] = column(f.Field)
+		if f.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Keyset returns a condition selecting the rows that sort strictly after the
+// cursor in the ordering, together with its arguments. value converts a
+// cursor field to a query argument; placeholder returns the placeholder of
+// the n-th argument, counting from 1.
+//
+// When all keys share a direction the condition is a row comparison such as
+// `(a, b) < ($1, $2)`, which both SQLite and PostgreSQL serve from a matching
+// index. Otherwise it is expanded, e.g. for `a, b desc` to
+// `(a > $1) OR (a = $2 AND b < $3)`.
+func Keyset(ordering usage.Ordering, value func(field string) any, placeholder func(n int) string) (string, []any) {
+	if sameDirection(ordering) {
+		cols := make([]string, len(ordering))
+		params := make([]string, len(ordering))
+		args := make([]any, len(ordering))
+		for i, f := range ordering {
+			cols[i] = column(f.Field)
+			params[i] = placeholder(i + 1)
+			args[i] = value(f.Field)
+		}
+		op := ">"
+		if ordering[0].Desc {
+			op = "<"
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(params, ", ")), args
+	}
+
+	var (
+		terms []string
+		args  []any
+	)
+	for i, f := range ordering {
+		var conds []string
+		for _, prev := range ordering[:i] {
+			args = append(args, value(prev.Field))
+			conds = append(conds, fmt.Sprintf("%s = %s", column(prev.Field), placeholder(len(args))))
+		}
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+		args = append(args, value(f.Field))
+		conds = append(conds, fmt.Sprintf("%s %s %s", column(f.Field), op, placeholder(len(args))))
+		terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+	}
+	return strings.Join(terms, " OR "), args
+}
+
+func sameDirection(ordering usage.Ordering) bool {
+	for _, f := range ordering[1:] {
+		if f.Desc != ordering[0].Desc {
+			return false
+		}
+	}
+	return true
+}
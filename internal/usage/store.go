@@ -0,0 +1,196 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+var (
+	// ErrNotFound is returned by a store when the resource does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned by a store when a resource with the same
+	// name is already stored.
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+// BatchError is returned by EventStore.BatchCreateEvents when an event of
+// the batch cannot be stored.
+type BatchError struct {
+	// Index is the position of the event in the batch.
+	Index int
+	// Name is the resource name of the event.
+	Name string
+	// Err is the reason the event cannot be stored, e.g. ErrAlreadyExists.
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("events[%d] %s: %v", e.Index, e.Name, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ListQuery describes a page of events to read from an EventStore.
+type ListQuery struct {
+	// OrderBy is the listing order. A nil OrderBy means DefaultOrdering.
+	OrderBy Ordering
+	// After, if set, restricts the result to events strictly after the cursor
+	// in the OrderBy order.
+	After *Cursor
+	// Limit is the maximum number of events to return.
+	Limit int
+	// Filter, if set, restricts the result to events it matches.
+	Filter Predicate
+	// ShowDeleted includes soft-deleted events in the result, which are
+	// excluded otherwise.
+	ShowDeleted bool
+}
+
+// EventStore persists usage events.
+type EventStore interface {
+	// CreateEvent stores a new event. It returns ErrAlreadyExists if an event
+	// with the same name is already stored.
+	CreateEvent(ctx context.Context, event *usagev1.Event) error
+	// BatchCreateEvents stores several new events atomically: if any of them
+	// has the same name as a stored event or another event of the batch, none
+	// are stored and a *BatchError wrapping ErrAlreadyExists is returned.
+	BatchCreateEvents(ctx context.Context, events []*usagev1.Event) error
+	// GetEvent returns the event with the given resource name, or ErrNotFound.
+	GetEvent(ctx context.Context, name string) (*usagev1.Event, error)
+	// ListEvents returns up to query.Limit events following query.After that
+	// match query.Filter, excluding soft-deleted events unless
+	// query.ShowDeleted is set.
+	ListEvents(ctx context.Context, query ListQuery) ([]*usagev1.Event, error)
+	// UpdateEvent atomically replaces the event with the given resource name
+	// by the result of update, which receives the stored event. It returns
+	// ErrNotFound if there is no such event; if update fails, its error is
+	// returned and the event is left unchanged.
+	UpdateEvent(ctx context.Context, name string, update func(*usagev1.Event) (*usagev1.Event, error)) (*usagev1.Event, error)
+	// DeleteEvent removes the event with the given resource name, or returns
+	// ErrNotFound.
+	DeleteEvent(ctx context.Context, name string) error
+	// PurgeEvents permanently removes the soft-deleted events whose purge
+	// time is not after now, and returns how many were removed.
+	PurgeEvents(ctx context.Context, now time.Time) (int, error)
+}
+
+// SourceStore persists the registry of event sources.
+type SourceStore interface {
+	// CreateSource stores a new source. It returns ErrAlreadyExists if a
+	// source with the same name is already stored.
+	CreateSource(ctx context.Context, source *usagev1.Source) error
+	// GetSource returns the source with the given resource name, or
+	// ErrNotFound.
+	GetSource(ctx context.Context, name string) (*usagev1.Source, error)
+	// ListSources returns up to limit sources ordered by name, starting after
+	// the given name. A limit of zero means no limit.
+	ListSources(ctx context.Context, after string, limit int) ([]*usagev1.Source, error)
+	// UpdateSource atomically replaces the source with the given resource
+	// name by the result of update, like EventStore.UpdateEvent.
+	UpdateSource(ctx context.Context, name string, update func(*usagev1.Source) (*usagev1.Source, error)) (*usagev1.Source, error)
+	// DeleteSource removes the source with the given resource name, or
+	// returns ErrNotFound.
+	DeleteSource(ctx context.Context, name string) error
+}
+
+// PriceStore persists the price catalog. The prices of an action are its
+// siblings; stores serialize the changes of siblings so that a check of the
+// siblings cannot miss a concurrent change.
+type PriceStore interface {
+	// CreatePrice stores a new price if check accepts its siblings. It
+	// returns ErrAlreadyExists if a price with the same name is already
+	// stored, and the error of check if it fails.
+	CreatePrice(ctx context.Context, price *usagev1.Price, check func(siblings []*usagev1.Price) error) error
+	// GetPrice returns the price with the given resource name, or
+	// ErrNotFound.
+	GetPrice(ctx context.Context, name string) (*usagev1.Price, error)
+	// ListPrices returns up to limit prices of the action with the given
+	// resource name ordered by name, starting after the given name. A limit
+	// of zero means no limit.
+	ListPrices(ctx context.Context, parent, after string, limit int) ([]*usagev1.Price, error)
+	// UpdatePrice atomically replaces the price with the given resource name
+	// by the result of update, like EventStore.UpdateEvent. update also
+	// receives the siblings of the price.
+	UpdatePrice(ctx context.Context, name string, update func(price *usagev1.Price, siblings []*usagev1.Price) (*usagev1.Price, error)) (*usagev1.Price, error)
+	// DeletePrice removes the price with the given resource name, or returns
+	// ErrNotFound.
+	DeletePrice(ctx context.Context, name string) error
+	// FindPrice returns the price of the action with the given resource name
+	// whose effective time contains t, or ErrNotFound.
+	FindPrice(ctx context.Context, parent string, t time.Time) (*usagev1.Price, error)
+}
+
+// RollupQuery describes the rollups to read from a RollupStore.
+type RollupQuery struct {
+	// Granularity, if set, restricts the result to rollups of the
+	// granularity.
+	Granularity Granularity
+	// Start and End, if not zero, restrict the result to rollups starting
+	// at or after Start and before End.
+	Start, End time.Time
+	// Subject, if set, restricts the result to rollups of the subject.
+	Subject string
+}
+
+// RollupStore persists the rollups of events.
+type RollupStore interface {
+	// AddRollups atomically adds the totals of deltas to the stored rollups
+	// with the same keys, creating those that are missing and removing those
+	// left empty. The keys of deltas are distinct.
+	AddRollups(ctx context.Context, deltas []*Rollup) error
+	// ListRollups returns the rollups matching query, ordered by start.
+	ListRollups(ctx context.Context, query RollupQuery) ([]*Rollup, error)
+	// ReplaceRollups atomically replaces all stored rollups by rollups.
+	ReplaceRollups(ctx context.Context, rollups []*Rollup) error
+}
+
+// Store persists everything the usage services need.
+type Store interface {
+	EventStore
+	SourceStore
+	PriceStore
+	RollupStore
+}
+
+// filterBatchSize is the number of events ScanEvents reads at a time when
+// the query has a filter.
+const filterBatchSize = 200
+
+// ScanEvents implements the filtering of a ListQuery for stores that can only
+// read events in listing order. It reads batches of events with fetch until
+// query.Limit events matching query.Filter are found or the events run out.
+func ScanEvents(query ListQuery, fetch func(after *Cursor, limit int) ([]*usagev1.Event, error)) ([]*usagev1.Event, error) {
+	if query.Filter == nil {
+		return fetch(query.After, query.Limit)
+	}
+
+	batchSize := max(query.Limit, filterBatchSize)
+	after := query.After
+	var result []*usagev1.Event
+	for {
+		batch, err := fetch(after, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range batch {
+			if !query.Filter(event) {
+				continue
+			}
+			result = append(result, event)
+			if query.Limit > 0 && len(result) == query.Limit {
+				return result, nil
+			}
+		}
+		if len(batch) < batchSize {
+			return result, nil
+		}
+		last := CursorOf(batch[len(batch)-1])
+		after = &last
+	}
+}
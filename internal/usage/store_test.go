@@ -0,0 +1,26 @@
+package usage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jan-sykora/api-demo/internal/usage"
+	"github.com/jan-sykora/api-demo/internal/usage/sqlite"
+	"github.com/jan-sykora/api-demo/internal/usage/storetest"
+)
+
+func init() {
+	usage.RegisterTestStore("sqlite", func(t *testing.T) usage.Store {
+		store, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "usage.db"))
+		if err != nil {
+			t.Fatalf("sqlite.Open() error = %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) usage.Store { return usage.NewMemoryStore() })
+}
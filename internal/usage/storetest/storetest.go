@@ -0,0 +1,663 @@
+// Package storetest tests implementations of usage.Store. Each store
+// package runs the same suite, so that the service behaves alike on all of
+// them:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) usage.Store { return openTestStore(t) })
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/usage"
+)
+
+// Run runs the suite against the stores returned by open, which must be
+// empty and are used by a single test each.
+func Run(t *testing.T, open func(t *testing.T) usage.Store) {
+	for _, test := range []struct {
+		name string
+		fn   func(t *testing.T, store usage.Store)
+	}{
+		{"CreateAndGetEvent", testCreateAndGetEvent},
+		{"CreateEventAlreadyExists", testCreateEventAlreadyExists},
+		{"BatchCreateEventsIsAtomic", testBatchCreateEventsIsAtomic},
+		{"GetEventNotFound", testGetEventNotFound},
+		{"ListEventsKeysetPagination", testListEventsKeysetPagination},
+		{"ListEventsOrderings", testListEventsOrderings},
+		{"ListEventsOrderingsAfterChanges", testListEventsOrderingsAfterChanges},
+		{"ListEventsFilter", testListEventsFilter},
+		{"DeleteEvent", testDeleteEvent},
+		{"UpdateEvent", testUpdateEvent},
+		{"PurgeEvents", testPurgeEvents},
+		{"Sources", testSources},
+		{"Prices", testPrices},
+		{"Rollups", testRollups},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, open(t))
+		})
+	}
+}
+
+// newEvent returns an event as created by the service. Times are whole
+// microseconds, the precision of all stores.
+func newEvent(id string, createTime time.Time) *usagev1.Event {
+	return &usagev1.Event{
+		Name:              "events/" + id,
+		Subject:           "users/alice",
+		Source:            "animal-classifier",
+		Action:            "classify",
+		ExecutionDuration: durationpb.New(1500 * time.Millisecond),
+		Labels:            map[string]string{"model_version": "v1"},
+		CreateTime:        timestamppb.New(createTime),
+	}
+}
+
+// createEvents stores events, failing the test if any cannot be.
+func createEvents(t *testing.T, store usage.Store, events ...*usagev1.Event) {
+	t.Helper()
+	for _, event := range events {
+		if err := store.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("CreateEvent(%s) error = %v", event.GetName(), err)
+		}
+	}
+}
+
+// listAll reads all pages of the events matching query, limit events at a
+// time, and returns their names.
+func listAll(t *testing.T, store usage.Store, query usage.ListQuery) []string {
+	t.Helper()
+	var names []string
+	for {
+		page, err := store.ListEvents(context.Background(), query)
+		if err != nil {
+			t.Fatalf("ListEvents() error = %v", err)
+		}
+		for _, event := range page {
+			names = append(names, event.GetName())
+		}
+		if len(page) < query.Limit {
+			return names
+		}
+		after := usage.CursorOf(page[len(page)-1])
+		query.After = &after
+	}
+}
+
+func testCreateAndGetEvent(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	event := newEvent("a", time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC))
+	event.Cost = &money.Money{CurrencyCode: "USD", Units: 1, Nanos: 250000000}
+	event.PriceVersion = "2025-01"
+	event.UpdateTime = timestamppb.New(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC))
+	event.Etag = `W/"1"`
+	if err := store.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+
+	got, err := store.GetEvent(ctx, event.GetName())
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	if !proto.Equal(got, event) {
+		t.Errorf("GetEvent() = %v, want %v", got, event)
+	}
+}
+
+func testCreateEventAlreadyExists(t *testing.T, store usage.Store) {
+	event := newEvent("a", time.Now().Truncate(time.Microsecond))
+	createEvents(t, store, event)
+	if err := store.CreateEvent(context.Background(), event); !errors.Is(err, usage.ErrAlreadyExists) {
+		t.Errorf("CreateEvent() duplicate error = %v, want %v", err, usage.ErrAlreadyExists)
+	}
+}
+
+func testBatchCreateEventsIsAtomic(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+	createEvents(t, store, newEvent("b", now))
+
+	for _, tc := range []struct {
+		name  string
+		batch []*usagev1.Event
+		index int
+		event string
+	}{
+		{"stored event", []*usagev1.Event{newEvent("a", now), newEvent("b", now)}, 1, "events/b"},
+		{"event of the batch", []*usagev1.Event{newEvent("a", now), newEvent("c", now), newEvent("a", now)}, 2, "events/a"},
+	} {
+		err := store.BatchCreateEvents(ctx, tc.batch)
+		if !errors.Is(err, usage.ErrAlreadyExists) {
+			t.Fatalf("BatchCreateEvents() with a duplicate %s error = %v, want %v", tc.name, err, usage.ErrAlreadyExists)
+		}
+		var batchErr *usage.BatchError
+		if !errors.As(err, &batchErr) || batchErr.Index != tc.index || batchErr.Name != tc.event {
+			t.Errorf("BatchCreateEvents() with a duplicate %s error = %v, want a *usage.BatchError of %s at %d", tc.name, err, tc.event, tc.index)
+		}
+		for _, name := range []string{"events/a", "events/c"} {
+			if _, err := store.GetEvent(ctx, name); !errors.Is(err, usage.ErrNotFound) {
+				t.Errorf("GetEvent(%s) after failed batch error = %v, want %v", name, err, usage.ErrNotFound)
+			}
+		}
+	}
+
+	if err := store.BatchCreateEvents(ctx, []*usagev1.Event{newEvent("a", now), newEvent("c", now)}); err != nil {
+		t.Fatalf("BatchCreateEvents() error = %v", err)
+	}
+	for _, name := range []string{"events/a", "events/c"} {
+		if _, err := store.GetEvent(ctx, name); err != nil {
+			t.Errorf("GetEvent(%q) error = %v", name, err)
+		}
+	}
+}
+
+func testGetEventNotFound(t *testing.T, store usage.Store) {
+	if _, err := store.GetEvent(context.Background(), "events/missing"); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("GetEvent() error = %v, want %v", err, usage.ErrNotFound)
+	}
+}
+
+func testListEventsKeysetPagination(t *testing.T, store usage.Store) {
+	// Two events share a create time to exercise the name tie-breaker
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createEvents(t, store,
+		newEvent("a", base),
+		newEvent("b", base.Add(time.Second)),
+		newEvent("c", base.Add(time.Second)),
+		newEvent("d", base.Add(2*time.Second)),
+		newEvent("e", base.Add(3*time.Second)),
+	)
+
+	got := listAll(t, store, usage.ListQuery{Limit: 2})
+	want := []string{"events/e", "events/d", "events/c", "events/b", "events/a"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListEvents() pages = %v, want %v", got, want)
+	}
+}
+
+func testListEventsOrderings(t *testing.T, store usage.Store) {
+	// Events tie on every field but the name with some other event
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []*usagev1.Event
+	for i := range 9 {
+		event := newEvent(fmt.Sprintf("e%d", i), base.Add(time.Duration(i%3)*time.Microsecond))
+		event.Subject = []string{"users/alice", "users/bob"}[i%2]
+		event.Action = []string{"classify", "detect"}[i/5]
+		event.ExecutionDuration = durationpb.New(time.Duration(i%4) * time.Second)
+		events = append(events, event)
+	}
+	createEvents(t, store, events...)
+
+	for _, orderBy := range []string{
+		"",
+		"create_time",
+		"execution_duration desc, create_time",
+		"subject, action desc",
+		"action, execution_duration, subject desc",
+		"name",
+	} {
+		ordering, err := usage.ParseOrderBy(orderBy)
+		if err != nil {
+			t.Fatalf("ParseOrderBy(%q) error = %v", orderBy, err)
+		}
+		sorted := slices.Clone(events)
+		slices.SortFunc(sorted, func(a, b *usagev1.Event) int {
+			return ordering.Compare(usage.CursorOf(a), usage.CursorOf(b))
+		})
+		var want []string
+		for _, event := range sorted {
+			want = append(want, event.GetName())
+		}
+
+		for _, limit := range []int{1, 2, 4} {
+			got := listAll(t, store, usage.ListQuery{OrderBy: ordering, Limit: limit})
+			if !slices.Equal(got, want) {
+				t.Errorf("ListEvents() ordered by %q in pages of %d = %v, want %v", orderBy, limit, got, want)
+			}
+		}
+	}
+}
+
+func testListEventsOrderingsAfterChanges(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := make(map[string]*usagev1.Event)
+	add := func(i int) {
+		event := newEvent(fmt.Sprintf("e%02d", i), base.Add(time.Duration(i%4)*time.Microsecond))
+		event.Subject = []string{"users/alice", "users/bob", "users/carol"}[i%3]
+		event.ExecutionDuration = durationpb.New(time.Duration(i%5) * time.Second)
+		createEvents(t, store, event)
+		events[event.GetName()] = event
+	}
+	for i := range 12 {
+		add(i)
+	}
+
+	// Many orderings of two fields, each in both directions
+	fields := []string{usage.FieldCreateTime, usage.FieldExecutionDuration, usage.FieldSubject, usage.FieldSource, usage.FieldAction}
+	var orderings []usage.Ordering
+	for i, first := range fields {
+		for j, second := range fields {
+			if i == j {
+				continue
+			}
+			orderBy := first + " desc, " + second
+			if (i+j)%2 == 0 {
+				orderBy = first + ", " + second + " desc"
+			}
+			ordering, err := usage.ParseOrderBy(orderBy)
+			if err != nil {
+				t.Fatalf("ParseOrderBy(%q) error = %v", orderBy, err)
+			}
+			orderings = append(orderings, ordering)
+		}
+	}
+	check := func(step string) {
+		t.Helper()
+		for _, ordering := range orderings {
+			sorted := slices.SortedFunc(maps.Values(events), func(a, b *usagev1.Event) int {
+				return ordering.Compare(usage.CursorOf(a), usage.CursorOf(b))
+			})
+			var want []string
+			for _, event := range sorted {
+				want = append(want, event.GetName())
+			}
+			if got := listAll(t, store, usage.ListQuery{OrderBy: ordering, Limit: 5}); !slices.Equal(got, want) {
+				t.Errorf("after %s, ListEvents() ordered by %q = %v, want %v", step, ordering, got, want)
+			}
+		}
+	}
+	check("creating events")
+
+	// Listings reflect the events created, updated and deleted since the
+	// orderings were last listed
+	for i := 12; i < 16; i++ {
+		add(i)
+	}
+	for _, name := range []string{"events/e01", "events/e07", "events/e14"} {
+		updated, err := store.UpdateEvent(ctx, name, func(event *usagev1.Event) (*usagev1.Event, error) {
+			event.Subject = "users/dave"
+			event.Action = "detect"
+			event.ExecutionDuration = durationpb.New(time.Minute)
+			return event, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateEvent(%s) error = %v", name, err)
+		}
+		events[name] = updated
+	}
+	for _, name := range []string{"events/e00", "events/e05", "events/e15"} {
+		if err := store.DeleteEvent(ctx, name); err != nil {
+			t.Fatalf("DeleteEvent(%s) error = %v", name, err)
+		}
+		delete(events, name)
+	}
+	check("changing events")
+}
+
+func testListEventsFilter(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []*usagev1.Event
+	for i := range 10 {
+		event := newEvent(fmt.Sprintf("e%d", i), base.Add(time.Duration(i)*time.Second))
+		if i%3 == 0 {
+			event.Subject = "users/bob"
+		}
+		if i%4 == 0 {
+			event.DeleteTime = timestamppb.New(base.Add(time.Hour))
+			event.PurgeTime = timestamppb.New(base.Add(31 * 24 * time.Hour))
+		}
+		events = append(events, event)
+	}
+	createEvents(t, store, events...)
+	// A deleted event can be restored
+	if _, err := store.UpdateEvent(ctx, "events/e8", func(event *usagev1.Event) (*usagev1.Event, error) {
+		event.DeleteTime, event.PurgeTime = nil, nil
+		return event, nil
+	}); err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+
+	ofBob := func(event *usagev1.Event) bool { return event.GetSubject() == "users/bob" }
+	for _, tc := range []struct {
+		name  string
+		query usage.ListQuery
+		want  []string
+	}{
+		{"live", usage.ListQuery{}, []string{"events/e9", "events/e8", "events/e7", "events/e6", "events/e5", "events/e3", "events/e2", "events/e1"}},
+		{"all", usage.ListQuery{ShowDeleted: true}, []string{"events/e9", "events/e8", "events/e7", "events/e6", "events/e5", "events/e4", "events/e3", "events/e2", "events/e1", "events/e0"}},
+		{"filtered", usage.ListQuery{Filter: ofBob}, []string{"events/e9", "events/e6", "events/e3"}},
+		{"filtered with deleted", usage.ListQuery{Filter: ofBob, ShowDeleted: true}, []string{"events/e9", "events/e6", "events/e3", "events/e0"}},
+	} {
+		for _, limit := range []int{1, 3, 100} {
+			tc.query.Limit = limit
+			if got := listAll(t, store, tc.query); !slices.Equal(got, tc.want) {
+				t.Errorf("ListEvents() of %s events in pages of %d = %v, want %v", tc.name, limit, got, tc.want)
+			}
+		}
+	}
+}
+
+func testDeleteEvent(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	event := newEvent("a", time.Now().Truncate(time.Microsecond))
+	createEvents(t, store, event)
+	if err := store.DeleteEvent(ctx, event.GetName()); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	if _, err := store.GetEvent(ctx, event.GetName()); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("GetEvent() after delete error = %v, want %v", err, usage.ErrNotFound)
+	}
+	if err := store.DeleteEvent(ctx, event.GetName()); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("DeleteEvent() twice error = %v, want %v", err, usage.ErrNotFound)
+	}
+}
+
+func testUpdateEvent(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	event := newEvent("a", time.Now().Truncate(time.Microsecond))
+	createEvents(t, store, event)
+
+	updated, err := store.UpdateEvent(ctx, event.GetName(), func(e *usagev1.Event) (*usagev1.Event, error) {
+		e.Action = "detect"
+		e.Labels["region"] = "us-east1"
+		return e, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent() error = %v", err)
+	}
+	got, err := store.GetEvent(ctx, event.GetName())
+	if err != nil {
+		t.Fatalf("GetEvent() error = %v", err)
+	}
+	if !proto.Equal(got, updated) {
+		t.Errorf("GetEvent() = %v, want %v", got, updated)
+	}
+
+	errReject := errors.New("rejected")
+	_, err = store.UpdateEvent(ctx, event.GetName(), func(e *usagev1.Event) (*usagev1.Event, error) {
+		e.Action = "rejected"
+		return nil, errReject
+	})
+	if !errors.Is(err, errReject) {
+		t.Errorf("UpdateEvent() rejected error = %v, want %v", err, errReject)
+	}
+	if got, err := store.GetEvent(ctx, event.GetName()); err != nil || !proto.Equal(got, updated) {
+		t.Errorf("GetEvent() after a rejected update = %v, %v, want %v", got, err, updated)
+	}
+
+	_, err = store.UpdateEvent(ctx, "events/missing", func(e *usagev1.Event) (*usagev1.Event, error) {
+		return e, nil
+	})
+	if !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("UpdateEvent() missing error = %v, want %v", err, usage.ErrNotFound)
+	}
+}
+
+func testPurgeEvents(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+	due := newEvent("due", now)
+	due.DeleteTime = timestamppb.New(now.Add(-time.Hour))
+	due.PurgeTime = timestamppb.New(now.Add(-time.Minute))
+	pending := newEvent("pending", now)
+	pending.DeleteTime = timestamppb.New(now)
+	pending.PurgeTime = timestamppb.New(now.Add(time.Hour))
+	live := newEvent("live", now)
+	createEvents(t, store, due, pending, live)
+
+	n, err := store.PurgeEvents(ctx, now)
+	if err != nil {
+		t.Fatalf("PurgeEvents() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("PurgeEvents() = %d, want 1", n)
+	}
+	if _, err := store.GetEvent(ctx, due.GetName()); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("GetEvent() purged error = %v, want %v", err, usage.ErrNotFound)
+	}
+	for _, event := range []*usagev1.Event{pending, live} {
+		if _, err := store.GetEvent(ctx, event.GetName()); err != nil {
+			t.Errorf("GetEvent(%q) error = %v", event.GetName(), err)
+		}
+	}
+}
+
+func testSources(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	var sources []*usagev1.Source
+	for _, id := range []string{"b", "a", "c"} {
+		source := &usagev1.Source{Name: "sources/" + id, Actions: []string{"classify"}}
+		if err := store.CreateSource(ctx, source); err != nil {
+			t.Fatalf("CreateSource() error = %v", err)
+		}
+		sources = append(sources, source)
+	}
+	if err := store.CreateSource(ctx, sources[0]); !errors.Is(err, usage.ErrAlreadyExists) {
+		t.Errorf("CreateSource() duplicate error = %v, want %v", err, usage.ErrAlreadyExists)
+	}
+
+	page, err := store.ListSources(ctx, "sources/a", 1)
+	if err != nil {
+		t.Fatalf("ListSources() error = %v", err)
+	}
+	if len(page) != 1 || page[0].GetName() != "sources/b" {
+		t.Errorf("ListSources() = %v, want [sources/b]", page)
+	}
+	all, err := store.ListSources(ctx, "", 0)
+	if err != nil {
+		t.Fatalf("ListSources() error = %v", err)
+	}
+	if len(all) != 3 || all[0].GetName() != "sources/a" || all[2].GetName() != "sources/c" {
+		t.Errorf("ListSources() without a limit = %v, want all 3 sources by name", all)
+	}
+
+	updated, err := store.UpdateSource(ctx, "sources/a", func(s *usagev1.Source) (*usagev1.Source, error) {
+		s.Actions = append(s.Actions, "detect")
+		return s, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateSource() error = %v", err)
+	}
+	got, err := store.GetSource(ctx, "sources/a")
+	if err != nil {
+		t.Fatalf("GetSource() error = %v", err)
+	}
+	if !proto.Equal(got, updated) {
+		t.Errorf("GetSource() = %v, want %v", got, updated)
+	}
+
+	if err := store.DeleteSource(ctx, "sources/a"); err != nil {
+		t.Fatalf("DeleteSource() error = %v", err)
+	}
+	if _, err := store.GetSource(ctx, "sources/a"); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("GetSource() after delete error = %v, want %v", err, usage.ErrNotFound)
+	}
+	if err := store.DeleteSource(ctx, "sources/a"); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("DeleteSource() twice error = %v, want %v", err, usage.ErrNotFound)
+	}
+}
+
+func testPrices(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	const parent = "sources/a/actions/classify"
+	cutover := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := &usagev1.Price{
+		Name:          parent + "/prices/old",
+		EffectiveTime: &interval.Interval{EndTime: timestamppb.New(cutover)},
+	}
+	current := &usagev1.Price{
+		Name:          parent + "/prices/current",
+		EffectiveTime: &interval.Interval{StartTime: timestamppb.New(cutover)},
+	}
+	other := &usagev1.Price{Name: "sources/a/actions/detect/prices/old"}
+	for _, price := range []*usagev1.Price{old, current, other} {
+		var siblings []*usagev1.Price
+		err := store.CreatePrice(ctx, price, func(s []*usagev1.Price) error {
+			siblings = s
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("CreatePrice(%s) error = %v", price.GetName(), err)
+		}
+		if price == current && (len(siblings) != 1 || siblings[0].GetName() != old.GetName()) {
+			t.Errorf("CreatePrice(%s) siblings = %v, want [%s]", price.GetName(), siblings, old.GetName())
+		}
+	}
+	if err := store.CreatePrice(ctx, old, func([]*usagev1.Price) error { return nil }); !errors.Is(err, usage.ErrAlreadyExists) {
+		t.Errorf("CreatePrice() duplicate error = %v, want %v", err, usage.ErrAlreadyExists)
+	}
+	errRejected := errors.New("rejected")
+	rejected := &usagev1.Price{Name: parent + "/prices/rejected"}
+	if err := store.CreatePrice(ctx, rejected, func([]*usagev1.Price) error { return errRejected }); !errors.Is(err, errRejected) {
+		t.Errorf("CreatePrice() rejected error = %v, want %v", err, errRejected)
+	}
+	if _, err := store.GetPrice(ctx, rejected.GetName()); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("GetPrice() of a rejected price error = %v, want %v", err, usage.ErrNotFound)
+	}
+
+	for _, tc := range []struct {
+		t    time.Time
+		want string
+	}{
+		{cutover.Add(-time.Microsecond), old.GetName()},
+		{cutover, current.GetName()},
+	} {
+		got, err := store.FindPrice(ctx, parent, tc.t)
+		if err != nil {
+			t.Fatalf("FindPrice(%v) error = %v", tc.t, err)
+		}
+		if got.GetName() != tc.want {
+			t.Errorf("FindPrice(%v) = %s, want %s", tc.t, got.GetName(), tc.want)
+		}
+	}
+
+	page, err := store.ListPrices(ctx, parent, "", 1)
+	if err != nil {
+		t.Fatalf("ListPrices() error = %v", err)
+	}
+	if len(page) != 1 || page[0].GetName() != current.GetName() {
+		t.Errorf("ListPrices() = %v, want [%s]", page, current.GetName())
+	}
+
+	end := cutover.AddDate(1, 0, 0)
+	_, err = store.UpdatePrice(ctx, current.GetName(), func(p *usagev1.Price, siblings []*usagev1.Price) (*usagev1.Price, error) {
+		p.EffectiveTime.EndTime = timestamppb.New(end)
+		return p, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdatePrice() error = %v", err)
+	}
+	if _, err := store.FindPrice(ctx, parent, end); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("FindPrice() after end error = %v, want %v", err, usage.ErrNotFound)
+	}
+
+	if err := store.DeletePrice(ctx, old.GetName()); err != nil {
+		t.Fatalf("DeletePrice() error = %v", err)
+	}
+	if err := store.DeletePrice(ctx, old.GetName()); !errors.Is(err, usage.ErrNotFound) {
+		t.Errorf("DeletePrice() twice error = %v, want %v", err, usage.ErrNotFound)
+	}
+}
+
+func testRollups(t *testing.T, store usage.Store) {
+	ctx := context.Background()
+	hour := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	key := usage.RollupKey{Granularity: usage.RollupHour, Start: hour, Subject: "users/a", Source: "s", Action: "classify"}
+	rollup := func(key usage.RollupKey, count int64) *usage.Rollup {
+		r := &usage.Rollup{
+			RollupKey:     key,
+			EventCount:    count,
+			TotalDuration: time.Duration(count) * time.Second,
+			Costs:         map[string]int64{"USD": count * 1000},
+			Durations:     usage.DurationSketch{},
+		}
+		r.Durations.Add(time.Second, count)
+		return r
+	}
+
+	later := key
+	later.Start = hour.Add(time.Hour)
+	if err := store.AddRollups(ctx, []*usage.Rollup{rollup(later, 1), rollup(key, 2)}); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+	if err := store.AddRollups(ctx, []*usage.Rollup{rollup(key, 1)}); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+	got, err := store.ListRollups(ctx, usage.RollupQuery{Granularity: usage.RollupHour})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 2 || got[0].RollupKey != key || !got[0].Equal(rollup(key, 3)) || got[1].RollupKey != later {
+		t.Errorf("ListRollups() = %+v, want the rollups of %v with 3 events and of %v", got, key, later)
+	}
+	other := key
+	other.Subject = "users/b"
+	if err := store.AddRollups(ctx, []*usage.Rollup{rollup(other, 1)}); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+	got, err = store.ListRollups(ctx, usage.RollupQuery{Subject: other.Subject})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 1 || got[0].RollupKey != other {
+		t.Errorf("ListRollups() of %s = %+v, want the rollup of %v", other.Subject, got, other)
+	}
+	if err := store.AddRollups(ctx, []*usage.Rollup{rollup(other, -1)}); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+
+	// Subtracting all events removes the rollup
+	if err := store.AddRollups(ctx, []*usage.Rollup{rollup(key, -3)}); err != nil {
+		t.Fatalf("AddRollups() error = %v", err)
+	}
+	got, err = store.ListRollups(ctx, usage.RollupQuery{Start: hour, End: later.Start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 1 || got[0].RollupKey != later {
+		t.Errorf("ListRollups() = %+v, want the rollup of %v", got, later)
+	}
+	got, err = store.ListRollups(ctx, usage.RollupQuery{Start: later.Start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ListRollups() after the last rollup = %+v, want none", got)
+	}
+	got, err = store.ListRollups(ctx, usage.RollupQuery{Granularity: usage.RollupDay})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ListRollups() day rollups = %+v, want none", got)
+	}
+
+	day := usage.RollupKey{Granularity: usage.RollupDay, Start: hour.Truncate(24 * time.Hour), Subject: "users/a", Source: "s", Action: "classify"}
+	if err := store.ReplaceRollups(ctx, []*usage.Rollup{rollup(day, 5)}); err != nil {
+		t.Fatalf("ReplaceRollups() error = %v", err)
+	}
+	got, err = store.ListRollups(ctx, usage.RollupQuery{})
+	if err != nil {
+		t.Fatalf("ListRollups() error = %v", err)
+	}
+	if len(got) != 1 || got[0].RollupKey != day || !got[0].Equal(rollup(day, 5)) {
+		t.Errorf("ListRollups() after ReplaceRollups = %+v, want the rollup of %v with 5 events", got, day)
+	}
+}
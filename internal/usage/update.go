@@ -0,0 +1,271 @@
+package usage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+	"github.com/jan-sykora/api-demo/internal/fieldbehavior"
+)
+
+// UpdateEvent updates fields of a usage event.
+func (s *Service) UpdateEvent(ctx context.Context, req *usagev1.UpdateEventRequest) (*usagev1.UpdateEventResponse, error) {
+	name := req.GetEvent().GetName()
+	if err := validateEventName("event.name", name); err != nil {
+		return nil, err
+	}
+
+	paths, err := updatePaths(req.GetEvent(), mutableEventFields, req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, invalidArgument("update_mask", ReasonInvalidUpdateMask, fmt.Errorf("invalid update_mask: %w", err))
+	}
+	if violations := validateUpdateEventRequest(req); len(violations) > 0 {
+		return nil, apierror.BadRequest(violations...)
+	}
+
+	// The store cannot be read while the event is locked for the update, so
+	// look up the source and the rate card of the updated event beforehand.
+	// If its source or action turn out to have changed in the meantime, the
+	// update is aborted.
+	checkSources := s.checkSources && updatesAny(paths, "source", "action")
+	reprice := updatesAny(paths, "source", "action", "execution_duration")
+	var (
+		expected *usagev1.Event
+		sources  map[string]*usagev1.Source
+		card     *RateCard
+	)
+	if checkSources || reprice {
+		expected, err = s.store.GetEvent(ctx, name)
+		if err != nil {
+			return nil, updateError(err, name)
+		}
+		applyUpdate(expected, req.GetEvent(), paths)
+	}
+	if checkSources {
+		sources = make(map[string]*usagev1.Source)
+		if _, err := s.sourceViolations(ctx, "", expected, sources); err != nil {
+			return nil, err
+		}
+	}
+	if reprice {
+		card, err = s.rateCard(ctx, expected.GetSource(), expected.GetAction(), expected.GetCreateTime().AsTime())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	var old *usagev1.Event
+	event, err := s.store.UpdateEvent(ctx, name, func(event *usagev1.Event) (*usagev1.Event, error) {
+		if err := checkEtag(event, req.GetEvent().GetEtag()); err != nil {
+			return nil, err
+		}
+		if event.GetDeleteTime() != nil {
+			return nil, apierror.New(codes.FailedPrecondition, ReasonEventDeleted,
+				fmt.Sprintf("event %q is deleted; undelete it first", name), map[string]string{"name": name})
+		}
+
+		old = proto.Clone(event).(*usagev1.Event)
+		applyUpdate(event, req.GetEvent(), paths)
+		if expected != nil && (event.GetSource() != expected.GetSource() || event.GetAction() != expected.GetAction()) {
+			return nil, apierror.New(codes.Aborted, ReasonConcurrentModification,
+				fmt.Sprintf("event %q was modified concurrently; retry the update", name), map[string]string{"name": name})
+		}
+		violations := fieldbehavior.CheckRequired(event)
+		if updatesAny(paths, "source", "action", "execution_duration") {
+			violations = append(violations, s.durationLimits.validate("execution_duration", event)...)
+		}
+		if checkSources {
+			registryViolations, err := s.sourceViolations(ctx, "", event, sources)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, registryViolations...)
+		}
+		if len(violations) > 0 {
+			return nil, apierror.Prefix(apierror.BadRequest(violations...), "event")
+		}
+		if reprice {
+			if err := setCost(event, card); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to price event: %v", err)
+			}
+		}
+
+		event.UpdateTime = timestamppb.New(now)
+		event.Etag = resourceEtag(event)
+		return event, nil
+	})
+	if err != nil {
+		return nil, updateError(err, name)
+	}
+	s.updateRollups(ctx, append(eventRollups(old, -1), eventRollups(event, 1)...))
+
+	return &usagev1.UpdateEventResponse{Event: event}, nil
+}
+
+// mutableEventFields are the fields of Event that clients can update.
+var mutableEventFields = mutableFields(&usagev1.Event{})
+
+// mutableFields returns the fields of a resource that clients can update:
+// all but those annotated as IDENTIFIER, OUTPUT_ONLY or IMMUTABLE, and the
+// etag.
+func mutableFields(resource proto.Message) map[protoreflect.Name]protoreflect.FieldDescriptor {
+	fields := resource.ProtoReflect().Descriptor().Fields()
+	mutable := make(map[protoreflect.Name]protoreflect.FieldDescriptor)
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		behaviors := fieldbehavior.Behaviors(fd)
+		if slices.Contains(behaviors, annotations.FieldBehavior_IDENTIFIER) ||
+			slices.Contains(behaviors, annotations.FieldBehavior_OUTPUT_ONLY) ||
+			slices.Contains(behaviors, annotations.FieldBehavior_IMMUTABLE) ||
+			fd.Name() == "etag" {
+			continue
+		}
+		mutable[fd.Name()] = fd
+	}
+	return mutable
+}
+
+// fieldPath is a path of an update mask, resolved to the fields it
+// traverses, e.g. [effective_time, end_time] for "effective_time.end_time".
+type fieldPath []protoreflect.FieldDescriptor
+
+// updatePaths resolves an update mask to the fields of resource to update,
+// following AIP-134: an empty mask selects the populated fields of resource,
+// and "*" selects all mutable fields. Immutable fields are ignored. Paths
+// may reach into fields of mutable message fields, except those of
+// well-known types, which are updated as a whole.
+func updatePaths(resource proto.Message, mutable map[protoreflect.Name]protoreflect.FieldDescriptor, mask []string) ([]fieldPath, error) {
+	var paths []fieldPath
+	switch {
+	case len(mask) == 0:
+		resource.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if _, ok := mutable[fd.Name()]; ok {
+				paths = append(paths, fieldPath{fd})
+			}
+			return true
+		})
+	case slices.Contains(mask, "*"):
+		if len(mask) > 1 {
+			return nil, fmt.Errorf("%q cannot be combined with other paths", "*")
+		}
+		for _, fd := range mutable {
+			paths = append(paths, fieldPath{fd})
+		}
+	default:
+		for _, path := range mask {
+			var fp fieldPath
+			md := resource.ProtoReflect().Descriptor()
+			for _, name := range strings.Split(path, ".") {
+				if md == nil || md.FullName().Parent() == "google.protobuf" {
+					return nil, fmt.Errorf("unknown field %q", path)
+				}
+				fd := md.Fields().ByName(protoreflect.Name(name))
+				if fd == nil {
+					return nil, fmt.Errorf("unknown field %q", path)
+				}
+				fp = append(fp, fd)
+				md = nil
+				if fd.Kind() == protoreflect.MessageKind && fd.Cardinality() != protoreflect.Repeated {
+					md = fd.Message()
+				}
+			}
+			if _, ok := mutable[fp[0].Name()]; ok {
+				paths = append(paths, fp)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// applyUpdate copies the fields at paths from src to dst, clearing those
+// that are not set in src.
+func applyUpdate(dst, src proto.Message, paths []fieldPath) {
+	for _, path := range paths {
+		d, s := dst.ProtoReflect(), src.ProtoReflect()
+		for _, fd := range path[:len(path)-1] {
+			d, s = d.Mutable(fd).Message(), s.Get(fd).Message()
+		}
+		if fd := path[len(path)-1]; s.Has(fd) {
+			d.Set(fd, s.Get(fd))
+		} else {
+			d.Clear(fd)
+		}
+	}
+}
+
+// updatesAny reports whether any of the named fields, or any of their
+// fields, is updated. Stored events are only checked against rules that may
+// have changed since they were recorded, like duration limits or the source
+// registry, when an update touches the fields the rules apply to.
+func updatesAny(paths []fieldPath, names ...protoreflect.Name) bool {
+	return slices.ContainsFunc(paths, func(path fieldPath) bool {
+		return slices.Contains(names, path[0].Name())
+	})
+}
+
+// validateUpdateEventRequest returns the violations of the new values of
+// the fields updated by an UpdateEvent request. Field behaviors are enforced
+// separately, see package fieldbehavior.
+func validateUpdateEventRequest(req *usagev1.UpdateEventRequest) []apierror.FieldViolation {
+	paths, err := updatePaths(req.GetEvent(), mutableEventFields, req.GetUpdateMask().GetPaths())
+	if err != nil {
+		// Reported by UpdateEvent
+		return nil
+	}
+
+	var violations []apierror.FieldViolation
+	for _, path := range paths {
+		switch path[0].Name() {
+		case "subject":
+			if subject := req.GetEvent().GetSubject(); subject != "" {
+				if err := ValidateSubject(subject); err != nil {
+					violations = append(violations, invalidField("event.subject", ReasonInvalidSubject, err))
+				}
+			}
+		case "labels":
+			violations = append(violations, validateLabels("event.labels", req.GetEvent().GetLabels())...)
+		}
+	}
+	return violations
+}
+
+// resource is implemented by the API resources that carry an etag.
+type resource interface {
+	proto.Message
+	GetName() string
+	GetEtag() string
+}
+
+// resourceEtag computes the etag of a resource from its content.
+func resourceEtag(r resource) string {
+	m := proto.Clone(r).ProtoReflect()
+	m.Clear(m.Descriptor().Fields().ByName("etag"))
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m.Interface())
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// checkEtag returns an ABORTED error if etag is set and does not match the
+// current state of r.
+func checkEtag(r resource, etag string) error {
+	if etag != "" && etag != resourceEtag(r) {
+		return apierror.New(codes.Aborted, ReasonEtagMismatch,
+			fmt.Sprintf("etag %q does not match the current etag of %q", etag, r.GetName()),
+			map[string]string{"name": r.GetName()})
+	}
+	return nil
+}
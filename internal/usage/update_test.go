@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/interval"
+	"google.golang.org/genproto/googleapis/type/money"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+func TestUpdatePaths(t *testing.T) {
+	// Only the effective time of prices is mutable: their name is an
+	// IDENTIFIER, their amounts IMMUTABLE and their times OUTPUT_ONLY
+	price := &usagev1.Price{
+		Name:          "sources/s/actions/a/prices/p",
+		EffectiveTime: &interval.Interval{EndTime: timestamppb.Now()},
+		PerCall:       &money.Money{CurrencyCode: "USD", Units: 1},
+		CreateTime:    timestamppb.Now(),
+		Etag:          `"etag"`,
+	}
+	for _, tc := range []struct {
+		name string
+		mask []string
+		want []string
+	}{
+		{"empty mask", nil, []string{"effective_time"}},
+		{"all fields", []string{"*"}, []string{"effective_time"}},
+		{"mutable field", []string{"effective_time"}, []string{"effective_time"}},
+		{"nested field", []string{"effective_time.end_time"}, []string{"effective_time.end_time"}},
+		{"immutable field", []string{"per_call"}, nil},
+		{"identifier", []string{"name"}, nil},
+		{"output only field", []string{"create_time", "update_time"}, nil},
+		{"etag", []string{"etag"}, nil},
+		{"mutable and immutable fields", []string{"per_second", "effective_time.start_time", "minimum"}, []string{"effective_time.start_time"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, err := updatePaths(price, mutablePriceFields, tc.mask)
+			if err != nil {
+				t.Fatalf("updatePaths(%q) error = %v", tc.mask, err)
+			}
+			var got []string
+			for _, path := range paths {
+				var names []string
+				for _, fd := range path {
+					names = append(names, string(fd.Name()))
+				}
+				got = append(got, strings.Join(names, "."))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("updatePaths(%q) = %q, want %q", tc.mask, got, tc.want)
+			}
+		})
+	}
+
+	for _, mask := range [][]string{
+		{"cost"},
+		{"per_call.units.nanos"},
+		{"effective_time.end_time.seconds"},
+		{"*", "effective_time"},
+	} {
+		if paths, err := updatePaths(price, mutablePriceFields, mask); err == nil {
+			t.Errorf("updatePaths(%q) = %v, want an error", mask, paths)
+		}
+	}
+}
+
+func TestUpdatePriceIgnoresImmutableFields(t *testing.T) {
+	ctx := context.Background()
+	s := newTestPriceCatalog(t, NewMemoryStore())
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	price := createTestPrice(t, s, "p", testPrice(start, time.Time{}))
+
+	end := timestamppb.New(start.AddDate(0, 1, 0))
+	resp, err := s.UpdatePrice(ctx, &usagev1.UpdatePriceRequest{
+		Price: &usagev1.Price{
+			Name:          price.GetName(),
+			EffectiveTime: &interval.Interval{EndTime: end},
+			PerCall:       &money.Money{CurrencyCode: "USD", Units: 100},
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"effective_time.end_time", "per_call"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePrice() error = %v", err)
+	}
+	if got := resp.GetPrice(); !proto.Equal(got.GetPerCall(), price.GetPerCall()) || !proto.Equal(got.GetEffectiveTime().GetEndTime(), end) {
+		t.Errorf("UpdatePrice() = %v, want the end time updated and per_call unchanged", got)
+	}
+}
@@ -0,0 +1,278 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+	"github.com/jan-sykora/api-demo/internal/apierror"
+)
+
+// DefaultWatchBufferSize is the number of events buffered per watcher when
+// Config.WatchBufferSize is not set. It leaves room for a full batch from
+// BatchCreateEvents, which is published at once.
+const DefaultWatchBufferSize = 2 * maxBatchSize
+
+// replayPageSize is the number of events read from the store at a time when
+// replaying events to a resumed watcher.
+const replayPageSize = 100
+
+// watchOrdering is the order in which events are replayed. Resume tokens
+// are cursors in this order.
+var watchOrdering = Ordering{{Field: FieldCreateTime}, {Field: FieldName}}
+
+// createTimeResolution is the resolution of the create times handed out by
+// watchHub.reserve, that of PostgreSQL timestamps.
+const createTimeResolution = time.Microsecond
+
+// watchHub fans out created events to the active watchers.
+//
+// Resume tokens are cursors in create time order, so watchers must receive
+// events in that order, and an event must not be received before all
+// events with earlier create times are stored: a watcher resuming after it
+// would miss them otherwise. The hub therefore hands out the create times
+// of new events itself, see reserve, and publishes events in that order.
+type watchHub struct {
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+	pending  []*reservation // in create time order
+	last     time.Time      // the latest create time handed out
+}
+
+// reservation holds the create times of events being created. Its events
+// are published once they and all the events of earlier reservations are
+// stored.
+type reservation struct {
+	hub    *watchHub
+	start  time.Time
+	done   bool
+	events []*usagev1.Event // the created events, set when done
+}
+
+// watcher is a subscription to created events.
+type watcher struct {
+	filter Predicate
+	events chan *usagev1.Event
+	// evicted is closed when the watcher is dropped because its buffer was
+	// full.
+	evicted chan struct{}
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{watchers: make(map[*watcher]struct{})}
+}
+
+// subscribe registers a watcher for events matching filter, buffering up to
+// size events. The watcher receives the events created at or after the
+// returned watermark, see watermark.
+func (h *watchHub) subscribe(filter Predicate, size int) (w *watcher, watermark time.Time) {
+	w = &watcher{
+		filter:  filter,
+		events:  make(chan *usagev1.Event, size),
+		evicted: make(chan struct{}),
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.watchers[w] = struct{}{}
+	return w, h.watermarkLocked()
+}
+
+// watermark returns the create time before which all events are stored and
+// published.
+func (h *watchHub) watermark() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.watermarkLocked()
+}
+
+// watermarkLocked is watermark for callers holding h.mu.
+func (h *watchHub) watermarkLocked() time.Time {
+	if len(h.pending) > 0 {
+		return h.pending[0].start
+	}
+	return h.nextCreateTime()
+}
+
+// nextCreateTime returns the earliest create time that can be handed out:
+// the current time, unless it is not after the last handed out one. The
+// caller must hold h.mu.
+func (h *watchHub) nextCreateTime() time.Time {
+	t := time.Now().Truncate(createTimeResolution)
+	if !t.After(h.last) {
+		t = h.last.Add(createTimeResolution)
+	}
+	return t
+}
+
+func (h *watchHub) unsubscribe(w *watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.watchers, w)
+}
+
+// reserve returns a reservation of increasing create times for n new
+// events, later than those of all previous reservations. Each reservation
+// must be published, even if no event was created.
+func (h *watchHub) reserve(n int) *reservation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := h.nextCreateTime()
+	h.last = start.Add(time.Duration(max(n, 1)-1) * createTimeResolution)
+	r := &reservation{hub: h, start: start}
+	h.pending = append(h.pending, r)
+	return r
+}
+
+// createTime returns the create time reserved for the i-th event.
+func (r *reservation) createTime(i int) time.Time {
+	return r.start.Add(time.Duration(i) * createTimeResolution)
+}
+
+// publish records the stored events of the reservation, in create time
+// order, and delivers them to the watchers as soon as all earlier
+// reservations are published.
+func (r *reservation) publish(events ...*usagev1.Event) {
+	h := r.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r.done = true
+	r.events = events
+	n := 0
+	for _, p := range h.pending {
+		if !p.done {
+			break
+		}
+		h.deliver(p.events...)
+		n++
+	}
+	clear(h.pending[:n])
+	h.pending = h.pending[n:]
+}
+
+// deliver delivers events to the watchers they match. It never blocks: a
+// watcher whose buffer is full is evicted, so that a slow consumer cannot
+// hold up event creation or other watchers. The caller must hold h.mu.
+func (h *watchHub) deliver(events ...*usagev1.Event) {
+	for w := range h.watchers {
+		for _, event := range events {
+			if w.filter != nil && !w.filter(event) {
+				continue
+			}
+			select {
+			case w.events <- event:
+				continue
+			default:
+			}
+			delete(h.watchers, w)
+			close(w.evicted)
+			break
+		}
+	}
+}
+
+// WatchEvents streams created events to the client.
+func (s *Service) WatchEvents(req *usagev1.WatchEventsRequest, stream grpc.ServerStreamingServer[usagev1.WatchEventsResponse]) error {
+	ctx := stream.Context()
+
+	predicate, err := compileFilter(req.GetFilter())
+	if err != nil {
+		return invalidArgument("filter", ReasonInvalidFilter, fmt.Errorf("invalid filter: %w", err))
+	}
+
+	var after *Cursor
+	if req.GetResumeToken() != "" {
+		cursor, err := s.pageTokens.decode(req.GetResumeToken(), req.GetFilter(), false, watchOrdering)
+		if errors.Is(err, errPageTokenMismatch) {
+			return invalidArgument("resume_token", ReasonInvalidResumeToken, errors.New("resume_token was issued for a different filter"))
+		}
+		if err != nil {
+			return invalidArgument("resume_token", ReasonInvalidResumeToken, errors.New("invalid resume_token"))
+		}
+		after = &cursor
+	}
+
+	// Let clients know that the request was accepted before the first event
+	// arrives, which may take a while.
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	send := func(event *usagev1.Event) error {
+		token, err := s.pageTokens.encode(req.GetFilter(), false, watchOrdering, CursorOf(event))
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to create resume token: %v", err)
+		}
+		return stream.Send(&usagev1.WatchEventsResponse{Event: event, ResumeToken: token})
+	}
+
+	// Catch up before subscribing, so that a long replay does not overflow
+	// the watcher's buffer.
+	if after != nil {
+		if after, err = s.replay(ctx, predicate, after, s.watchers.watermark(), send); err != nil {
+			return err
+		}
+	}
+
+	w, watermark := s.watchers.subscribe(predicate, s.watchBufferSize)
+	defer s.watchers.unsubscribe(w)
+
+	// The watcher receives the events from the watermark on; replay those
+	// created before it while catching up.
+	if after != nil {
+		if _, err := s.replay(ctx, predicate, after, watermark, send); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-w.evicted:
+			return apierror.New(codes.ResourceExhausted, ReasonWatcherTooSlow, "watcher fell behind; resume from the last resume_token", nil)
+		case event := <-w.events:
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replay sends the live events matching filter that follow after in
+// watchOrdering and were created before until. It returns the cursor of the
+// last event sent, or after if there were none.
+func (s *Service) replay(ctx context.Context, filter Predicate, after *Cursor, until time.Time, send func(*usagev1.Event) error) (*Cursor, error) {
+	for {
+		events, err := s.store.ListEvents(ctx, ListQuery{
+			OrderBy: watchOrdering,
+			After:   after,
+			Limit:   replayPageSize,
+			Filter:  filter,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to replay events: %v", err)
+		}
+		for _, event := range events {
+			if !event.GetCreateTime().AsTime().Before(until) {
+				return after, nil
+			}
+			if err := send(event); err != nil {
+				return nil, err
+			}
+			last := CursorOf(event)
+			after = &last
+		}
+		if len(events) < replayPageSize {
+			return after, nil
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	usagev1 "github.com/jan-sykora/api-demo/gen/go/ai/h2o/usage/v1"
+)
+
+// testWatchStream is the server side of a WatchEvents stream whose
+// responses are received from a channel.
+type testWatchStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *usagev1.WatchEventsResponse
+}
+
+func (s *testWatchStream) Context() context.Context { return s.ctx }
+
+func (s *testWatchStream) SendHeader(metadata.MD) error { return nil }
+
+func (s *testWatchStream) Send(resp *usagev1.WatchEventsResponse) error {
+	select {
+	case s.responses <- resp:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// testWatch is a WatchEvents call running in the background.
+type testWatch struct {
+	responses chan *usagev1.WatchEventsResponse
+	cancel    context.CancelFunc
+	done      chan error
+}
+
+// startWatch calls WatchEvents in the background; the call is canceled at
+// the end of the test. Without a resume token, it waits until the watcher
+// is subscribed, so that it receives the events created from then on.
+// Resumed watchers receive them anyway.
+func startWatch(t *testing.T, s *Service, req *usagev1.WatchEventsRequest) *testWatch {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &testWatch{
+		responses: make(chan *usagev1.WatchEventsResponse),
+		cancel:    cancel,
+		done:      make(chan error, 1),
+	}
+	n := watcherCount(s)
+	go func() {
+		w.done <- s.WatchEvents(req, &testWatchStream{ctx: ctx, responses: w.responses})
+	}()
+	t.Cleanup(w.stop)
+	if req.GetResumeToken() != "" {
+		return w
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); watcherCount(s) == n; time.Sleep(time.Millisecond) {
+		select {
+		case err := <-w.done:
+			w.done <- err
+			return w
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("WatchEvents() did not subscribe")
+		}
+	}
+	return w
+}
+
+func watcherCount(s *Service) int {
+	s.watchers.mu.Lock()
+	defer s.watchers.mu.Unlock()
+	return len(s.watchers.watchers)
+}
+
+// stop cancels the call and waits for it to return.
+func (w *testWatch) stop() {
+	w.cancel()
+	<-w.done
+	w.done <- context.Canceled
+}
+
+// next returns the next response of the stream.
+func (w *testWatch) next(t *testing.T) *usagev1.WatchEventsResponse {
+	t.Helper()
+	select {
+	case resp := <-w.responses:
+		return resp
+	case err := <-w.done:
+		w.done <- err
+		t.Fatalf("WatchEvents() error = %v, want another event", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchEvents() sent no event")
+	}
+	return nil
+}
+
+// err returns the error that the call ended with.
+func (w *testWatch) err(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-w.done:
+		w.done <- err
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchEvents() did not return")
+		return nil
+	}
+}
+
+// receiveNames returns the names of the events of the next n responses.
+func (w *testWatch) receiveNames(t *testing.T, n int) []string {
+	t.Helper()
+	var names []string
+	for range n {
+		names = append(names, w.next(t).GetEvent().GetName())
+	}
+	return names
+}
+
+func TestWatchEventsFilter(t *testing.T) {
+	s := newTestService(t, Config{})
+	w := startWatch(t, s, &usagev1.WatchEventsRequest{Filter: `action = "detect"`})
+
+	createTestEvent(t, s, testEvent("users/alice", "classify", time.Second))
+	detect := createTestEvent(t, s, testEvent("users/alice", "detect", time.Second))
+	createTestEvent(t, s, testEvent("users/bob", "classify", time.Second))
+	detectAgain := createTestEvent(t, s, testEvent("users/bob", "detect", time.Second))
+
+	got := w.receiveNames(t, 2)
+	if want := []string{detect.GetName(), detectAgain.GetName()}; !slices.Equal(got, want) {
+		t.Errorf("WatchEvents() events = %v, want %v", got, want)
+	}
+
+	w = startWatch(t, s, &usagev1.WatchEventsRequest{Filter: "action ="})
+	if code, reason, _ := errorReason(w.err(t)); code != codes.InvalidArgument || reason != ReasonInvalidFilter {
+		t.Errorf("WatchEvents() with an invalid filter error = %v %s, want %v %s", code, reason, codes.InvalidArgument, ReasonInvalidFilter)
+	}
+}
+
+func TestWatchEventsResume(t *testing.T) {
+	s := newTestService(t, Config{})
+	filter := `subject = "users/alice"`
+	w := startWatch(t, s, &usagev1.WatchEventsRequest{Filter: filter})
+
+	var want []string
+	for i := range 3 {
+		want = append(want, createTestEvent(t, s, testEvent("users/alice", "classify", time.Duration(i+1)*time.Second)).GetName())
+	}
+	first := w.next(t)
+	w.stop()
+
+	// Events created while disconnected are replayed, then new ones
+	// streamed, skipping those of other subjects
+	createTestEvent(t, s, testEvent("users/bob", "classify", time.Second))
+	for range 2 {
+		want = append(want, createTestEvent(t, s, testEvent("users/alice", "classify", time.Second)).GetName())
+	}
+	w = startWatch(t, s, &usagev1.WatchEventsRequest{Filter: filter, ResumeToken: first.GetResumeToken()})
+	want = append(want, createTestEvent(t, s, testEvent("users/alice", "detect", time.Second)).GetName())
+	if got := w.receiveNames(t, len(want)-1); !slices.Equal(got, want[1:]) {
+		t.Errorf("WatchEvents() resumed events = %v, want %v", got, want[1:])
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  *usagev1.WatchEventsRequest
+	}{
+		{"different filter", &usagev1.WatchEventsRequest{ResumeToken: first.GetResumeToken()}},
+		{"invalid token", &usagev1.WatchEventsRequest{Filter: filter, ResumeToken: "invalid"}},
+	} {
+		w := startWatch(t, s, tc.req)
+		if code, reason, _ := errorReason(w.err(t)); code != codes.InvalidArgument || reason != ReasonInvalidResumeToken {
+			t.Errorf("WatchEvents() with %s error = %v %s, want %v %s", tc.name, code, reason, codes.InvalidArgument, ReasonInvalidResumeToken)
+		}
+	}
+}
+
+func TestWatchEventsEvictsSlowWatchers(t *testing.T) {
+	s := newTestService(t, Config{WatchBufferSize: 2})
+	slow := startWatch(t, s, &usagev1.WatchEventsRequest{})
+	fast := startWatch(t, s, &usagev1.WatchEventsRequest{})
+
+	// The slow watcher takes the first event, then none while the others
+	// are created, so its buffer overflows; the fast one keeps up
+	var (
+		names []string
+		last  *usagev1.WatchEventsResponse
+	)
+	for i := range 5 {
+		names = append(names, createTestEvent(t, s, testEvent("users/alice", "classify", time.Second)).GetName())
+		if got := fast.next(t).GetEvent().GetName(); got != names[i] {
+			t.Errorf("fast watcher received %s, want %s", got, names[i])
+		}
+		if i == 0 {
+			last = slow.next(t)
+		}
+	}
+
+	// The events sent before the eviction are still delivered, with a
+	// resume token to pick up from
+	for err := error(nil); err == nil; {
+		select {
+		case last = <-slow.responses:
+		case err = <-slow.done:
+			slow.done <- err
+			if code, reason, _ := errorReason(err); code != codes.ResourceExhausted || reason != ReasonWatcherTooSlow {
+				t.Fatalf("WatchEvents() of a slow watcher error = %v, want %v %s", err, codes.ResourceExhausted, ReasonWatcherTooSlow)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("slow watcher was not evicted")
+		}
+	}
+	i := slices.Index(names, last.GetEvent().GetName())
+	resumed := startWatch(t, s, &usagev1.WatchEventsRequest{ResumeToken: last.GetResumeToken()})
+	if got := resumed.receiveNames(t, len(names)-i-1); !slices.Equal(got, names[i+1:]) {
+		t.Errorf("WatchEvents() resumed after eviction = %v, want %v", got, names[i+1:])
+	}
+}
+
+// slowEventStore takes a random while to store events, so that concurrent
+// requests store them in a different order than they are created in.
+type slowEventStore struct {
+	Store
+}
+
+func (s slowEventStore) CreateEvent(ctx context.Context, event *usagev1.Event) error {
+	time.Sleep(rand.N(time.Millisecond))
+	return s.Store.CreateEvent(ctx, event)
+}
+
+func (s slowEventStore) BatchCreateEvents(ctx context.Context, events []*usagev1.Event) error {
+	time.Sleep(rand.N(time.Millisecond))
+	return s.Store.BatchCreateEvents(ctx, events)
+}
+
+func TestWatchEventsOrderUnderConcurrentCreates(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServiceWithStore(t, slowEventStore{NewMemoryStore()}, Config{})
+	w := startWatch(t, s, &usagev1.WatchEventsRequest{})
+
+	// Writers create events one by one and in batches while the watcher
+	// reconnects several times
+	const writers, rounds, batchSize = 8, 20, 5
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subject := fmt.Sprintf("users/writer-%d", i)
+			for round := range rounds {
+				if round%2 == 0 {
+					_, err := s.CreateEvent(ctx, &usagev1.CreateEventRequest{Event: testEvent(subject, "classify", time.Second)})
+					if err != nil {
+						errs <- fmt.Errorf("CreateEvent() error = %w", err)
+						return
+					}
+					continue
+				}
+				var reqs []*usagev1.CreateEventRequest
+				for range batchSize {
+					reqs = append(reqs, &usagev1.CreateEventRequest{Event: testEvent(subject, "detect", time.Second)})
+				}
+				if _, err := s.BatchCreateEvents(ctx, &usagev1.BatchCreateEventsRequest{Requests: reqs}); err != nil {
+					errs <- fmt.Errorf("BatchCreateEvents() error = %w", err)
+					return
+				}
+			}
+		}()
+	}
+
+	total := writers * rounds / 2 * (1 + batchSize)
+	var received []*usagev1.Event
+	for len(received) < total {
+		resp := w.next(t)
+		received = append(received, resp.GetEvent())
+		if len(received)%50 == 0 {
+			w.stop()
+			w = startWatch(t, s, &usagev1.WatchEventsRequest{ResumeToken: resp.GetResumeToken()})
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < len(received); i++ {
+		prev, event := received[i-1].GetCreateTime().AsTime(), received[i].GetCreateTime().AsTime()
+		if !prev.Before(event) {
+			t.Fatalf("WatchEvents() sent %s created at %v after %s created at %v",
+				received[i].GetName(), event, received[i-1].GetName(), prev)
+		}
+	}
+	stored, err := s.store.ListEvents(ctx, ListQuery{OrderBy: watchOrdering, Limit: total + 1})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	var got, want []string
+	for _, event := range received {
+		got = append(got, event.GetName())
+	}
+	for _, event := range stored {
+		want = append(want, event.GetName())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("WatchEvents() received %d events, want all %d stored events in create_time order", len(got), len(want))
+	}
+}
@@ -2,6 +2,8 @@
 // @generated from file ai/h2o/usage/v1/event.proto (package ai.h2o.usage.v1, syntax proto3)
 /* eslint-disable */
 
+import type { Money } from "../../../../google/type/money_pb";
+
 /**
  * A usage event recording an operation.
  *
@@ -17,19 +19,23 @@ export type Event = {
 name?: string;
 /**
  * The subject who performed the action.
- * Format: `users/{user}`
+ * Format: `users/{user}` or `serviceAccounts/{service_account}`, where the
+ * ID is 1-63 characters of lowercase letters, digits and hyphens, starts
+ * with a letter and does not end with a hyphen.
  *
  * @generated from field: string subject = 2;
  */
 subject: string;
 /**
- * The source where the action originated (e.g., "animal-classifier").
+ * The source where the action originated (e.g., "animal-classifier"): the
+ * ID of a registered `Source`.
  *
  * @generated from field: string source = 3;
  */
 source: string;
 /**
- * The action that was performed (e.g., "classify").
+ * The action that was performed (e.g., "classify"): one of the `actions`
+ * of the source.
  *
  * @generated from field: string action = 4;
  */
@@ -40,11 +46,66 @@ action: string;
  * @generated from field: google.protobuf.Duration execution_duration = 5;
  */
 executionDuration: string;
+/**
+ * Key/value metadata of the operation, e.g. the model version or the
+ * region it ran in. Keys are 1-63 characters of lowercase letters, digits,
+ * underscores and hyphens, and start with a letter. Values are up to 63
+ * characters of letters of either case, digits, underscores, hyphens and
+ * dots, e.g. "v1.2". An event has at most 64 labels.
+ *
+ * @generated from field: map<string, string> labels = 11;
+ */
+labels?: { [key: string]: string };
 /**
  * The time when the event was recorded.
  *
  * @generated from field: google.protobuf.Timestamp create_time = 6;
  */
 createTime?: string;
+/**
+ * The time when the event was deleted. Only set for soft-deleted events.
+ *
+ * @generated from field: google.protobuf.Timestamp delete_time = 7;
+ */
+deleteTime?: string;
+/**
+ * The time after which a soft-deleted event is permanently purged.
+ *
+ * @generated from field: google.protobuf.Timestamp purge_time = 8;
+ */
+purgeTime?: string;
+/**
+ * The time when the event was last updated.
+ *
+ * @generated from field: google.protobuf.Timestamp update_time = 9;
+ */
+updateTime?: string;
+/**
+ * A checksum of the event's current state, following AIP-154. Send it
+ * back with an update or delete to make the request fail with `ABORTED`
+ * if the event was modified in the meantime.
+ *
+ * @generated from field: string etag = 10;
+ */
+etag?: string;
+/**
+ * The cost of the operation, computed when the event is recorded from the
+ * catalog price of its action in effect at `create_time`, or else from the
+ * rate card the server is configured with. Unset if neither applies.
+ *
+ * Price changes only affect events recorded afterwards. An update of
+ * `source`, `action` or `execution_duration` reprices the event with the
+ * price in effect at `create_time`, which `price_version` then reflects.
+ *
+ * @generated from field: google.type.Money cost = 12;
+ */
+cost?: Money;
+/**
+ * The price that `cost` was computed with: the resource name of a catalog
+ * price, or the version of a configured rate card.
+ *
+ * @generated from field: string price_version = 13;
+ */
+priceVersion?: string;
 }
 ;
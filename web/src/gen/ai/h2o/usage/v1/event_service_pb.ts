@@ -2,9 +2,45 @@
 // @generated from file ai/h2o/usage/v1/event_service.proto (package ai.h2o.usage.v1, syntax proto3)
 /* eslint-disable */
 
+import type { Status } from "../../../../google/rpc/status_pb";
+import type { Interval } from "../../../../google/type/interval_pb";
+import type { Money } from "../../../../google/type/money_pb";
 import type { Event } from "./event_pb";
+import type { BigIntString } from "../../../../runtime";
 import { RPC } from "../../../../runtime";
 
+/**
+ * The time buckets of AggregateUsage.
+ *
+ * @generated from enum ai.h2o.usage.v1.TimeBucket
+ */
+export enum TimeBucket {
+/**
+ * Events are not grouped by time.
+ *
+ * @generated from enum value: TIME_BUCKET_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "TIME_BUCKET_UNSPECIFIED",
+/**
+ * Hours.
+ *
+ * @generated from enum value: TIME_BUCKET_HOUR = 1;
+ */
+HOUR = "TIME_BUCKET_HOUR",
+/**
+ * Calendar days.
+ *
+ * @generated from enum value: TIME_BUCKET_DAY = 2;
+ */
+DAY = "TIME_BUCKET_DAY",
+/**
+ * Calendar months.
+ *
+ * @generated from enum value: TIME_BUCKET_MONTH = 3;
+ */
+MONTH = "TIME_BUCKET_MONTH",
+}
+
 /**
  * Request message for CreateEvent.
  *
@@ -17,6 +53,30 @@ export type CreateEventRequest = {
  * @generated from field: ai.h2o.usage.v1.Event event = 1;
  */
 event: Event;
+/**
+ * A unique identifier for this request, following AIP-155. If a request
+ * with the same `request_id` was already processed recently, the
+ * originally created event is returned instead of creating a new one, so
+ * that clients can safely retry. Reusing a `request_id` with a different
+ * payload fails with `ALREADY_EXISTS`. Request IDs are remembered by each
+ * server process, not persisted, so only retries reaching the same
+ * process are deduplicated; set `event_id` to deduplicate across servers.
+ *
+ * @generated from field: string request_id = 2;
+ */
+requestId?: string;
+/**
+ * The ID to use for the event, which will become the final component of
+ * the event's resource name. If not set, the server generates a UUID.
+ *
+ * Following AIP-122, the ID must be 1 to 63 characters long, consist of
+ * lowercase letters, digits and hyphens, start with a letter and not end
+ * with a hyphen. Creating an event with an ID that is already in use fails
+ * with `ALREADY_EXISTS`.
+ *
+ * @generated from field: string event_id = 3;
+ */
+eventId?: string;
 }
 ;
 /**
@@ -33,6 +93,315 @@ export type CreateEventResponse = {
 event?: Event;
 }
 ;
+/**
+ * Request message for BatchCreateEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.BatchCreateEventsRequest
+ */
+export type BatchCreateEventsRequest = {
+/**
+ * The requests specifying the events to create, validated with the same
+ * rules as `CreateEvent`. At most 1000 events can be created in a batch.
+ * The `request_id` of the individual requests must be empty; set it on
+ * the batch request instead.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.CreateEventRequest requests = 1;
+ */
+requests: CreateEventRequest[];
+/**
+ * If false (the default), the batch is atomic: if any request fails, no
+ * event is created and the error of the first failed request is returned.
+ * If true, the valid requests are applied and the failed ones are reported
+ * in `BatchCreateEventsResponse.failures`.
+ *
+ * @generated from field: bool allow_partial_success = 2;
+ */
+allowPartialSuccess?: boolean;
+/**
+ * A unique identifier for the whole batch, following AIP-155. A retried
+ * batch with the same `request_id` returns the original response.
+ *
+ * @generated from field: string request_id = 3;
+ */
+requestId?: string;
+}
+;
+/**
+ * Response message for BatchCreateEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.BatchCreateEventsResponse
+ */
+export type BatchCreateEventsResponse = {
+/**
+ * The created events, in the order of the requests they were created by.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.Event events = 1;
+ */
+events?: Event[];
+/**
+ * The requests that failed, in request order. Only set when
+ * `allow_partial_success` is true.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.BatchCreateEventsResponse.Failure failures = 2;
+ */
+failures?: BatchCreateEventsResponse_Failure[];
+}
+;
+/**
+ * A request of the batch that could not be applied.
+ *
+ * @generated from message ai.h2o.usage.v1.BatchCreateEventsResponse.Failure
+ */
+export type BatchCreateEventsResponse_Failure = {
+/**
+ * The index of the failed request in `BatchCreateEventsRequest.requests`.
+ *
+ * @generated from field: int32 index = 1;
+ */
+index?: number;
+/**
+ * The error of the request, with the same code and details that
+ * `CreateEvent` would have returned for it.
+ *
+ * @generated from field: google.rpc.Status status = 2;
+ */
+status?: Status;
+}
+;
+/**
+ * Request message for IngestEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.IngestEventsRequest
+ */
+export type IngestEventsRequest = {
+/**
+ * A client-chosen number identifying the request within the stream,
+ * echoed in its response.
+ *
+ * @generated from field: int64 sequence = 1;
+ */
+sequence?: BigIntString;
+/**
+ * The event to create, with the same semantics as a `CreateEvent` call.
+ * Setting `request_id` makes it safe to resend unacknowledged events on
+ * a new stream after a disconnect.
+ *
+ * @generated from field: ai.h2o.usage.v1.CreateEventRequest request = 2;
+ */
+request: CreateEventRequest;
+}
+;
+/**
+ * Response message for IngestEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.IngestEventsResponse
+ */
+export type IngestEventsResponse = {
+/**
+ * The `sequence` of the request this response belongs to.
+ *
+ * @generated from field: int64 sequence = 1;
+ */
+sequence?: BigIntString;
+/**
+ * The created event, set if the request succeeded.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 2;
+ */
+event?: Event;
+/**
+ * The error of the request, with the same code and details that
+ * `CreateEvent` would have returned for it. Unset if the request
+ * succeeded.
+ *
+ * @generated from field: google.rpc.Status status = 3;
+ */
+status?: Status;
+}
+;
+/**
+ * Request message for WatchEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.WatchEventsRequest
+ */
+export type WatchEventsRequest = {
+/**
+ * A filter expression following AIP-160, with the same syntax as
+ * `ListEventsRequest.filter`. Only matching events are streamed.
+ *
+ * @generated from field: string filter = 1;
+ */
+filter?: string;
+/**
+ * A `resume_token` received from a previous `WatchEvents` stream with the
+ * same filter. Events created after the event it was issued for are
+ * replayed, in `create_time` order, before new events are streamed. If
+ * empty, only events created after the call are streamed.
+ *
+ * @generated from field: string resume_token = 2;
+ */
+resumeToken?: string;
+}
+;
+/**
+ * Response message for WatchEvents.
+ *
+ * @generated from message ai.h2o.usage.v1.WatchEventsResponse
+ */
+export type WatchEventsResponse = {
+/**
+ * The created event.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event?: Event;
+/**
+ * A token to resume the stream after this event, e.g. when the stream is
+ * closed with `RESOURCE_EXHAUSTED` because the client did not keep up.
+ *
+ * @generated from field: string resume_token = 2;
+ */
+resumeToken?: string;
+}
+;
+/**
+ * Request message for GetEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.GetEventRequest
+ */
+export type GetEventRequest = {
+/**
+ * The name of the event to retrieve.
+ * Format: `events/{event}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+}
+;
+/**
+ * Response message for GetEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.GetEventResponse
+ */
+export type GetEventResponse = {
+/**
+ * The requested event.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event?: Event;
+}
+;
+/**
+ * Request message for UpdateEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdateEventRequest
+ */
+export type UpdateEventRequest = {
+/**
+ * The event to update. Its `name` identifies the event; if its `etag` is
+ * set, it must match the current etag of the event.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event: Event;
+/**
+ * The fields to update, following AIP-134. If omitted, all populated
+ * fields of `event` are updated; `*` replaces all mutable fields. Output
+ * only fields and `name` cannot be updated and are ignored.
+ *
+ * @generated from field: google.protobuf.FieldMask update_mask = 2;
+ */
+updateMask?: string;
+}
+;
+/**
+ * Response message for UpdateEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdateEventResponse
+ */
+export type UpdateEventResponse = {
+/**
+ * The updated event.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event?: Event;
+}
+;
+/**
+ * Request message for DeleteEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.DeleteEventRequest
+ */
+export type DeleteEventRequest = {
+/**
+ * The name of the event to delete.
+ * Format: `events/{event}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+/**
+ * The current etag of the event. If set and the event has been modified
+ * since, the request fails with `ABORTED`.
+ *
+ * @generated from field: string etag = 2;
+ */
+etag?: string;
+}
+;
+/**
+ * Response message for DeleteEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.DeleteEventResponse
+ */
+export type DeleteEventResponse = {
+/**
+ * The deleted event, with `delete_time` and `purge_time` set.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event?: Event;
+}
+;
+/**
+ * Request message for UndeleteEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.UndeleteEventRequest
+ */
+export type UndeleteEventRequest = {
+/**
+ * The name of the event to restore.
+ * Format: `events/{event}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+/**
+ * The current etag of the event. If set and the event has been modified
+ * since, the request fails with `ABORTED`.
+ *
+ * @generated from field: string etag = 2;
+ */
+etag?: string;
+}
+;
+/**
+ * Response message for UndeleteEvent.
+ *
+ * @generated from message ai.h2o.usage.v1.UndeleteEventResponse
+ */
+export type UndeleteEventResponse = {
+/**
+ * The restored event.
+ *
+ * @generated from field: ai.h2o.usage.v1.Event event = 1;
+ */
+event?: Event;
+}
+;
 /**
  * Request message for ListEvents.
  *
@@ -51,6 +420,40 @@ pageSize?: number;
  * @generated from field: string page_token = 2;
  */
 pageToken?: string;
+/**
+ * A filter expression following AIP-160, e.g.
+ * `action = "classify" AND subject = "users/alice"`.
+ *
+ * Supported fields are `name`, `subject`, `source`, `action` (compared as
+ * strings; `=` and `!=` accept `*` wildcards), `create_time` (a quoted
+ * RFC 3339 timestamp), `execution_duration` (e.g. `1.5s` or `200ms`) and
+ * labels: `labels.region = "us-east1"` compares the value of a label like
+ * a string field and only matches events that have the label, while
+ * `labels:region` or `labels.region:*` tests for its presence.
+ * Restrictions can be combined with `AND`, `OR`, `NOT` and parentheses.
+ * Note that, as in AIP-160, `OR` binds tighter than `AND`.
+ *
+ * @generated from field: string filter = 3;
+ */
+filter?: string;
+/**
+ * A comma-separated list of fields to order by, following AIP-132, e.g.
+ * `execution_duration desc, create_time`. Fields are sorted ascending
+ * unless suffixed with ` desc`.
+ *
+ * Supported fields are `create_time`, `execution_duration`, `subject`,
+ * `source`, `action` and `name`. Ties are broken by `name`. The default
+ * order is `create_time desc`.
+ *
+ * @generated from field: string order_by = 4;
+ */
+orderBy?: string;
+/**
+ * If true, soft-deleted events are included in the results.
+ *
+ * @generated from field: bool show_deleted = 5;
+ */
+showDeleted?: boolean;
 }
 ;
 /**
@@ -73,15 +476,313 @@ events?: Event[];
 nextPageToken?: string;
 }
 ;
+/**
+ * Request message for AggregateUsage.
+ *
+ * @generated from message ai.h2o.usage.v1.AggregateUsageRequest
+ */
+export type AggregateUsageRequest = {
+/**
+ * A filter expression selecting the events to aggregate, with the same
+ * syntax and fields as the `filter` of `ListEvents`, e.g.
+ * `create_time >= "2025-01-01T00:00:00Z" AND source = "animal-classifier"`.
+ *
+ * @generated from field: string filter = 1;
+ */
+filter?: string;
+/**
+ * The fields to group events by: `subject`, `source`, `action` or
+ * `labels.{key}` for the value of a label. Events without the label are
+ * grouped together. If empty, all events are aggregated into one group per
+ * time bucket.
+ *
+ * @generated from field: repeated string group_by = 2;
+ */
+groupBy?: string[];
+/**
+ * The time buckets to group events by their `create_time` into. If
+ * unspecified, events are not grouped by time.
+ *
+ * @generated from field: ai.h2o.usage.v1.TimeBucket time_bucket = 3;
+ */
+timeBucket?: TimeBucket;
+/**
+ * The IANA time zone in which buckets start, e.g. `Europe/Prague`. Defaults
+ * to `UTC`.
+ *
+ * @generated from field: string time_zone = 4;
+ */
+timeZone?: string;
+}
+;
+/**
+ * Response message for AggregateUsage.
+ *
+ * @generated from message ai.h2o.usage.v1.AggregateUsageResponse
+ */
+export type AggregateUsageResponse = {
+/**
+ * The totals of each group of events, ordered by the start of their time
+ * bucket, then by the values of the `group_by` fields in order.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.UsageAggregate aggregates = 1;
+ */
+aggregates?: UsageAggregate[];
+}
+;
+/**
+ * The totals of a group of events.
+ *
+ * @generated from message ai.h2o.usage.v1.UsageAggregate
+ */
+export type UsageAggregate = {
+/**
+ * The subject of the events, if grouped by `subject`.
+ *
+ * @generated from field: string subject = 1;
+ */
+subject?: string;
+/**
+ * The source of the events, if grouped by `source`.
+ *
+ * @generated from field: string source = 2;
+ */
+source?: string;
+/**
+ * The action of the events, if grouped by `action`.
+ *
+ * @generated from field: string action = 3;
+ */
+action?: string;
+/**
+ * The values of the labels grouped by, for the events that have them.
+ *
+ * @generated from field: map<string, string> labels = 4;
+ */
+labels?: { [key: string]: string };
+/**
+ * The time bucket of the events, if grouped by time.
+ *
+ * @generated from field: google.type.Interval interval = 5;
+ */
+interval?: Interval;
+/**
+ * The number of events.
+ *
+ * @generated from field: int64 event_count = 6;
+ */
+eventCount?: BigIntString;
+/**
+ * The sum of the `execution_duration` of the events.
+ *
+ * @generated from field: google.protobuf.Duration total_execution_duration = 7;
+ */
+totalExecutionDuration?: string;
+/**
+ * The mean `execution_duration` of the events.
+ *
+ * @generated from field: google.protobuf.Duration average_execution_duration = 8;
+ */
+averageExecutionDuration?: string;
+/**
+ * The median `execution_duration` of the events. Like the 95th percentile
+ * and the maximum, it is estimated when read from rollups, see
+ * AggregateUsage.
+ *
+ * @generated from field: google.protobuf.Duration p50_execution_duration = 9;
+ */
+p50ExecutionDuration?: string;
+/**
+ * The 95th percentile of the `execution_duration` of the events.
+ *
+ * @generated from field: google.protobuf.Duration p95_execution_duration = 10;
+ */
+p95ExecutionDuration?: string;
+/**
+ * The longest `execution_duration` of the events.
+ *
+ * @generated from field: google.protobuf.Duration max_execution_duration = 11;
+ */
+maxExecutionDuration?: string;
+/**
+ * The sum of the `cost` of the events, one amount per currency. Events
+ * without a cost are not included.
+ *
+ * @generated from field: repeated google.type.Money total_cost = 12;
+ */
+totalCost?: Money[];
+}
+;
+/**
+ * Request message for CheckQuota.
+ *
+ * @generated from message ai.h2o.usage.v1.CheckQuotaRequest
+ */
+export type CheckQuotaRequest = {
+/**
+ * The subject of the event, e.g. `users/alice`.
+ *
+ * @generated from field: string subject = 1;
+ */
+subject: string;
+/**
+ * The source of the event, e.g. `animal-classifier`.
+ *
+ * @generated from field: string source = 2;
+ */
+source: string;
+/**
+ * The action of the event, e.g. `classify`.
+ *
+ * @generated from field: string action = 3;
+ */
+action: string;
+}
+;
+/**
+ * Response message for CheckQuota.
+ *
+ * @generated from message ai.h2o.usage.v1.CheckQuotaResponse
+ */
+export type CheckQuotaResponse = {
+/**
+ * Whether an event of the subject, source and action would be accepted:
+ * none of the quotas is exhausted.
+ *
+ * @generated from field: bool allowed = 1;
+ */
+allowed?: boolean;
+/**
+ * The usage of the quotas that apply to the event, in their current
+ * windows.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.QuotaUsage quotas = 2;
+ */
+quotas?: QuotaUsage[];
+}
+;
+/**
+ * The usage of a quota by a subject in a window of time.
+ *
+ * @generated from message ai.h2o.usage.v1.QuotaUsage
+ */
+export type QuotaUsage = {
+/**
+ * The identifier of the quota in the server configuration, e.g.
+ * `free-daily-classifications`.
+ *
+ * @generated from field: string quota_id = 1;
+ */
+quotaId?: string;
+/**
+ * The window of time in which usage counts against the quota. Windows are
+ * hours, days or months of UTC.
+ *
+ * @generated from field: google.type.Interval window = 2;
+ */
+window?: Interval;
+/**
+ * The number of events allowed in the window, or zero if not limited.
+ *
+ * @generated from field: int64 event_limit = 3;
+ */
+eventLimit?: BigIntString;
+/**
+ * The number of events in the window.
+ *
+ * @generated from field: int64 event_count = 4;
+ */
+eventCount?: BigIntString;
+/**
+ * The total `execution_duration` allowed in the window, or unset if not
+ * limited.
+ *
+ * @generated from field: google.protobuf.Duration execution_duration_limit = 5;
+ */
+executionDurationLimit?: string;
+/**
+ * The total `execution_duration` of the events in the window.
+ *
+ * @generated from field: google.protobuf.Duration total_execution_duration = 6;
+ */
+totalExecutionDuration?: string;
+/**
+ * Whether the usage has reached a limit, so that further events are
+ * rejected until the window ends. The event reaching a limit on the
+ * execution duration is accepted, even if it exceeds it.
+ *
+ * @generated from field: bool exhausted = 7;
+ */
+exhausted?: boolean;
+}
+;
 /**
  * Creates a new usage event.
  *
  * @generated from rpc ai.h2o.usage.v1.EventService.CreateEvent
  */
 export const EventService_CreateEvent = new RPC<CreateEventRequest,CreateEventResponse>("POST", "/v1/events");
+/**
+ * Creates a batch of usage events.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.BatchCreateEvents
+ */
+export const EventService_BatchCreateEvents = new RPC<BatchCreateEventsRequest,BatchCreateEventsResponse>("POST", "/v1/events:batchCreate");
+/**
+ * Gets a usage event.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.GetEvent
+ */
+export const EventService_GetEvent = new RPC<GetEventRequest,GetEventResponse>("GET", "/v1/{name=events/*}");
+/**
+ * Updates a usage event, e.g. to correct its `execution_duration` or
+ * `action`.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.UpdateEvent
+ */
+export const EventService_UpdateEvent = new RPC<UpdateEventRequest,UpdateEventResponse>("PATCH", "/v1/{event.name=events/*}", "event");
+/**
+ * Deletes a usage event. The event is soft-deleted: it is hidden from
+ * `ListEvents` and can be restored with `UndeleteEvent` until its
+ * `purge_time`, after which it is permanently removed.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.DeleteEvent
+ */
+export const EventService_DeleteEvent = new RPC<DeleteEventRequest,DeleteEventResponse>("DELETE", "/v1/{name=events/*}");
+/**
+ * Restores a soft-deleted usage event.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.UndeleteEvent
+ */
+export const EventService_UndeleteEvent = new RPC<UndeleteEventRequest,UndeleteEventResponse>("POST", "/v1/{name=events/*}:undelete");
 /**
  * Lists usage events.
  *
  * @generated from rpc ai.h2o.usage.v1.EventService.ListEvents
  */
 export const EventService_ListEvents = new RPC<ListEventsRequest,ListEventsResponse>("GET", "/v1/events");
+/**
+ * Aggregates the usage recorded by events into totals, optionally grouped
+ * by event fields and time buckets. Deleted events are not counted.
+ *
+ * When grouping by `subject`, `source` and `action` only, with a filter on
+ * those fields and on `create_time` by `>=` and `<` comparisons with whole
+ * hours, the totals are read from hourly and daily rollups maintained as
+ * events are written, rather than from the events themselves. The
+ * percentiles and maximum of `execution_duration` are then estimates within
+ * 1% of the exact values.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.AggregateUsage
+ */
+export const EventService_AggregateUsage = new RPC<AggregateUsageRequest,AggregateUsageResponse>("GET", "/v1/events:aggregate");
+/**
+ * Reports the usage of a subject against the quotas that apply to its
+ * events of a source and action, so that clients can check whether such an
+ * event would be accepted before doing the work it records. When the server
+ * enforces quotas, CreateEvent and BatchCreateEvents reject events of
+ * exhausted quotas with `RESOURCE_EXHAUSTED` and a `google.rpc.QuotaFailure`
+ * detail.
+ *
+ * @generated from rpc ai.h2o.usage.v1.EventService.CheckQuota
+ */
+export const EventService_CheckQuota = new RPC<CheckQuotaRequest,CheckQuotaResponse>("GET", "/v1/events:checkQuota");
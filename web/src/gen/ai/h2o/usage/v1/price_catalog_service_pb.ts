@@ -0,0 +1,227 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file ai/h2o/usage/v1/price_catalog_service.proto (package ai.h2o.usage.v1, syntax proto3)
+/* eslint-disable */
+
+import type { Price } from "./price_pb";
+import { RPC } from "../../../../runtime";
+
+/**
+ * Request message for CreatePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.CreatePriceRequest
+ */
+export type CreatePriceRequest = {
+/**
+ * The action to create the price for.
+ * Format: `sources/{source}/actions/{action}`
+ *
+ * @generated from field: string parent = 1;
+ */
+parent: string;
+/**
+ * The price to create.
+ *
+ * @generated from field: ai.h2o.usage.v1.Price price = 2;
+ */
+price: Price;
+/**
+ * The ID to use for the price, which will become the final component of
+ * the price's resource name, e.g. `v2025-01`.
+ *
+ * Following AIP-122, the ID must be 1 to 63 characters long, consist of
+ * lowercase letters, digits and hyphens, start with a letter and not end
+ * with a hyphen.
+ *
+ * @generated from field: string price_id = 3;
+ */
+priceId: string;
+}
+;
+/**
+ * Response message for CreatePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.CreatePriceResponse
+ */
+export type CreatePriceResponse = {
+/**
+ * The created price.
+ *
+ * @generated from field: ai.h2o.usage.v1.Price price = 1;
+ */
+price?: Price;
+}
+;
+/**
+ * Request message for GetPrice.
+ *
+ * @generated from message ai.h2o.usage.v1.GetPriceRequest
+ */
+export type GetPriceRequest = {
+/**
+ * The name of the price to retrieve.
+ * Format: `sources/{source}/actions/{action}/prices/{price}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+}
+;
+/**
+ * Response message for GetPrice.
+ *
+ * @generated from message ai.h2o.usage.v1.GetPriceResponse
+ */
+export type GetPriceResponse = {
+/**
+ * The requested price.
+ *
+ * @generated from field: ai.h2o.usage.v1.Price price = 1;
+ */
+price?: Price;
+}
+;
+/**
+ * Request message for ListPrices.
+ *
+ * @generated from message ai.h2o.usage.v1.ListPricesRequest
+ */
+export type ListPricesRequest = {
+/**
+ * The action whose prices to list.
+ * Format: `sources/{source}/actions/{action}`
+ *
+ * @generated from field: string parent = 1;
+ */
+parent: string;
+/**
+ * The maximum number of prices to return.
+ *
+ * @generated from field: int32 page_size = 2;
+ */
+pageSize?: number;
+/**
+ * A page token, received from a previous `ListPrices` call.
+ *
+ * @generated from field: string page_token = 3;
+ */
+pageToken?: string;
+}
+;
+/**
+ * Response message for ListPrices.
+ *
+ * @generated from message ai.h2o.usage.v1.ListPricesResponse
+ */
+export type ListPricesResponse = {
+/**
+ * The list of prices.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.Price prices = 1;
+ */
+prices?: Price[];
+/**
+ * A token to retrieve the next page of results.
+ *
+ * @generated from field: string next_page_token = 2;
+ */
+nextPageToken?: string;
+}
+;
+/**
+ * Request message for UpdatePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdatePriceRequest
+ */
+export type UpdatePriceRequest = {
+/**
+ * The price to update. Its `name` identifies the price; if its `etag` is
+ * set, it must match the current etag of the price.
+ *
+ * @generated from field: ai.h2o.usage.v1.Price price = 1;
+ */
+price: Price;
+/**
+ * The fields to update, following AIP-134. If omitted, all populated
+ * mutable fields of `price` are updated; `*` replaces all mutable fields.
+ *
+ * @generated from field: google.protobuf.FieldMask update_mask = 2;
+ */
+updateMask?: string;
+}
+;
+/**
+ * Response message for UpdatePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdatePriceResponse
+ */
+export type UpdatePriceResponse = {
+/**
+ * The updated price.
+ *
+ * @generated from field: ai.h2o.usage.v1.Price price = 1;
+ */
+price?: Price;
+}
+;
+/**
+ * Request message for DeletePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.DeletePriceRequest
+ */
+export type DeletePriceRequest = {
+/**
+ * The name of the price to delete.
+ * Format: `sources/{source}/actions/{action}/prices/{price}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+/**
+ * The current etag of the price. If set and the price has been modified
+ * since, the request fails with `ABORTED`.
+ *
+ * @generated from field: string etag = 2;
+ */
+etag?: string;
+}
+;
+/**
+ * Response message for DeletePrice.
+ *
+ * @generated from message ai.h2o.usage.v1.DeletePriceResponse
+ */
+export type DeletePriceResponse = {
+}
+;
+/**
+ * Creates a price. Its `effective_time` must not overlap with the other
+ * prices of the action, and the action must be registered.
+ *
+ * @generated from rpc ai.h2o.usage.v1.PriceCatalogService.CreatePrice
+ */
+export const PriceCatalogService_CreatePrice = new RPC<CreatePriceRequest,CreatePriceResponse>("POST", "/v1/{parent=sources/*/actions/*}/prices", "price");
+/**
+ * Gets a price.
+ *
+ * @generated from rpc ai.h2o.usage.v1.PriceCatalogService.GetPrice
+ */
+export const PriceCatalogService_GetPrice = new RPC<GetPriceRequest,GetPriceResponse>("GET", "/v1/{name=sources/*/actions/*/prices/*}");
+/**
+ * Lists the prices of an action, ordered by name.
+ *
+ * @generated from rpc ai.h2o.usage.v1.PriceCatalogService.ListPrices
+ */
+export const PriceCatalogService_ListPrices = new RPC<ListPricesRequest,ListPricesResponse>("GET", "/v1/{parent=sources/*/actions/*}/prices");
+/**
+ * Updates a price. Only its `effective_time` can be changed, e.g. to end
+ * it before a new price takes effect.
+ *
+ * @generated from rpc ai.h2o.usage.v1.PriceCatalogService.UpdatePrice
+ */
+export const PriceCatalogService_UpdatePrice = new RPC<UpdatePriceRequest,UpdatePriceResponse>("PATCH", "/v1/{price.name=sources/*/actions/*/prices/*}", "price");
+/**
+ * Deletes a price. Events already priced with it keep their cost.
+ *
+ * @generated from rpc ai.h2o.usage.v1.PriceCatalogService.DeletePrice
+ */
+export const PriceCatalogService_DeletePrice = new RPC<DeletePriceRequest,DeletePriceResponse>("DELETE", "/v1/{name=sources/*/actions/*/prices/*}");
@@ -0,0 +1,125 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file ai/h2o/usage/v1/price.proto (package ai.h2o.usage.v1, syntax proto3)
+/* eslint-disable */
+
+import type { Interval } from "../../../../google/type/interval_pb";
+import type { Money } from "../../../../google/type/money_pb";
+
+/**
+ * How charges are rounded.
+ *
+ * @generated from enum ai.h2o.usage.v1.RoundingMode
+ */
+export enum RoundingMode {
+/**
+ * Same as `ROUNDING_MODE_HALF_UP`.
+ *
+ * @generated from enum value: ROUNDING_MODE_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "ROUNDING_MODE_UNSPECIFIED",
+/**
+ * Round to the nearest increment; halves are rounded up.
+ *
+ * @generated from enum value: ROUNDING_MODE_HALF_UP = 1;
+ */
+HALF_UP = "ROUNDING_MODE_HALF_UP",
+/**
+ * Round up to the next increment.
+ *
+ * @generated from enum value: ROUNDING_MODE_UP = 2;
+ */
+UP = "ROUNDING_MODE_UP",
+/**
+ * Round down to the previous increment.
+ *
+ * @generated from enum value: ROUNDING_MODE_DOWN = 3;
+ */
+DOWN = "ROUNDING_MODE_DOWN",
+}
+
+/**
+ * The price of an action of a source during a period of time. An event is
+ * priced with the price of its source and action in effect at its
+ * `create_time`.
+ *
+ * Amounts are immutable: to change a price, end its `effective_time` and
+ * create a new price effective from then on.
+ *
+ * @generated from message ai.h2o.usage.v1.Price
+ */
+export type Price = {
+/**
+ * The resource name of the price.
+ * Format: `sources/{source}/actions/{action}/prices/{price}`
+ *
+ * @generated from field: string name = 1;
+ */
+name?: string;
+/**
+ * The period in which the price is in effect. The start time is inclusive
+ * and the end time exclusive; if either is unset, the period is open on
+ * that side. The periods of the prices of an action must not overlap.
+ *
+ * @generated from field: google.type.Interval effective_time = 2;
+ */
+effectiveTime: Interval;
+/**
+ * The amount charged for every event. At least one of `per_call` and
+ * `per_second` must be set, and all amounts of a price must be in the same
+ * currency.
+ *
+ * @generated from field: google.type.Money per_call = 3;
+ */
+perCall?: Money;
+/**
+ * The amount charged per second of `execution_duration`.
+ *
+ * @generated from field: google.type.Money per_second = 4;
+ */
+perSecond?: Money;
+/**
+ * The least amount charged for an event.
+ *
+ * @generated from field: google.type.Money minimum = 5;
+ */
+minimum?: Money;
+/**
+ * The granularity at which execution durations are billed: they are
+ * rounded up to a multiple of it. If unset, exact durations are billed.
+ *
+ * @generated from field: google.protobuf.Duration billing_increment = 6;
+ */
+billingIncrement?: string;
+/**
+ * The precision of charges, e.g. 0.01 for cents. If unset, charges are
+ * rounded to nanos.
+ *
+ * @generated from field: google.type.Money rounding_increment = 7;
+ */
+roundingIncrement?: Money;
+/**
+ * How charges are rounded to `rounding_increment`.
+ *
+ * @generated from field: ai.h2o.usage.v1.RoundingMode rounding_mode = 8;
+ */
+roundingMode?: RoundingMode;
+/**
+ * The time when the price was created.
+ *
+ * @generated from field: google.protobuf.Timestamp create_time = 9;
+ */
+createTime?: string;
+/**
+ * The time when the price was last updated.
+ *
+ * @generated from field: google.protobuf.Timestamp update_time = 10;
+ */
+updateTime?: string;
+/**
+ * A checksum of the price's current state, following AIP-154.
+ *
+ * @generated from field: string etag = 11;
+ */
+etag?: string;
+}
+;
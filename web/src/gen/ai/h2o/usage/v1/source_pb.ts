@@ -0,0 +1,54 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file ai/h2o/usage/v1/source.proto (package ai.h2o.usage.v1, syntax proto3)
+/* eslint-disable */
+
+/**
+ * A product or tool that records usage events, with the actions it may
+ * record. Events are only accepted for registered sources and actions.
+ *
+ * @generated from message ai.h2o.usage.v1.Source
+ */
+export type Source = {
+/**
+ * The resource name of the source. Its ID is the `source` of the events
+ * recorded by it.
+ * Format: `sources/{source}`
+ *
+ * @generated from field: string name = 1;
+ */
+name?: string;
+/**
+ * A human-readable name of the source.
+ *
+ * @generated from field: string display_name = 2;
+ */
+displayName?: string;
+/**
+ * The actions that events of this source may record (e.g., "classify").
+ * Each action follows the same rules as resource IDs: 1 to 63 characters
+ * of lowercase letters, digits and hyphens, starting with a letter and not
+ * ending with a hyphen.
+ *
+ * @generated from field: repeated string actions = 3;
+ */
+actions: string[];
+/**
+ * The time when the source was registered.
+ *
+ * @generated from field: google.protobuf.Timestamp create_time = 4;
+ */
+createTime?: string;
+/**
+ * The time when the source was last updated.
+ *
+ * @generated from field: google.protobuf.Timestamp update_time = 5;
+ */
+updateTime?: string;
+/**
+ * A checksum of the source's current state, following AIP-154.
+ *
+ * @generated from field: string etag = 6;
+ */
+etag?: string;
+}
+;
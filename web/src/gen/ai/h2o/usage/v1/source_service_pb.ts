@@ -0,0 +1,212 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file ai/h2o/usage/v1/source_service.proto (package ai.h2o.usage.v1, syntax proto3)
+/* eslint-disable */
+
+import type { Source } from "./source_pb";
+import { RPC } from "../../../../runtime";
+
+/**
+ * Request message for CreateSource.
+ *
+ * @generated from message ai.h2o.usage.v1.CreateSourceRequest
+ */
+export type CreateSourceRequest = {
+/**
+ * The source to register.
+ *
+ * @generated from field: ai.h2o.usage.v1.Source source = 1;
+ */
+source: Source;
+/**
+ * The ID to use for the source, which will become the final component of
+ * the source's resource name and the `source` of its events.
+ *
+ * Following AIP-122, the ID must be 1 to 63 characters long, consist of
+ * lowercase letters, digits and hyphens, start with a letter and not end
+ * with a hyphen.
+ *
+ * @generated from field: string source_id = 2;
+ */
+sourceId: string;
+}
+;
+/**
+ * Response message for CreateSource.
+ *
+ * @generated from message ai.h2o.usage.v1.CreateSourceResponse
+ */
+export type CreateSourceResponse = {
+/**
+ * The registered source.
+ *
+ * @generated from field: ai.h2o.usage.v1.Source source = 1;
+ */
+source?: Source;
+}
+;
+/**
+ * Request message for GetSource.
+ *
+ * @generated from message ai.h2o.usage.v1.GetSourceRequest
+ */
+export type GetSourceRequest = {
+/**
+ * The name of the source to retrieve.
+ * Format: `sources/{source}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+}
+;
+/**
+ * Response message for GetSource.
+ *
+ * @generated from message ai.h2o.usage.v1.GetSourceResponse
+ */
+export type GetSourceResponse = {
+/**
+ * The requested source.
+ *
+ * @generated from field: ai.h2o.usage.v1.Source source = 1;
+ */
+source?: Source;
+}
+;
+/**
+ * Request message for ListSources.
+ *
+ * @generated from message ai.h2o.usage.v1.ListSourcesRequest
+ */
+export type ListSourcesRequest = {
+/**
+ * The maximum number of sources to return.
+ *
+ * @generated from field: int32 page_size = 1;
+ */
+pageSize?: number;
+/**
+ * A page token, received from a previous `ListSources` call.
+ *
+ * @generated from field: string page_token = 2;
+ */
+pageToken?: string;
+}
+;
+/**
+ * Response message for ListSources.
+ *
+ * @generated from message ai.h2o.usage.v1.ListSourcesResponse
+ */
+export type ListSourcesResponse = {
+/**
+ * The list of sources.
+ *
+ * @generated from field: repeated ai.h2o.usage.v1.Source sources = 1;
+ */
+sources?: Source[];
+/**
+ * A token to retrieve the next page of results.
+ *
+ * @generated from field: string next_page_token = 2;
+ */
+nextPageToken?: string;
+}
+;
+/**
+ * Request message for UpdateSource.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdateSourceRequest
+ */
+export type UpdateSourceRequest = {
+/**
+ * The source to update. Its `name` identifies the source; if its `etag`
+ * is set, it must match the current etag of the source.
+ *
+ * @generated from field: ai.h2o.usage.v1.Source source = 1;
+ */
+source: Source;
+/**
+ * The fields to update, following AIP-134. If omitted, all populated
+ * fields of `source` are updated; `*` replaces all mutable fields.
+ *
+ * @generated from field: google.protobuf.FieldMask update_mask = 2;
+ */
+updateMask?: string;
+}
+;
+/**
+ * Response message for UpdateSource.
+ *
+ * @generated from message ai.h2o.usage.v1.UpdateSourceResponse
+ */
+export type UpdateSourceResponse = {
+/**
+ * The updated source.
+ *
+ * @generated from field: ai.h2o.usage.v1.Source source = 1;
+ */
+source?: Source;
+}
+;
+/**
+ * Request message for DeleteSource.
+ *
+ * @generated from message ai.h2o.usage.v1.DeleteSourceRequest
+ */
+export type DeleteSourceRequest = {
+/**
+ * The name of the source to delete.
+ * Format: `sources/{source}`
+ *
+ * @generated from field: string name = 1;
+ */
+name: string;
+/**
+ * The current etag of the source. If set and the source has been
+ * modified since, the request fails with `ABORTED`.
+ *
+ * @generated from field: string etag = 2;
+ */
+etag?: string;
+}
+;
+/**
+ * Response message for DeleteSource.
+ *
+ * @generated from message ai.h2o.usage.v1.DeleteSourceResponse
+ */
+export type DeleteSourceResponse = {
+}
+;
+/**
+ * Registers a new source.
+ *
+ * @generated from rpc ai.h2o.usage.v1.SourceService.CreateSource
+ */
+export const SourceService_CreateSource = new RPC<CreateSourceRequest,CreateSourceResponse>("POST", "/v1/sources", "source");
+/**
+ * Gets a source.
+ *
+ * @generated from rpc ai.h2o.usage.v1.SourceService.GetSource
+ */
+export const SourceService_GetSource = new RPC<GetSourceRequest,GetSourceResponse>("GET", "/v1/{name=sources/*}");
+/**
+ * Lists sources, ordered by name.
+ *
+ * @generated from rpc ai.h2o.usage.v1.SourceService.ListSources
+ */
+export const SourceService_ListSources = new RPC<ListSourcesRequest,ListSourcesResponse>("GET", "/v1/sources");
+/**
+ * Updates a source, e.g. to allow a new action.
+ *
+ * @generated from rpc ai.h2o.usage.v1.SourceService.UpdateSource
+ */
+export const SourceService_UpdateSource = new RPC<UpdateSourceRequest,UpdateSourceResponse>("PATCH", "/v1/{source.name=sources/*}", "source");
+/**
+ * Deletes a source. Events already recorded for it are kept, but no new
+ * events are accepted.
+ *
+ * @generated from rpc ai.h2o.usage.v1.SourceService.DeleteSource
+ */
+export const SourceService_DeleteSource = new RPC<DeleteSourceRequest,DeleteSourceResponse>("DELETE", "/v1/{name=sources/*}");
@@ -0,0 +1,4 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/api/annotations.proto (package google.api, syntax proto3)
+/* eslint-disable */
+
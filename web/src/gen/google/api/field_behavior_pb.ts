@@ -0,0 +1,46 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/api/field_behavior.proto (package google.api, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from enum google.api.FieldBehavior
+ */
+export enum FieldBehavior {
+/**
+ * @generated from enum value: FIELD_BEHAVIOR_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "FIELD_BEHAVIOR_UNSPECIFIED",
+/**
+ * @generated from enum value: OPTIONAL = 1;
+ */
+OPTIONAL = "OPTIONAL",
+/**
+ * @generated from enum value: REQUIRED = 2;
+ */
+REQUIRED = "REQUIRED",
+/**
+ * @generated from enum value: OUTPUT_ONLY = 3;
+ */
+OUTPUT_ONLY = "OUTPUT_ONLY",
+/**
+ * @generated from enum value: INPUT_ONLY = 4;
+ */
+INPUT_ONLY = "INPUT_ONLY",
+/**
+ * @generated from enum value: IMMUTABLE = 5;
+ */
+IMMUTABLE = "IMMUTABLE",
+/**
+ * @generated from enum value: UNORDERED_LIST = 6;
+ */
+UNORDERED_LIST = "UNORDERED_LIST",
+/**
+ * @generated from enum value: NON_EMPTY_DEFAULT = 7;
+ */
+NON_EMPTY_DEFAULT = "NON_EMPTY_DEFAULT",
+/**
+ * @generated from enum value: IDENTIFIER = 8;
+ */
+IDENTIFIER = "IDENTIFIER",
+}
+
@@ -0,0 +1,54 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/api/field_info.proto (package google.api, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from message google.api.FieldInfo
+ */
+export type FieldInfo = {
+/**
+ * @generated from field: google.api.FieldInfo.Format format = 1;
+ */
+format?: FieldInfo_Format;
+/**
+ * @generated from field: repeated google.api.TypeReference referenced_types = 2;
+ */
+referencedTypes?: TypeReference[];
+}
+;
+/**
+ * @generated from enum google.api.FieldInfo.Format
+ */
+export enum FieldInfo_Format {
+/**
+ * @generated from enum value: FORMAT_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "FORMAT_UNSPECIFIED",
+/**
+ * @generated from enum value: UUID4 = 1;
+ */
+UUID4 = "UUID4",
+/**
+ * @generated from enum value: IPV4 = 2;
+ */
+IPV4 = "IPV4",
+/**
+ * @generated from enum value: IPV6 = 3;
+ */
+IPV6 = "IPV6",
+/**
+ * @generated from enum value: IPV4_OR_IPV6 = 4;
+ */
+IPV4_OR_IPV6 = "IPV4_OR_IPV6",
+}
+
+/**
+ * @generated from message google.api.TypeReference
+ */
+export type TypeReference = {
+/**
+ * @generated from field: string type_name = 1;
+ */
+typeName?: string;
+}
+;
@@ -0,0 +1,78 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/api/http.proto (package google.api, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from message google.api.Http
+ */
+export type Http = {
+/**
+ * @generated from field: repeated google.api.HttpRule rules = 1;
+ */
+rules?: HttpRule[];
+/**
+ * @generated from field: bool fully_decode_reserved_expansion = 2;
+ */
+fullyDecodeReservedExpansion?: boolean;
+}
+;
+/**
+ * @generated from message google.api.HttpRule
+ */
+export type HttpRule = {
+/**
+ * @generated from field: string selector = 1;
+ */
+selector?: string;
+/**
+ * @generated from field: string get = 2;
+ */
+get?: string;
+/**
+ * @generated from field: string put = 3;
+ */
+put?: string;
+/**
+ * @generated from field: string post = 4;
+ */
+post?: string;
+/**
+ * @generated from field: string delete = 5;
+ */
+delete?: string;
+/**
+ * @generated from field: string patch = 6;
+ */
+patch?: string;
+/**
+ * @generated from field: google.api.CustomHttpPattern custom = 8;
+ */
+custom?: CustomHttpPattern;
+/**
+ * @generated from field: string body = 7;
+ */
+body?: string;
+/**
+ * @generated from field: string response_body = 12;
+ */
+responseBody?: string;
+/**
+ * @generated from field: repeated google.api.HttpRule additional_bindings = 11;
+ */
+additionalBindings?: HttpRule[];
+}
+;
+/**
+ * @generated from message google.api.CustomHttpPattern
+ */
+export type CustomHttpPattern = {
+/**
+ * @generated from field: string kind = 1;
+ */
+kind?: string;
+/**
+ * @generated from field: string path = 2;
+ */
+path?: string;
+}
+;
@@ -0,0 +1,84 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/api/resource.proto (package google.api, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from message google.api.ResourceDescriptor
+ */
+export type ResourceDescriptor = {
+/**
+ * @generated from field: string type = 1;
+ */
+type?: string;
+/**
+ * @generated from field: repeated string pattern = 2;
+ */
+pattern?: string[];
+/**
+ * @generated from field: string name_field = 3;
+ */
+nameField?: string;
+/**
+ * @generated from field: google.api.ResourceDescriptor.History history = 4;
+ */
+history?: ResourceDescriptor_History;
+/**
+ * @generated from field: string plural = 5;
+ */
+plural?: string;
+/**
+ * @generated from field: string singular = 6;
+ */
+singular?: string;
+/**
+ * @generated from field: repeated google.api.ResourceDescriptor.Style style = 10;
+ */
+style?: ResourceDescriptor_Style[];
+}
+;
+/**
+ * @generated from enum google.api.ResourceDescriptor.History
+ */
+export enum ResourceDescriptor_History {
+/**
+ * @generated from enum value: HISTORY_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "HISTORY_UNSPECIFIED",
+/**
+ * @generated from enum value: ORIGINALLY_SINGLE_PATTERN = 1;
+ */
+ORIGINALLY_SINGLE_PATTERN = "ORIGINALLY_SINGLE_PATTERN",
+/**
+ * @generated from enum value: FUTURE_MULTI_PATTERN = 2;
+ */
+FUTURE_MULTI_PATTERN = "FUTURE_MULTI_PATTERN",
+}
+
+/**
+ * @generated from enum google.api.ResourceDescriptor.Style
+ */
+export enum ResourceDescriptor_Style {
+/**
+ * @generated from enum value: STYLE_UNSPECIFIED = 0;
+ */
+UNSPECIFIED = "STYLE_UNSPECIFIED",
+/**
+ * @generated from enum value: DECLARATIVE_FRIENDLY = 1;
+ */
+DECLARATIVE_FRIENDLY = "DECLARATIVE_FRIENDLY",
+}
+
+/**
+ * @generated from message google.api.ResourceReference
+ */
+export type ResourceReference = {
+/**
+ * @generated from field: string type = 1;
+ */
+type?: string;
+/**
+ * @generated from field: string child_type = 2;
+ */
+childType?: string;
+}
+;
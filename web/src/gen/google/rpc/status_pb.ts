@@ -0,0 +1,22 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/rpc/status.proto (package google.rpc, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from message google.rpc.Status
+ */
+export type Status = {
+/**
+ * @generated from field: int32 code = 1;
+ */
+code?: number;
+/**
+ * @generated from field: string message = 2;
+ */
+message?: string;
+/**
+ * @generated from field: repeated google.protobuf.Any details = 3;
+ */
+details?: any[];
+}
+;
@@ -0,0 +1,18 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/type/interval.proto (package google.type, syntax proto3)
+/* eslint-disable */
+
+/**
+ * @generated from message google.type.Interval
+ */
+export type Interval = {
+/**
+ * @generated from field: google.protobuf.Timestamp start_time = 1;
+ */
+startTime?: string;
+/**
+ * @generated from field: google.protobuf.Timestamp end_time = 2;
+ */
+endTime?: string;
+}
+;
@@ -0,0 +1,24 @@
+// @generated by protoc-gen-grpc-gateway-es v0.3.1 with parameter "target=ts"
+// @generated from file google/type/money.proto (package google.type, syntax proto3)
+/* eslint-disable */
+
+import type { BigIntString } from "../../runtime";
+
+/**
+ * @generated from message google.type.Money
+ */
+export type Money = {
+/**
+ * @generated from field: string currency_code = 1;
+ */
+currencyCode?: string;
+/**
+ * @generated from field: int64 units = 2;
+ */
+units?: BigIntString;
+/**
+ * @generated from field: int32 nanos = 3;
+ */
+nanos?: number;
+}
+;
@@ -1,6 +1,8 @@
 import './style.css'
-import { EventService_CreateEvent, EventService_ListEvents } from './gen/ai/h2o/usage/v1/event_service_pb'
+import { EventService_AggregateUsage, EventService_CheckQuota, EventService_CreateEvent, EventService_ListEvents, TimeBucket } from './gen/ai/h2o/usage/v1/event_service_pb'
+import type { CheckQuotaResponse, QuotaUsage, UsageAggregate } from './gen/ai/h2o/usage/v1/event_service_pb'
 import type { Event } from './gen/ai/h2o/usage/v1/event_pb'
+import type { Money } from './gen/google/type/money_pb'
 import type { RequestConfig } from './gen/runtime'
 
 interface ImageItem {
@@ -13,6 +15,9 @@ interface ImageItem {
 const ANIMALS = ['Dog', 'Cat', 'Bird', 'Horse', 'Elephant', 'Lion', 'Tiger', 'Bear', 'Rabbit', 'Fox']
 const STORAGE_KEY = 'animal-classifier-images'
 const USER_ID = 'users/anonymous'
+const SOURCE = 'animal-classifier'
+const ACTION = 'classify'
+const MODEL_VERSION = 'mock-1'
 
 const apiConfig: RequestConfig = {
   basePath: 'http://localhost:8080',
@@ -22,22 +27,78 @@ let images: ImageItem[] = loadImages()
 
 // --- API Functions ---
 
-async function sendUsageEvent(durationMs: number): Promise<void> {
-  const request = EventService_CreateEvent.createRequest(apiConfig, {
-    event: {
-      subject: USER_ID,
-      source: 'animal-classifier',
-      action: 'classify',
-      executionDuration: `${(durationMs / 1000).toFixed(3)}s`,
-    },
+const SEND_ATTEMPTS = 4
+const RETRY_BASE_DELAY_MS = 500
+
+function sleep(ms: number): Promise<void> {
+  return new Promise(resolve => setTimeout(resolve, ms))
+}
+
+// sendUsageEvent records one classification. All attempts share a request_id,
+// so the server records the event only once even if a retried request had
+// already succeeded.
+async function sendUsageEvent(durationMs: number, imageSizeBytes: number): Promise<void> {
+  const requestId = crypto.randomUUID()
+
+  for (let attempt = 1; attempt <= SEND_ATTEMPTS; attempt++) {
+    const request = EventService_CreateEvent.createRequest(apiConfig, {
+      event: {
+        subject: USER_ID,
+        source: SOURCE,
+        action: ACTION,
+        executionDuration: `${(durationMs / 1000).toFixed(3)}s`,
+        labels: {
+          model_version: MODEL_VERSION,
+          image_size_bytes: String(imageSizeBytes),
+        },
+      },
+      requestId,
+    })
+
+    try {
+      const response = await fetch(request)
+      if (response.ok) {
+        const data = EventService_CreateEvent.responseTypeId(await response.json())
+        console.log('Usage event recorded:', data.event?.name)
+        return
+      }
+      // Client errors will not succeed on retry
+      if (response.status < 500) {
+        console.error('Failed to send usage event:', response.status, await response.text())
+        return
+      }
+      console.warn(`Sending usage event failed with status ${response.status} (attempt ${attempt})`)
+    } catch (error) {
+      console.warn(`Sending usage event failed (attempt ${attempt}):`, error)
+    }
+
+    if (attempt < SEND_ATTEMPTS) {
+      await sleep(RETRY_BASE_DELAY_MS * 2 ** (attempt - 1))
+    }
+  }
+  console.error(`Failed to send usage event after ${SEND_ATTEMPTS} attempts`)
+}
+
+// checkQuota reports whether the user may classify another image. If the
+// check fails, classification is allowed: the server still rejects events of
+// exhausted quotas if it enforces them.
+async function checkQuota(): Promise<CheckQuotaResponse | null> {
+  const request = EventService_CheckQuota.createRequest(apiConfig, {
+    subject: USER_ID,
+    source: SOURCE,
+    action: ACTION,
   })
 
   try {
     const response = await fetch(request)
-    const data = EventService_CreateEvent.responseTypeId(await response.json())
-    console.log('Usage event recorded:', data.event?.name)
+    if (!response.ok) {
+      console.warn('Failed to check quota:', response.status, await response.text())
+      return null
+    }
+    return EventService_CheckQuota.responseTypeId(await response.json())
   } catch (error) {
-    console.error('Failed to send usage event:', error)
+    console.warn('Failed to check quota:', error)
+    return null
   }
 }
 
@@ -56,6 +117,44 @@ async function fetchEvents(): Promise<Event[]> {
   }
 }
 
+// fetchDailyUsage returns the totals of the events of each day in the
+// browser's time zone, oldest first.
+async function fetchDailyUsage(): Promise<UsageAggregate[]> {
+  const request = EventService_AggregateUsage.createRequest(apiConfig, {
+    timeBucket: TimeBucket.DAY,
+    timeZone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+  })
+
+  try {
+    const response = await fetch(request)
+    const data = EventService_AggregateUsage.responseTypeId(await response.json())
+    return data.aggregates || []
+  } catch (error) {
+    console.error('Failed to fetch daily usage:', error)
+    return []
+  }
+}
+
+let eventSource: EventSource | null = null
+
+// watchEvents subscribes to newly created events. The browser reconnects
+// automatically and resumes from the last received event.
+function watchEvents(onEvent: (event: Event) => void): void {
+  stopWatchingEvents()
+  eventSource = new EventSource(`${apiConfig.basePath}/v1/events:watch`)
+  eventSource.onmessage = (message) => {
+    onEvent(JSON.parse(message.data) as Event)
+  }
+  eventSource.onerror = () => {
+    console.warn('Event stream interrupted, reconnecting')
+  }
+}
+
+function stopWatchingEvents(): void {
+  eventSource?.close()
+  eventSource = null
+}
+
 // --- Storage Functions ---
 
 function saveImages(): void {
@@ -84,6 +183,11 @@ function mockClassify(): string {
   return ANIMALS[Math.floor(Math.random() * ANIMALS.length)]
 }
 
+// escapeHtml escapes a value for use in HTML text or a quoted attribute.
+function escapeHtml(value: unknown): string {
+  return String(value ?? '').replace(/[&<>"']/g, c => `&#${c.charCodeAt(0)};`)
+}
+
 function renderGallery(): void {
   const gallery = document.getElementById('gallery')
   if (!gallery) return
@@ -95,22 +199,46 @@ function renderGallery(): void {
 
   gallery.innerHTML = images.map(img => `
     <div class="gallery-item">
-      <img src="${img.dataUrl}" alt="${img.name}" />
+      <img src="${escapeHtml(img.dataUrl)}" alt="${escapeHtml(img.name)}" />
       <div class="gallery-item-info">
-        <p class="gallery-item-name">${img.name}</p>
+        <p class="gallery-item-name">${escapeHtml(img.name)}</p>
         <span class="gallery-item-classification ${img.classification ? '' : 'pending'}">
-          ${img.classification || 'Classifying...'}
+          ${escapeHtml(img.classification || 'Classifying...')}
         </span>
       </div>
     </div>
   `).join('')
 }
 
-function handleFile(file: File): void {
+function formatQuota(usage: QuotaUsage): string {
+  const eventLimit = Number(usage.eventLimit ?? 0)
+  const limit = eventLimit > 0 && Number(usage.eventCount ?? 0) >= eventLimit
+    ? `${usage.eventCount} of ${usage.eventLimit} classifications`
+    : `${usage.totalExecutionDuration} of ${usage.executionDurationLimit} of compute`
+  const end = usage.window?.endTime
+  return `${limit} used${end ? `, resets ${new Date(end).toLocaleString()}` : ''}`
+}
+
+function showQuotaMessage(quota: CheckQuotaResponse | null): void {
+  const message = document.getElementById('quota-message')
+  if (!message) return
+
+  const exhausted = quota?.quotas?.filter(usage => usage.exhausted) ?? []
+  message.hidden = exhausted.length === 0
+  message.textContent = `Quota reached: ${exhausted.map(formatQuota).join('; ')}`
+}
+
+async function handleFile(file: File): Promise<void> {
   if (!file.type.startsWith('image/')) {
     return
   }
 
+  const quota = await checkQuota()
+  showQuotaMessage(quota)
+  if (quota && !quota.allowed) {
+    return
+  }
+
   const reader = new FileReader()
   reader.onload = (e) => {
     const id = generateId()
@@ -137,7 +265,7 @@ function handleFile(file: File): void {
         renderGallery()
 
         const durationMs = performance.now() - startTime
-        sendUsageEvent(durationMs)
+        sendUsageEvent(durationMs, file.size)
       }
     }, classificationDelay)
   }
@@ -187,6 +315,7 @@ function renderClassifierPage(): void {
         <input type="file" id="file-input" accept="image/*" hidden />
         <p>Drop an image here or <button id="browse-btn">browse</button></p>
       </div>
+      <p class="quota-message" id="quota-message" hidden></p>
     </section>
 
     <section class="gallery-section">
@@ -200,6 +329,43 @@ function renderClassifierPage(): void {
 
 // --- Events Page ---
 
+function formatMoney(money: Money | undefined): string {
+  if (!money) {
+    return '-'
+  }
+  const amount = Number(money.units ?? 0) + (money.nanos ?? 0) / 1e9
+  return `${amount.toFixed(4)} ${money.currencyCode}`
+}
+
+function renderUsageRow(aggregate: UsageAggregate): string {
+  const day = aggregate.interval?.startTime
+  const costs = aggregate.totalCost ?? []
+  return `
+    <tr>
+      <td>${escapeHtml(day ? new Date(day).toLocaleDateString() : '-')}</td>
+      <td>${escapeHtml(aggregate.eventCount)}</td>
+      <td>${escapeHtml(aggregate.totalExecutionDuration)}</td>
+      <td>${escapeHtml(aggregate.averageExecutionDuration)}</td>
+      <td>${escapeHtml(aggregate.p95ExecutionDuration)}</td>
+      <td>${escapeHtml(costs.length > 0 ? costs.map(formatMoney).join(', ') : '-')}</td>
+    </tr>
+  `
+}
+
+function renderEventRow(event: Event): string {
+  return `
+    <tr>
+      <td>${escapeHtml(event.name || '-')}</td>
+      <td>${escapeHtml(event.subject)}</td>
+      <td>${escapeHtml(event.source)}</td>
+      <td>${escapeHtml(event.action)}</td>
+      <td>${escapeHtml(event.executionDuration)}</td>
+      <td>${escapeHtml(formatMoney(event.cost))}</td>
+      <td>${escapeHtml(event.createTime ? new Date(event.createTime).toLocaleString() : '-')}</td>
+    </tr>
+  `
+}
+
 async function renderEventsPage(): Promise<void> {
   const main = document.getElementById('main')!
   main.innerHTML = `
@@ -209,22 +375,46 @@ async function renderEventsPage(): Promise<void> {
     </section>
   `
 
-  const events = await fetchEvents()
+  // Start watching before listing so that no event falls in between. Events
+  // streamed before the table is rendered are kept until then.
+  const names = new Set<string>()
+  const streamed: Event[] = []
+  let addEvent = (event: Event) => { streamed.unshift(event) }
+  watchEvents(event => {
+    if (!event.name || names.has(event.name)) return
+    names.add(event.name)
+    addEvent(event)
+  })
 
-  if (events.length === 0) {
-    main.innerHTML = `
-      <section class="events-section">
-        <h2>Usage Events</h2>
-        <p class="empty-events">No events recorded yet</p>
-      </section>
-    `
-    return
-  }
+  const [dailyUsage, fetched] = await Promise.all([fetchDailyUsage(), fetchEvents()])
+  const listed = fetched.filter(event => !event.name || !names.has(event.name))
+  listed.forEach(event => event.name && names.add(event.name))
+  const events = [...streamed, ...listed]
 
   main.innerHTML = `
+    <section class="events-section" ${dailyUsage.length === 0 ? 'hidden' : ''}>
+      <h2>Daily Usage</h2>
+      <table class="events-table">
+        <thead>
+          <tr>
+            <th>Day</th>
+            <th>Events</th>
+            <th>Total duration</th>
+            <th>Average</th>
+            <th>p95</th>
+            <th>Cost</th>
+          </tr>
+        </thead>
+        <tbody>
+          ${dailyUsage.slice().reverse().map(renderUsageRow).join('')}
+        </tbody>
+      </table>
+    </section>
+
     <section class="events-section">
       <h2>Usage Events</h2>
-      <table class="events-table">
+      <p class="empty-events" ${events.length > 0 ? 'hidden' : ''}>No events recorded yet</p>
+      <table class="events-table" id="events-table" ${events.length === 0 ? 'hidden' : ''}>
         <thead>
           <tr>
             <th>Name</th>
@@ -232,24 +422,24 @@ async function renderEventsPage(): Promise<void> {
             <th>Source</th>
             <th>Action</th>
             <th>Duration</th>
+            <th>Cost</th>
             <th>Created</th>
           </tr>
         </thead>
         <tbody>
-          ${events.map(event => `
-            <tr>
-              <td>${event.name || '-'}</td>
-              <td>${event.subject}</td>
-              <td>${event.source}</td>
-              <td>${event.action}</td>
-              <td>${event.executionDuration}</td>
-              <td>${event.createTime ? new Date(event.createTime).toLocaleString() : '-'}</td>
-            </tr>
-          `).join('')}
+          ${events.map(renderEventRow).join('')}
         </tbody>
       </table>
     </section>
   `
+
+  const empty = main.querySelector<HTMLElement>('.empty-events')!
+  const table = main.querySelector<HTMLElement>('#events-table')!
+  addEvent = (event: Event) => {
+    table.querySelector('tbody')!.insertAdjacentHTML('afterbegin', renderEventRow(event))
+    table.hidden = false
+    empty.hidden = true
+  }
 }
 
 // --- Router ---
@@ -264,6 +454,7 @@ function updateActiveLink(): void {
 function router(): void {
   const hash = window.location.hash || '#/'
   updateActiveLink()
+  stopWatchingEvents()
 
   switch (hash) {
     case '#/events':